@@ -33,13 +33,14 @@ import (
 	"github.com/knative/pkg/signals"
 	"github.com/knative/pkg/system"
 	"github.com/knative/pkg/version"
-	"github.com/knative/pkg/websocket"
 	"github.com/knative/serving/cmd/util"
 	"github.com/knative/serving/pkg/activator"
 	activatorconfig "github.com/knative/serving/pkg/activator/config"
 	activatorhandler "github.com/knative/serving/pkg/activator/handler"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/apis/networking"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/statproto"
 	clientset "github.com/knative/serving/pkg/client/clientset/versioned"
 	servinginformers "github.com/knative/serving/pkg/client/informers/externalversions"
 	"github.com/knative/serving/pkg/goversion"
@@ -73,8 +74,9 @@ const (
 	requestCountingQueueLength = 100
 
 	// The number of requests that are queued on the breaker before the 503s are sent.
-	// The value must be adjusted depending on the actual production requirements.
-	breakerQueueDepth = 10000
+	// The requests actually queued are further limited by config-activator and the
+	// annotations of the revisions.
+	breakerQueueDepth = autoscaling.ActivatorMaxQueuedRequestsMax
 
 	// The upper bound for concurrent requests sent to the revision.
 	// As new endpoints show up, the Breakers concurrency increases up to this value.
@@ -92,7 +94,7 @@ var (
 	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 )
 
-func statReporter(statSink *websocket.ManagedConnection, stopCh <-chan struct{},
+func statReporter(statSink *statproto.Sender, stopCh <-chan struct{},
 	statChan <-chan *autoscaler.StatMessage, logger *zap.SugaredLogger) {
 	for {
 		select {
@@ -101,7 +103,18 @@ func statReporter(statSink *websocket.ManagedConnection, stopCh <-chan struct{},
 				logger.Error("Stat sink is not connected")
 				continue
 			}
-			if err := statSink.Send(sm); err != nil {
+			// Send everything that queued up in a single frame.
+			sms := []*autoscaler.StatMessage{sm}
+		drain:
+			for len(sms) < statReportingQueueLength {
+				select {
+				case sm := <-statChan:
+					sms = append(sms, sm)
+				default:
+					break drain
+				}
+			}
+			if err := statSink.Send(sms...); err != nil {
 				logger.Errorw("Error while sending stat", zap.Error(err))
 			}
 		case <-stopCh:
@@ -182,7 +195,8 @@ func main() {
 	}
 
 	params := queue.BreakerParams{QueueDepth: breakerQueueDepth, MaxConcurrency: breakerMaxConcurrency, InitialCapacity: 0}
-	throttler := activator.NewThrottler(params, endpointInformer, sksInformer.Lister(), revisionInformer.Lister(), logger)
+	// POD_IP tells which revisions this activator is assigned to.
+	throttler := activator.NewThrottler(params, endpointInformer, sksInformer, revisionInformer.Lister(), os.Getenv("POD_IP"), logger)
 
 	activatorL3 := fmt.Sprintf("%s:%d", activator.K8sServiceName, networking.ServiceHTTPPort)
 	zipkinEndpoint, err := zipkin.NewEndpoint("activator", activatorL3)
@@ -210,7 +224,7 @@ func main() {
 	// Open a websocket connection to the autoscaler
 	autoscalerEndpoint := fmt.Sprintf("ws://%s.%s.svc.%s:%d", "autoscaler", system.Namespace(), network.GetClusterDomainName(), autoscalerPort)
 	logger.Info("Connecting to autoscaler at", autoscalerEndpoint)
-	statSink := websocket.NewDurableSendingConnection(autoscalerEndpoint, logger)
+	statSink := statproto.NewSender(autoscalerEndpoint, logger)
 	go statReporter(statSink, stopCh, statChan, logger)
 
 	podName := util.GetRequiredEnvOrFatal("POD_NAME", logger)
@@ -221,6 +235,20 @@ func main() {
 	cr := activatorhandler.NewConcurrencyReporter(podName, reqChan, reportTicker.C, statChan)
 	go cr.Run(stopCh)
 
+	// Report the length of the queues of the revisions.
+	go func() {
+		queueTicker := time.NewTicker(time.Second)
+		defer queueTicker.Stop()
+		for {
+			select {
+			case <-queueTicker.C:
+				throttler.ReportQueueLengths(reporter)
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
 	// Create activation handler chain
 	// Note: innermost handlers are specified first, ie. the last handler in the chain will be executed first
 	var ah http.Handler = activatorhandler.New(
@@ -20,6 +20,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 	"time"
 
 	"github.com/knative/pkg/configmap"
@@ -34,7 +36,9 @@ import (
 	"github.com/knative/pkg/system"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
 	"github.com/knative/serving/pkg/autoscaler/statserver"
+	"github.com/knative/serving/pkg/autoscaler/trace"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/hpa"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/kpa"
 	"github.com/knative/serving/pkg/resources"
@@ -43,21 +47,44 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 const (
-	statsServerAddr = ":8080"
+	statsServerPort = 8080
 	statsBufferLen  = 1000
 	component       = "autoscaler"
+
+	// serviceName is the name of the Service in front of all autoscaler
+	// replicas. Its Endpoints list the replicas sharing the revisions.
+	serviceName = "autoscaler"
 )
 
 var (
-	masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
-	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	masterURL   = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	kubeconfig  = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	recordStats = flag.String("record-stats", "", "Path of a file to append all scraped and received stats to as JSON lines, for replay with the simulator.")
+
+	podScraping = flag.Bool("pod-scraping", false, "Scrape a sample of the ready pods of a revision directly rather than through its private Service.")
+
+	defaultScrapeOptions = autoscaler.DefaultScrapeSchedulerOptions()
+	scrapeWorkers        = flag.Int("scrape-workers", defaultScrapeOptions.Workers, "The maximum number of revisions scraped in parallel.")
+	scrapeJitter         = flag.Float64("scrape-jitter", defaultScrapeOptions.Jitter, "The fraction of the scrape interval each scrape is randomly moved by, in [0, 1).")
+	scrapeMaxBackoff     = flag.Duration("scrape-max-backoff", defaultScrapeOptions.MaxBackoff, "The longest interval between two scrapes of a revision without pods.")
+
+	debugPort = flag.Int("debug-port", 8008, "The port to serve the latest decisions of all deciders on under "+autoscaler.DebugPath+". Zero disables it.")
+
+	snapshotFile       = flag.String("metric-snapshot-file", "", "Path of a file to persist the collected metrics in, so they survive restarts.")
+	snapshotConfigMap  = flag.String("metric-snapshot-configmap", "", "Name prefix of the ConfigMaps in the system namespace to persist the collected metrics in, so they survive restarts.")
+	snapshotConfigMaps = flag.Int("metric-snapshot-configmaps", 16, "The number of ConfigMaps the collected metrics are spread across.")
+	snapshotInterval   = flag.Duration("metric-snapshot-interval", 10*time.Second, "The interval between two snapshots of the collected metrics.")
 )
 
 func main() {
@@ -110,12 +137,66 @@ func main() {
 
 	endpointsInformer := endpointsinformer.Get(ctx)
 
-	collector := autoscaler.NewMetricCollector(statsScraperFactoryFunc(endpointsInformer.Lister()), logger)
-	customMetricsAdapter.WithCustomMetrics(autoscaler.NewMetricProvider(collector))
+	// Split the revisions across all autoscaler replicas if we know who we are.
+	var shards *sharding.Shards
+	if podIP := os.Getenv("POD_IP"); podIP != "" {
+		shards = sharding.New(podIP)
+		endpointsInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: controller.FilterWithNameAndNamespace(system.Namespace(), serviceName),
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { shards.Update(sharding.MembersFromEndpoints(obj.(*corev1.Endpoints))) },
+				UpdateFunc: func(_, obj interface{}) { shards.Update(sharding.MembersFromEndpoints(obj.(*corev1.Endpoints))) },
+			},
+		})
+		ctx = sharding.WithShards(ctx, shards)
+	}
+	forwarder := sharding.NewForwarder(statsServerPort, logger)
+	defer forwarder.Shutdown()
+	shards.Watch(func() {
+		logger.Infof("Autoscaler replicas changed to %v", shards.Members())
+		forwarder.Retain(shards.Members())
+	})
+
+	if *scrapeJitter < 0 || *scrapeJitter >= 1 {
+		logger.Fatalw("Invalid -scrape-jitter, must be in [0, 1)", zap.Float64("jitter", *scrapeJitter))
+	}
+	scheduler := autoscaler.NewScrapeScheduler(autoscaler.ScrapeSchedulerOptions{
+		Workers:    *scrapeWorkers,
+		Interval:   defaultScrapeOptions.Interval,
+		Jitter:     *scrapeJitter,
+		MaxBackoff: *scrapeMaxBackoff,
+	}, clock.RealClock{}, logger)
+	collector := autoscaler.NewMetricCollectorWithScheduler(statsScraperFactoryFunc(endpointsInformer.Lister()),
+		scheduler, clock.RealClock{}, logger)
+	if *recordStats != "" {
+		f, err := os.OpenFile(*recordStats, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			logger.Fatalw("Error opening the stats recording", zap.Error(err))
+		}
+		defer f.Close()
+		collector.RecordTo(trace.NewWriter(f), ctx.Done())
+	}
+
+	// Restore the metrics collected before a restart, so the revisions don't
+	// start out with empty windows.
+	snapshotStore := newSnapshotStore(kubeclient.Get(ctx), shards)
+	if snapshotStore != nil {
+		snapshot, err := snapshotStore.Load()
+		if err != nil {
+			logger.Errorw("Failed to load metric snapshot", zap.Error(err))
+		}
+		collector.Restore(snapshot)
+	}
 
 	// Set up scalers.
 	// uniScalerFactory depends endpointsInformer to be set.
 	multiScaler := autoscaler.NewMultiScaler(ctx.Done(), uniScalerFactoryFunc(endpointsInformer, collector), logger)
+	metricProvider := autoscaler.NewMetricProvider(collector, collector, multiScaler)
+	if shards != nil {
+		// Only the owner of a revision has its data, the others ask it.
+		metricProvider.ForwardTo(shards, statsServerPort)
+	}
+	customMetricsAdapter.WithCustomMetrics(metricProvider)
 
 	psInformerFactory := resources.NewPodScalableInformerFactory(ctx)
 	controllers := []*controller.Impl{
@@ -124,7 +205,12 @@ func main() {
 	}
 
 	// Set up a statserver.
-	statsServer := statserver.New(statsServerAddr, statsCh, logger)
+	// forwardedCh receives the stats other replicas forward to us as the owner.
+	forwardedCh := make(chan *autoscaler.StatMessage, statsBufferLen)
+	defer close(forwardedCh)
+	statsServer := statserver.New(fmt.Sprintf(":%d", statsServerPort), statsCh, logger)
+	statsServer.Forwarded(forwardedCh)
+	statsServer.Handle(autoscaler.MetricValuePath, metricProvider.MetricValueHandler())
 
 	// Start watching the configs.
 	if err := cmw.Start(ctx.Done()); err != nil {
@@ -138,8 +224,33 @@ func main() {
 
 	go controller.StartAll(ctx.Done(), controllers...)
 
+	snapshotterDone := make(chan struct{})
+	if snapshotStore != nil {
+		go func() {
+			defer close(snapshotterDone)
+			autoscaler.RunSnapshotter(ctx.Done(), collector, snapshotStore, *snapshotInterval, logger)
+		}()
+	} else {
+		close(snapshotterDone)
+	}
+
 	go func() {
 		for sm := range statsCh {
+			if owner := shards.Owner(sm.Key); owner != shards.Self() {
+				if err := forwarder.Forward(owner, sm); err != nil {
+					logger.Debugw("Failed to forward stat to "+owner, zap.Error(err))
+				}
+				continue
+			}
+			collector.Record(sm.Key, sm.Stat)
+			multiScaler.Poke(sm.Key, sm.Stat)
+		}
+	}()
+
+	// Forwarded stats are never forwarded again, even if the replicas
+	// disagree on the owner for a moment.
+	go func() {
+		for sm := range forwardedCh {
 			collector.Record(sm.Key, sm.Stat)
 			multiScaler.Poke(sm.Key, sm.Stat)
 		}
@@ -150,15 +261,49 @@ func main() {
 		return customMetricsAdapter.Run(ctx.Done())
 	})
 	eg.Go(statsServer.ListenAndServe)
+	var debugServer *http.Server
+	if *debugPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle(autoscaler.DebugPath, autoscaler.NewDebugHandler(multiScaler, logger))
+		debugServer = &http.Server{Addr: fmt.Sprintf(":%d", *debugPort), Handler: mux}
+		eg.Go(func() error {
+			if err := debugServer.ListenAndServe(); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		})
+	}
 
 	// This will block until either a signal arrives or one of the grouped functions
 	// returns an error.
 	<-egCtx.Done()
 
 	statsServer.Shutdown(5 * time.Second)
+	if debugServer != nil {
+		debugServer.Close()
+	}
 	if err := eg.Wait(); err != nil {
 		logger.Errorw("Error while shutting down", zap.Error(err))
 	}
+	<-snapshotterDone
+}
+
+// newSnapshotStore creates the store configured by the flags or nil if
+// the collected metrics should not be persisted.
+func newSnapshotStore(kubeClient kubernetes.Interface, shards *sharding.Shards) autoscaler.SnapshotStore {
+	switch {
+	case *snapshotFile != "":
+		return autoscaler.NewFileSnapshotStore(*snapshotFile)
+	case *snapshotConfigMap != "":
+		var owns func(string) bool
+		if shards != nil {
+			// All replicas share the ConfigMaps.
+			owns = shards.IsOwner
+		}
+		return autoscaler.NewConfigMapSnapshotStore(kubeClient, system.Namespace(), *snapshotConfigMap, *snapshotConfigMaps, owns)
+	default:
+		return nil
+	}
 }
 
 func uniScalerFactoryFunc(endpointsInformer corev1informers.EndpointsInformer, metricClient autoscaler.MetricClient) func(decider *autoscaler.Decider) (autoscaler.UniScaler, error) {
@@ -180,12 +325,15 @@ func uniScalerFactoryFunc(endpointsInformer corev1informers.EndpointsInformer, m
 		}
 
 		podCounter := resources.NewScopedEndpointsCounter(endpointsInformer.Lister(), decider.Namespace, decider.Name)
-		return autoscaler.New(decider.Namespace, decider.Name, metricClient, podCounter, decider.Spec, reporter)
+		return autoscaler.NewUniScaler(decider.Namespace, decider.Name, metricClient, podCounter, decider.Spec, reporter)
 	}
 }
 
 func statsScraperFactoryFunc(endpointsLister corev1listers.EndpointsLister) func(metric *autoscaler.Metric) (autoscaler.StatsScraper, error) {
 	return func(metric *autoscaler.Metric) (autoscaler.StatsScraper, error) {
+		if *podScraping {
+			return autoscaler.NewPodScraper(metric, endpointsLister)
+		}
 		podCounter := resources.NewScopedEndpointsCounter(endpointsLister, metric.Namespace, metric.Spec.ScrapeTarget)
 		return autoscaler.NewServiceScraper(metric, podCounter)
 	}
@@ -124,6 +124,32 @@ func TestUniScalerFactoryFunc(t *testing.T) {
 	}
 }
 
+func TestUniScalerFactoryUnknownAlgorithm(t *testing.T) {
+	uniScalerFactory := getTestUniScalerFactory()
+	decider := &autoscaler.Decider{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testRevision,
+			Labels: map[string]string{
+				serving.RevisionLabelKey:      testRevision,
+				serving.ConfigurationLabelKey: "test-config",
+			},
+		},
+		Spec: autoscaler.DeciderSpec{
+			Algorithm:   "magic",
+			ServiceName: "magic-services-offered",
+		},
+	}
+
+	_, err := uniScalerFactory(decider)
+	if err == nil {
+		t.Fatal("No error was returned")
+	}
+	if got, want := err.Error(), `unknown decider algorithm "magic"`; !strings.Contains(got, want) {
+		t.Errorf("Error = %q, want to contain = %q", got, want)
+	}
+}
+
 func getTestUniScalerFactory() func(decider *autoscaler.Decider) (autoscaler.UniScaler, error) {
 	kubeClient := fakeK8s.NewSimpleClientset()
 	kubeInformer := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
@@ -135,3 +161,19 @@ type testMetricClient struct{}
 func (t *testMetricClient) StableAndPanicConcurrency(key string) (float64, float64, error) {
 	return 1.0, 1.0, nil
 }
+
+func (t *testMetricClient) StableAndPanicRPS(key string) (float64, float64, error) {
+	return 1.0, 1.0, nil
+}
+
+func (t *testMetricClient) StableLatency(key string, percentile int) (float64, error) {
+	return 0.1, nil
+}
+
+func (t *testMetricClient) StableAndPanicQueueLength(key string) (float64, float64, error) {
+	return 0.0, 0.0, nil
+}
+
+func (t *testMetricClient) StableAndPanicProxiedRPS(key string) (float64, float64, error) {
+	return 1.0, 1.0, nil
+}
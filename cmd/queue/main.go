@@ -189,18 +189,25 @@ func handler(reqChan chan queue.ReqEvent, breaker *queue.Breaker, proxy *httputi
 		if activator.Name == h {
 			in, out = queue.ProxiedIn, queue.ProxiedOut
 		}
-		reqChan <- queue.ReqEvent{Time: time.Now(), EventType: in}
+		start := time.Now()
+		reqChan <- queue.ReqEvent{Time: start, EventType: in}
 		defer func() {
-			reqChan <- queue.ReqEvent{Time: time.Now(), EventType: out}
+			now := time.Now()
+			reqChan <- queue.ReqEvent{Time: now, EventType: out, Latency: now.Sub(start)}
 		}()
 		network.RewriteHostOut(r)
 
 		// Enforce queuing and concurrency limits.
 		if breaker != nil {
+			queuedAt := time.Now()
+			reqChan <- queue.ReqEvent{Time: queuedAt, EventType: queue.ReqQueued}
 			ok := breaker.Maybe(func() {
+				dequeuedAt := time.Now()
+				reqChan <- queue.ReqEvent{Time: dequeuedAt, EventType: queue.ReqDequeued, Latency: dequeuedAt.Sub(queuedAt)}
 				proxy.ServeHTTP(w, r)
 			})
 			if !ok {
+				reqChan <- queue.ReqEvent{Time: time.Now(), EventType: queue.ReqRejected}
 				http.Error(w, "overload", http.StatusServiceUnavailable)
 			}
 		} else {
@@ -0,0 +1,188 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The simulator replays a recording of autoscaler stats, as written by the
+// autoscaler's -record-stats flag, against the KPA decider with a virtual
+// clock and reports the resulting desired scale timeline, panic windows and
+// pod-seconds. This allows tuning config-autoscaler and the autoscaling
+// annotations offline. For example:
+//
+//	go run ./cmd/simulator -trace stats.jsonl -config config/config-autoscaler.yaml \
+//	  -annotation autoscaling.knative.dev/panicThresholdPercentage=150
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/knative/pkg/logging"
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+	apiconfig "github.com/knative/serving/pkg/apis/config"
+	"github.com/knative/serving/pkg/apis/serving/v1beta1"
+	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/simulator"
+	"github.com/knative/serving/pkg/autoscaler/trace"
+	kparesources "github.com/knative/serving/pkg/reconciler/autoscaling/kpa/resources"
+	"github.com/knative/serving/pkg/reconciler/autoscaling/resources"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	tracePath            = flag.String("trace", "", "Path of the recorded stats, one JSON encoded StatMessage per line.")
+	configPath           = flag.String("config", "", "Path of a config-autoscaler ConfigMap. Defaults are used if empty.")
+	key                  = flag.String("key", "", "The namespace/name of the revision to replay. Defaults to the revision of the first recorded stat.")
+	containerConcurrency = flag.Int64("container-concurrency", 0, "The containerConcurrency of the revision.")
+	startupDelay         = flag.Duration("startup-delay", 0, "The time a new pod takes to become ready.")
+	verbose              = flag.Bool("verbose", false, "Log the decisions of the autoscaler.")
+	annotations          = annotationsFlag{}
+)
+
+func main() {
+	flag.Var(annotations, "annotation", "An autoscaling annotation of the revision as key=value. May be repeated.")
+	flag.Parse()
+
+	if *tracePath == "" {
+		log.Fatal("-trace is required")
+	}
+	messages, err := readTrace(*tracePath)
+	if err != nil {
+		log.Fatalf("Error reading trace: %v", err)
+	}
+	config, validationConfig, err := readConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Error reading config: %v", err)
+	}
+	if *key == "" && len(messages) > 0 {
+		*key = messages[0].Key
+	}
+	namespace, name, err := splitKey(*key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := apiconfig.FromContextOrDefaults(context.Background())
+	cfg.Autoscaler = validationConfig
+	if err := autoscaling.ValidateAnnotations(apiconfig.ToContext(context.Background(), cfg), annotations); err != nil {
+		log.Fatalf("Invalid annotations: %v", err)
+	}
+
+	logger := zap.NewNop().Sugar()
+	if *verbose {
+		l, err := zap.NewDevelopment()
+		if err != nil {
+			log.Fatalf("Error creating logger: %v", err)
+		}
+		logger = l.Sugar()
+	}
+	ctx := logging.WithLogger(context.Background(), logger)
+
+	pa := &v1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: annotations,
+		},
+		Spec: v1alpha1.PodAutoscalerSpec{
+			ContainerConcurrency: v1beta1.RevisionContainerConcurrencyType(*containerConcurrency),
+		},
+	}
+	min, max := pa.ScaleBounds()
+	result, err := simulator.Run(ctx, messages,
+		kparesources.MakeDecider(ctx, pa, config, ""),
+		resources.MakeMetric(ctx, pa, "", config),
+		simulator.Options{
+			StartupDelay: *startupDelay,
+			MinScale:     min,
+			MaxScale:     max,
+		})
+	if err != nil {
+		log.Fatalf("Error simulating: %v", err)
+	}
+	if err := simulator.Print(os.Stdout, result); err != nil {
+		log.Fatalf("Error printing the result: %v", err)
+	}
+}
+
+func readTrace(path string) ([]autoscaler.StatMessage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return trace.Read(f)
+}
+
+// readConfig reads the autoscaler configuration, and the part of it the
+// annotations are validated against.
+func readConfig(path string) (*autoscaler.Config, *apiconfig.Autoscaler, error) {
+	var cm corev1.ConfigMap
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := yaml.Unmarshal(b, &cm); err != nil {
+			return nil, nil, err
+		}
+		// The example only documents the defaults.
+		delete(cm.Data, "_example")
+	}
+	config, err := autoscaler.NewConfigFromMap(cm.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	validationConfig, err := apiconfig.NewAutoscalerConfigFromMap(cm.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, validationConfig, nil
+}
+
+func splitKey(key string) (string, string, error) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid revision key %q, want namespace/name", key)
+	}
+	return parts[0], parts[1], nil
+}
+
+// annotationsFlag collects repeated key=value flags.
+type annotationsFlag map[string]string
+
+func (a annotationsFlag) String() string {
+	var kvs []string
+	for k, v := range a {
+		kvs = append(kvs, k+"="+v)
+	}
+	return strings.Join(kvs, ",")
+}
+
+func (a annotationsFlag) Set(kv string) error {
+	parts := strings.SplitN(kv, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid annotation %q, want key=value", kv)
+	}
+	a[parts[0]] = parts[1]
+	return nil
+}
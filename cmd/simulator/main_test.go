@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiconfig "github.com/knative/serving/pkg/apis/config"
+	"github.com/knative/serving/pkg/autoscaler"
+)
+
+func TestReadConfig(t *testing.T) {
+	want, err := autoscaler.NewConfigFromMap(nil)
+	if err != nil {
+		t.Fatalf("NewConfigFromMap() = %v", err)
+	}
+	wantValidation, err := apiconfig.NewAutoscalerConfigFromMap(nil)
+	if err != nil {
+		t.Fatalf("NewAutoscalerConfigFromMap() = %v", err)
+	}
+
+	for _, path := range []string{"", "../../config/config-autoscaler.yaml"} {
+		got, gotValidation, err := readConfig(path)
+		if err != nil {
+			t.Fatalf("readConfig(%q) = %v", path, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("readConfig(%q) (-want, +got) = %v", path, diff)
+		}
+		if diff := cmp.Diff(wantValidation, gotValidation); diff != "" {
+			t.Errorf("readConfig(%q) validation config (-want, +got) = %v", path, diff)
+		}
+	}
+
+	if _, _, err := readConfig("does-not-exist.yaml"); err == nil {
+		t.Error("readConfig() = nil, wanted an error")
+	}
+}
+
+func TestSplitKey(t *testing.T) {
+	tests := []struct {
+		key                 string
+		wantNamespace, want string
+		wantErr             bool
+	}{{
+		key:           "ns/rev",
+		wantNamespace: "ns",
+		want:          "rev",
+	}, {
+		key:     "rev",
+		wantErr: true,
+	}, {
+		key:     "ns/",
+		wantErr: true,
+	}, {
+		key:     "a/b/c",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			namespace, name, err := splitKey(test.key)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("splitKey() = %v, wantErr %v", err, test.wantErr)
+			}
+			if namespace != test.wantNamespace || name != test.want {
+				t.Errorf("splitKey() = %q, %q, want %q, %q", namespace, name, test.wantNamespace, test.want)
+			}
+		})
+	}
+}
+
+func TestAnnotationsFlag(t *testing.T) {
+	a := annotationsFlag{}
+	if err := a.Set("autoscaling.knative.dev/target=10"); err != nil {
+		t.Fatalf("Set() = %v", err)
+	}
+	if err := a.Set("autoscaling.knative.dev/window=2m"); err != nil {
+		t.Fatalf("Set() = %v", err)
+	}
+	want := annotationsFlag{
+		"autoscaling.knative.dev/target": "10",
+		"autoscaling.knative.dev/window": "2m",
+	}
+	if diff := cmp.Diff(want, a); diff != "" {
+		t.Errorf("annotations (-want, +got) = %v", diff)
+	}
+	if err := a.Set("no-value"); err == nil {
+		t.Error("Set() = nil, wanted an error")
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/autoscaling"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ActivatorConfigName is the name of the config map of the activator.
+	ActivatorConfigName = "config-activator"
+
+	retryAttemptsKey       = "retry-attempts"
+	retryBodyBufferSizeKey = "retry-body-buffer-size"
+	maxQueuedRequestsKey   = "max-queued-requests"
+	maxQueueWaitKey        = "max-queue-wait"
+	overloadStatusCodeKey  = "overload-status-code"
+	overloadRetryAfterKey  = "overload-retry-after"
+)
+
+// Activator holds the tunable parameters of the activator.
+type Activator struct {
+	// RetryAttempts is the number of times a request that failed to connect
+	// to a pod is retried against another one.
+	RetryAttempts int
+	// RetryBodyBufferSize is the size, in bytes, up to which the body of a
+	// request is buffered so that the request can be retried, whatever its
+	// method. 0 only retries the idempotent requests without a body.
+	RetryBodyBufferSize int64
+
+	// MaxQueuedRequests is the number of requests queued for a revision
+	// while it has no capacity for them, before the next ones are rejected.
+	MaxQueuedRequests int
+	// MaxQueueWait is how long a request waits in the queue before it's
+	// rejected. 0 waits for as long as the client does.
+	MaxQueueWait time.Duration
+	// OverloadStatusCode is the status code, 429 or 503, of the responses
+	// to the rejected requests.
+	OverloadStatusCode int
+	// OverloadRetryAfter is the Retry-After of the responses to the rejected
+	// requests. 0 leaves the header out.
+	OverloadRetryAfter time.Duration
+}
+
+// QueueLimits holds how the activator queues the requests of a revision.
+type QueueLimits struct {
+	MaxQueuedRequests  int
+	MaxQueueWait       time.Duration
+	OverloadStatusCode int
+	OverloadRetryAfter time.Duration
+}
+
+// QueueLimitsFor returns how the requests of a revision with the given
+// annotations are queued, the annotations taking precedence over the config.
+func (a *Activator) QueueLimitsFor(annotations map[string]string) QueueLimits {
+	ql := QueueLimits{
+		MaxQueuedRequests:  a.MaxQueuedRequests,
+		MaxQueueWait:       a.MaxQueueWait,
+		OverloadStatusCode: a.OverloadStatusCode,
+		OverloadRetryAfter: a.OverloadRetryAfter,
+	}
+	// no error check: relying on validation
+	if v, ok := annotations[autoscaling.ActivatorMaxQueuedRequestsAnnotationKey]; ok {
+		if i, err := strconv.Atoi(v); err == nil && i > 0 {
+			ql.MaxQueuedRequests = i
+		}
+	}
+	if v, ok := annotations[autoscaling.ActivatorMaxQueueWaitAnnotationKey]; ok {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			ql.MaxQueueWait = d
+		}
+	}
+	if v, ok := annotations[autoscaling.ActivatorOverloadStatusCodeAnnotationKey]; ok {
+		if i, err := strconv.Atoi(v); err == nil && isOverloadStatusCode(i) {
+			ql.OverloadStatusCode = i
+		}
+	}
+	if v, ok := annotations[autoscaling.ActivatorOverloadRetryAfterAnnotationKey]; ok {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			ql.OverloadRetryAfter = d
+		}
+	}
+	return ql
+}
+
+func isOverloadStatusCode(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
+
+// NewActivatorConfigFromMap creates an Activator config from the supplied map.
+func NewActivatorConfigFromMap(data map[string]string) (*Activator, error) {
+	ac := &Activator{
+		RetryAttempts:      2,
+		MaxQueuedRequests:  autoscaling.ActivatorMaxQueuedRequestsMax,
+		OverloadStatusCode: http.StatusServiceUnavailable,
+	}
+
+	if raw, ok := data[retryAttemptsKey]; ok {
+		val, err := strconv.Atoi(raw)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("%s must be an integer equal or greater than 0, got %q", retryAttemptsKey, raw)
+		}
+		ac.RetryAttempts = val
+	}
+
+	if raw, ok := data[retryBodyBufferSizeKey]; ok {
+		val, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("%s must be an integer equal or greater than 0, got %q", retryBodyBufferSizeKey, raw)
+		}
+		ac.RetryBodyBufferSize = val
+	}
+
+	if raw, ok := data[maxQueuedRequestsKey]; ok {
+		val, err := strconv.Atoi(raw)
+		if err != nil || val < 1 || val > autoscaling.ActivatorMaxQueuedRequestsMax {
+			return nil, fmt.Errorf("%s must be an integer between 1 and %d, got %q",
+				maxQueuedRequestsKey, autoscaling.ActivatorMaxQueuedRequestsMax, raw)
+		}
+		ac.MaxQueuedRequests = val
+	}
+
+	for _, dur := range []struct {
+		key   string
+		field *time.Duration
+	}{{
+		key:   maxQueueWaitKey,
+		field: &ac.MaxQueueWait,
+	}, {
+		key:   overloadRetryAfterKey,
+		field: &ac.OverloadRetryAfter,
+	}} {
+		if raw, ok := data[dur.key]; ok {
+			val, err := time.ParseDuration(raw)
+			if err != nil || val < 0 {
+				return nil, fmt.Errorf("%s must be a duration equal or greater than 0, got %q", dur.key, raw)
+			}
+			*dur.field = val
+		}
+	}
+
+	if raw, ok := data[overloadStatusCodeKey]; ok {
+		val, err := strconv.Atoi(raw)
+		if err != nil || !isOverloadStatusCode(val) {
+			return nil, fmt.Errorf("%s must be one of 429 or 503, got %q", overloadStatusCodeKey, raw)
+		}
+		ac.OverloadStatusCode = val
+	}
+
+	return ac, nil
+}
+
+// NewActivatorConfigFromConfigMap creates an Activator config from the supplied ConfigMap.
+func NewActivatorConfigFromConfigMap(configMap *corev1.ConfigMap) (*Activator, error) {
+	return NewActivatorConfigFromMap(configMap.Data)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/knative/pkg/configmap/testing"
+	"github.com/knative/serving/pkg/apis/autoscaling"
+)
+
+func TestActivatorConfig(t *testing.T) {
+	actual, example := ConfigMapsFromTestFile(t, ActivatorConfigName)
+	defaults := &Activator{
+		RetryAttempts:      2,
+		MaxQueuedRequests:  10000,
+		OverloadStatusCode: 503,
+	}
+
+	tests := []struct {
+		name    string
+		data    *corev1.ConfigMap
+		want    *Activator
+		wantErr bool
+	}{{
+		name: "actual config",
+		data: actual,
+		want: defaults,
+	}, {
+		name: "example config",
+		data: example,
+		want: defaults,
+	}, {
+		name: "with value overrides",
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"retry-attempts":         "0",
+				"retry-body-buffer-size": "4096",
+				"max-queued-requests":    "100",
+				"max-queue-wait":         "10s",
+				"overload-status-code":   "429",
+				"overload-retry-after":   "5s",
+			},
+		},
+		want: &Activator{
+			RetryAttempts:       0,
+			RetryBodyBufferSize: 4096,
+			MaxQueuedRequests:   100,
+			MaxQueueWait:        10 * time.Second,
+			OverloadStatusCode:  429,
+			OverloadRetryAfter:  5 * time.Second,
+		},
+	}, {
+		name: "negative retry attempts",
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"retry-attempts": "-1",
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "invalid retry body buffer size",
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"retry-body-buffer-size": "4k",
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "max queued requests too large",
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"max-queued-requests": "10001",
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "negative max queue wait",
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"max-queue-wait": "-1s",
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "unsupported overload status code",
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"overload-status-code": "500",
+			},
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewActivatorConfigFromConfigMap(test.data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewActivatorConfigFromConfigMap() = %v, want error: %v", err, test.wantErr)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("NewActivatorConfigFromConfigMap() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestQueueLimitsFor(t *testing.T) {
+	ac := &Activator{
+		MaxQueuedRequests:  10000,
+		MaxQueueWait:       time.Minute,
+		OverloadStatusCode: 503,
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        QueueLimits
+	}{{
+		name: "no annotations",
+		want: QueueLimits{
+			MaxQueuedRequests:  10000,
+			MaxQueueWait:       time.Minute,
+			OverloadStatusCode: 503,
+		},
+	}, {
+		name: "annotations",
+		annotations: map[string]string{
+			autoscaling.ActivatorMaxQueuedRequestsAnnotationKey:  "10",
+			autoscaling.ActivatorMaxQueueWaitAnnotationKey:       "0s",
+			autoscaling.ActivatorOverloadStatusCodeAnnotationKey: "429",
+			autoscaling.ActivatorOverloadRetryAfterAnnotationKey: "3s",
+		},
+		want: QueueLimits{
+			MaxQueuedRequests:  10,
+			OverloadStatusCode: 429,
+			OverloadRetryAfter: 3 * time.Second,
+		},
+	}, {
+		name: "invalid annotations",
+		annotations: map[string]string{
+			autoscaling.ActivatorMaxQueuedRequestsAnnotationKey:  "0",
+			autoscaling.ActivatorOverloadStatusCodeAnnotationKey: "500",
+		},
+		want: QueueLimits{
+			MaxQueuedRequests:  10000,
+			MaxQueueWait:       time.Minute,
+			OverloadStatusCode: 503,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ac.QueueLimitsFor(test.annotations); got != test.want {
+				t.Errorf("QueueLimitsFor() = %+v, want: %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -28,12 +28,14 @@ type cfgKey struct{}
 
 // Config is a configuration for the activator
 type Config struct {
-	Tracing *tracingconfig.Config
+	Activator *Activator
+	Tracing   *tracingconfig.Config
 }
 
-// FromContext obtains a Config injected into the passed context
+// FromContext obtains a Config injected into the passed context, nil if there is none
 func FromContext(ctx context.Context) *Config {
-	return ctx.Value(cfgKey{}).(*Config)
+	cfg, _ := ctx.Value(cfgKey{}).(*Config)
+	return cfg
 }
 
 func toContext(ctx context.Context, c *Config) context.Context {
@@ -53,6 +55,7 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 			"activator",
 			logger,
 			configmap.Constructors{
+				ActivatorConfigName:      NewActivatorConfigFromConfigMap,
 				tracingconfig.ConfigName: tracingconfig.NewTracingConfigFromConfigMap,
 			},
 			onAfterStore...,
@@ -68,7 +71,8 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 // Load creates a Config for this store
 func (s *Store) Load() *Config {
 	return &Config{
-		Tracing: s.UntypedLoad(tracingconfig.ConfigName).(*tracingconfig.Config).DeepCopy(),
+		Activator: s.UntypedLoad(ActivatorConfigName).(*Activator).DeepCopy(),
+		Tracing:   s.UntypedLoad(tracingconfig.ConfigName).(*tracingconfig.Config).DeepCopy(),
 	}
 }
 
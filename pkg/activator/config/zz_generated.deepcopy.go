@@ -24,9 +24,30 @@ import (
 	tracingconfig "github.com/knative/serving/pkg/tracing/config"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Activator) DeepCopyInto(out *Activator) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Activator.
+func (in *Activator) DeepCopy() *Activator {
+	if in == nil {
+		return nil
+	}
+	out := new(Activator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Config) DeepCopyInto(out *Config) {
 	*out = *in
+	if in.Activator != nil {
+		in, out := &in.Activator, &out.Activator
+		*out = new(Activator)
+		**out = **in
+	}
 	if in.Tracing != nil {
 		in, out := &in.Tracing, &out.Tracing
 		*out = new(tracingconfig.Config)
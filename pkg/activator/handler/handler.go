@@ -16,11 +16,18 @@ limitations under the License.
 package handler
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
 	"time"
 
 	"go.opencensus.io/plugin/ochttp"
@@ -29,6 +36,7 @@ import (
 
 	"github.com/knative/pkg/logging/logkey"
 	"github.com/knative/serving/pkg/activator"
+	activatorconfig "github.com/knative/serving/pkg/activator/config"
 	"github.com/knative/serving/pkg/activator/util"
 	"github.com/knative/serving/pkg/apis/networking"
 	"github.com/knative/serving/pkg/apis/serving"
@@ -64,6 +72,10 @@ type activationHandler struct {
 // The default time we'll try to probe the revision for activation.
 const defaulTimeout = 2 * time.Minute
 
+// statusClientClosedRequest is the non-standard status of the requests
+// the client gave up on, following the nginx convention.
+const statusClientClosedRequest = 499
+
 // New constructs a new http.Handler that deals with revision activation.
 func New(l *zap.SugaredLogger, r activator.StatsReporter, t *activator.Throttler,
 	rl servinglisters.RevisionLister, sl corev1listers.ServiceLister,
@@ -159,9 +171,17 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Host:   host,
 	}
 
+	limits := queueLimits(r.Context(), revision)
+	tryCtx := r.Context()
+	if limits.MaxQueueWait > 0 {
+		var cancel context.CancelFunc
+		tryCtx, cancel = context.WithTimeout(tryCtx, limits.MaxQueueWait)
+		defer cancel()
+	}
+
 	_, ttSpan := trace.StartSpan(r.Context(), "throttler_try")
 	ttStart := time.Now()
-	err = a.throttler.Try(revID, func() {
+	err = a.throttler.Try(tryCtx, revID, limits.MaxQueuedRequests, func() {
 		var (
 			httpStatus int
 		)
@@ -169,12 +189,39 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		ttSpan.End()
 		a.logger.Debugf("Waiting for throttler took %v time", time.Since(ttStart))
 
-		success, attempts := a.probeEndpoint(logger, r, target)
+		cc := int(revision.Spec.ContainerConcurrency)
+		dest, release := a.pickTarget(revID, cc, target, nil)
+		defer func() { release() }()
+
+		// Only the requests arriving before the revision is first probed
+		// ready wait on probing, the others are proxied right away.
+		success, attempts := a.throttler.WaitReady(revID, func() (bool, int) {
+			return a.probeEndpoint(logger, r, dest)
+		})
 		if success {
-			// Once we see a successful probe, send traffic.
-			attempts++
+			// Once we see a successful probe, send traffic, retrying against
+			// the other pods as long as we fail to connect and the budget allows.
+			retries, body := retryBudget(r)
+			var tried []string
 			reqCtx, proxySpan := trace.StartSpan(r.Context(), "proxy")
-			httpStatus = a.proxyRequest(w, r.WithContext(reqCtx), target)
+			for {
+				attempts++
+				if body != nil {
+					r.Body = ioutil.NopCloser(bytes.NewReader(body))
+				}
+				var retry bool
+				httpStatus, retry = a.proxyRequest(w, r.WithContext(reqCtx), dest, retries > 0)
+				if !retry {
+					break
+				}
+				retries--
+				logger.Infof("Failed to connect to %s, retrying the request", dest.Host)
+				if dest != target {
+					tried = append(tried, dest.Host)
+				}
+				release()
+				dest, release = a.pickTarget(revID, cc, target, tried)
+			}
 			proxySpan.End()
 		} else {
 			httpStatus = http.StatusInternalServerError
@@ -201,16 +248,55 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}, "ThrottlerTry")
 		ttSpan.End()
 
-		if err == activator.ErrActivatorOverload {
-			http.Error(w, activator.ErrActivatorOverload.Error(), http.StatusServiceUnavailable)
-		} else {
+		switch err {
+		case activator.ErrActivatorOverload, activator.ErrActivatorQueueTimeout:
+			if limits.OverloadRetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limits.OverloadRetryAfter.Seconds()))))
+			}
+			http.Error(w, err.Error(), limits.OverloadStatusCode)
+		case activator.ErrActivatorRequestCanceled:
+			// Nobody is left to read the response, so this is not an error of ours.
+			w.WriteHeader(statusClientClosedRequest)
+			logger.Debugw("The request was canceled while waiting for capacity", zap.Error(err))
+		default:
 			w.WriteHeader(http.StatusInternalServerError)
 			logger.Errorw("Error processing request in the activator", zap.Error(err))
 		}
 	}
 }
 
-func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request, target *url.URL) int {
+// queueLimits returns how the requests of the revision are queued, which
+// without a config is only bounded by the throttler.
+func queueLimits(ctx context.Context, revision *v1alpha1.Revision) activatorconfig.QueueLimits {
+	cfg := activatorconfig.FromContext(ctx)
+	if cfg == nil || cfg.Activator == nil {
+		return activatorconfig.QueueLimits{
+			OverloadStatusCode: http.StatusServiceUnavailable,
+		}
+	}
+	return cfg.Activator.QueueLimitsFor(revision.Annotations)
+}
+
+// pickTarget returns the least loaded pod of the revision, other than the
+// tried ones, or the private service target if we know no such pod, along
+// with the function to call once done with it.
+func (a *activationHandler) pickTarget(rev activator.RevisionID, containerConcurrency int, target *url.URL, tried []string) (*url.URL, func()) {
+	// Send the request straight to the pod, rather than leave balancing
+	// to the private service.
+	if podHost, release, ok := a.throttler.PickPod(rev, containerConcurrency, tried...); ok {
+		return &url.URL{
+			Scheme: "http",
+			Host:   podHost,
+		}, release
+	}
+	return target, func() {}
+}
+
+// proxyRequest proxies the request to the target and returns the status code
+// of the response. If it fails to connect to the target and canRetry is set,
+// it returns true without writing a response, so that the request can be
+// retried.
+func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request, target *url.URL, canRetry bool) (int, bool) {
 	network.RewriteHostIn(r)
 	recorder := pkghttp.NewResponseRecorder(w, http.StatusOK)
 	proxy := httputil.NewSingleHostReverseProxy(target)
@@ -219,12 +305,74 @@ func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request,
 	}
 	proxy.FlushInterval = -1
 
+	var retry bool
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		if canRetry && isDialError(err) {
+			retry = true
+			return
+		}
+		a.logger.Errorw("Error proxying request", zap.Error(err))
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
 	r.Header.Set(network.ProxyHeaderName, activator.Name)
 
 	util.SetupHeaderPruning(proxy)
 
 	proxy.ServeHTTP(recorder, r)
-	return recorder.ResponseCode
+	if retry {
+		return 0, true
+	}
+	return recorder.ResponseCode, false
+}
+
+// retryBudget returns the number of times the request can be retried, and
+// its body to replay on every attempt if it had to be buffered for that.
+// Idempotent requests without a body can always be retried, the others
+// only if their body fits in the configured buffer.
+func retryBudget(r *http.Request) (int, []byte) {
+	cfg := activatorconfig.FromContext(r.Context())
+	if cfg == nil || cfg.Activator == nil || cfg.Activator.RetryAttempts == 0 {
+		return 0, nil
+	}
+	attempts, limit := cfg.Activator.RetryAttempts, cfg.Activator.RetryBodyBufferSize
+
+	if r.Body == nil || r.Body == http.NoBody {
+		if isIdempotent(r.Method) || limit > 0 {
+			return attempts, nil
+		}
+		return 0, nil
+	}
+	if limit == 0 || r.ContentLength > limit {
+		return 0, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil || int64(len(body)) > limit {
+		// Hand what we read back to the request.
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return 0, nil
+	}
+	return attempts, body
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isDialError returns whether the error is a failure to connect, in which
+// case the request never reached the target.
+func isDialError(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial"
 }
 
 // serviceHostName obtains the hostname of the underlying service and the correct
@@ -15,9 +15,12 @@ limitations under the License.
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -36,12 +39,15 @@ import (
 	. "github.com/knative/pkg/logging/testing"
 	_ "github.com/knative/pkg/system/testing"
 	"github.com/knative/serving/pkg/activator"
+	activatorconfig "github.com/knative/serving/pkg/activator/config"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	nv1a1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving/v1beta1"
 	servingfake "github.com/knative/serving/pkg/client/clientset/versioned/fake"
 	servinginformers "github.com/knative/serving/pkg/client/informers/externalversions"
+	netinformers "github.com/knative/serving/pkg/client/informers/externalversions/networking/v1alpha1"
 	netlisters "github.com/knative/serving/pkg/client/listers/networking/v1alpha1"
 	servinglisters "github.com/knative/serving/pkg/client/listers/serving/v1alpha1"
 	"github.com/knative/serving/pkg/network"
@@ -293,8 +299,9 @@ func TestActivationHandler(t *testing.T) {
 			throttler := activator.NewThrottler(
 				params,
 				test.endpointsInformer,
-				sksLister(sks(testNamespace, testRevName)),
+				sksInformer(sks(testNamespace, testRevName)),
 				revisionLister(revision(testNamespace, testRevName)),
+				"",
 				TestLogger(t))
 
 			handler := (New(TestLogger(t), reporter, throttler,
@@ -360,8 +367,9 @@ func TestActivationHandlerOverflow(t *testing.T) {
 	throttler := activator.NewThrottler(
 		breakerParams,
 		endpointsInformer(endpoints(namespace, revName, breakerParams.InitialCapacity)),
-		sksLister(sks(namespace, revName)),
+		sksInformer(sks(namespace, revName)),
 		revisionLister(revision(namespace, revName)),
+		"",
 		TestLogger(t))
 
 	handler := (New(TestLogger(t), reporter, throttler,
@@ -393,18 +401,18 @@ func TestActivationHandlerOverflowSeveralRevisions(t *testing.T) {
 	breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 10}
 	reporter := &fakeReporter{}
 	epClient := endpointsInformer(endpoints(testNamespace, rev1, breakerParams.InitialCapacity), endpoints(testNamespace, rev2, breakerParams.InitialCapacity))
-	sksClient := sksLister(sks(testNamespace, rev1), sks(testNamespace, rev2))
+	sksClient := sksInformer(sks(testNamespace, rev1), sks(testNamespace, rev2))
 	revClient := revisionLister(revision(testNamespace, rev1), revision(testNamespace, rev2))
 	svcClient := serviceLister(service(testNamespace, rev1, "http"), service(testNamespace, rev2, "http"))
 
 	respCh := make(chan *httptest.ResponseRecorder, overallRequests)
 	lockerCh := make(chan struct{})
 
-	throttler := activator.NewThrottler(breakerParams, epClient, sksClient, revClient, TestLogger(t))
+	throttler := activator.NewThrottler(breakerParams, epClient, sksClient, revClient, "", TestLogger(t))
 
 	rt := getRT(t, nil, 200, []string{}, nil, wantBody, "", lockerCh)
 	handler := (New(TestLogger(t), reporter, throttler,
-		revClient, svcClient, sksClient)).(*activationHandler)
+		revClient, svcClient, sksClient.Lister())).(*activationHandler)
 
 	// Setup transports.
 	handler.transport = rt
@@ -430,8 +438,9 @@ func TestActivationHandlerProxyHeader(t *testing.T) {
 	throttler := activator.NewThrottler(
 		breakerParams,
 		endpointsInformer(endpoints(namespace, revName, breakerParams.InitialCapacity)),
-		sksLister(sks(namespace, revName)),
+		sksInformer(sks(namespace, revName)),
 		revisionLister(revision(namespace, revName)),
+		"",
 		TestLogger(t))
 
 	probeRt := getRT(t, nil, 200, []string{}, nil, wantBody, "", nil)
@@ -463,6 +472,357 @@ func TestActivationHandlerProxyHeader(t *testing.T) {
 	}
 }
 
+func TestActivationHandlerPodTarget(t *testing.T) {
+	breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 10}
+	namespace, revName := testNamespace, testRevName
+
+	interceptCh := make(chan *http.Request, 1)
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		interceptCh <- r
+		fake := httptest.NewRecorder()
+		return fake.Result(), nil
+	})
+	ep := endpoints(namespace, revName, 1)
+	ep.Subsets[0].Ports = []corev1.EndpointPort{{Name: "http", Port: 8012}}
+	throttler := activator.NewThrottler(
+		breakerParams,
+		endpointsInformer(ep),
+		sksInformer(sks(namespace, revName)),
+		revisionLister(revision(namespace, revName)),
+		"",
+		TestLogger(t))
+
+	probeRt := getRT(t, nil, 200, []string{}, nil, wantBody, "", nil)
+
+	handler := activationHandler{
+		transport:             rt,
+		probeTransportFactory: rtFact(probeRt),
+		logger:                TestLogger(t),
+		reporter:              &fakeReporter{},
+		throttler:             throttler,
+		revisionLister:        revisionLister(revision(testNamespace, testRevName)),
+		serviceLister:         serviceLister(service(testNamespace, testRevName, "http")),
+		sksLister:             sksLister(sks(testNamespace, testRevName)),
+	}
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+	req.Header.Set(activator.RevisionHeaderNamespace, namespace)
+	req.Header.Set(activator.RevisionHeaderName, revName)
+	handler.ServeHTTP(writer, req)
+
+	select {
+	case httpReq := <-interceptCh:
+		if got, want := httpReq.URL.Host, "127.0.0.1:8012"; got != want {
+			t.Errorf("Request sent to %q, want: %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a request to be intercepted")
+	}
+}
+
+func TestActivationHandlerProbesOnce(t *testing.T) {
+	breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 10}
+	namespace, revName := testNamespace, testRevName
+
+	probes := 0
+	rt := getRT(t, nil, 200, []string{}, nil, wantBody, "", nil)
+	probeRt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		probes++
+		return rt(r)
+	})
+	throttler := activator.NewThrottler(
+		breakerParams,
+		endpointsInformer(endpoints(namespace, revName, 1)),
+		sksInformer(sks(namespace, revName)),
+		revisionLister(revision(namespace, revName)),
+		"",
+		TestLogger(t))
+
+	reporter := &fakeReporter{}
+	handler := activationHandler{
+		transport:             rt,
+		probeTransportFactory: rtFact(probeRt),
+		probeTimeout:          100 * time.Millisecond,
+		logger:                TestLogger(t),
+		reporter:              reporter,
+		throttler:             throttler,
+		revisionLister:        revisionLister(revision(testNamespace, testRevName)),
+		serviceLister:         serviceLister(service(testNamespace, testRevName, "http")),
+		sksLister:             sksLister(sks(testNamespace, testRevName)),
+	}
+
+	for i := 0; i < 3; i++ {
+		if resp := sendRequest(namespace, revName, handler); resp.Code != http.StatusOK {
+			t.Fatalf("Request %d: StatusCode = %d, want: %d", i, resp.Code, http.StatusOK)
+		}
+	}
+	if probes != 1 {
+		t.Errorf("probes = %d, want: 1", probes)
+	}
+
+	var attempts []int
+	for _, call := range reporter.calls {
+		if call.Op == "ReportRequestCount" {
+			attempts = append(attempts, call.Attempts)
+		}
+	}
+	if got, want := attempts, []int{2, 1, 1}; !cmp.Equal(got, want) {
+		t.Errorf("Attempts = %v, want: %v", got, want)
+	}
+}
+
+func TestActivationHandlerRetry(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		config       map[string]string
+		failures     int
+		err          error
+		wantStatus   int
+		wantHosts    int
+		wantAttempts int
+	}{{
+		name:         "retries on another pod",
+		method:       http.MethodGet,
+		failures:     1,
+		wantStatus:   http.StatusOK,
+		wantHosts:    2,
+		wantAttempts: 3,
+	}, {
+		name:         "runs out of retries",
+		method:       http.MethodGet,
+		config:       map[string]string{"retry-attempts": "1"},
+		failures:     5,
+		wantStatus:   http.StatusBadGateway,
+		wantHosts:    2,
+		wantAttempts: 3,
+	}, {
+		name:         "retries disabled",
+		method:       http.MethodGet,
+		config:       map[string]string{"retry-attempts": "0"},
+		failures:     1,
+		wantStatus:   http.StatusBadGateway,
+		wantHosts:    1,
+		wantAttempts: 2,
+	}, {
+		name:         "not a connection failure",
+		method:       http.MethodGet,
+		failures:     1,
+		err:          errors.New("connection reset by peer"),
+		wantStatus:   http.StatusBadGateway,
+		wantHosts:    1,
+		wantAttempts: 2,
+	}, {
+		name:         "body without a buffer",
+		method:       http.MethodPost,
+		body:         "hi",
+		failures:     1,
+		wantStatus:   http.StatusBadGateway,
+		wantHosts:    1,
+		wantAttempts: 2,
+	}, {
+		name:         "body fitting the buffer",
+		method:       http.MethodPost,
+		body:         "hi",
+		config:       map[string]string{"retry-body-buffer-size": "5"},
+		failures:     1,
+		wantStatus:   http.StatusOK,
+		wantHosts:    2,
+		wantAttempts: 3,
+	}, {
+		name:         "body over the buffer",
+		method:       http.MethodPost,
+		body:         "hello world!",
+		config:       map[string]string{"retry-body-buffer-size": "5"},
+		wantStatus:   http.StatusOK,
+		wantHosts:    1,
+		wantAttempts: 2,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namespace, revName := testNamespace, testRevName
+			breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 10}
+
+			failures := test.failures
+			var hosts []string
+			rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				hosts = append(hosts, r.URL.Host)
+				if failures > 0 {
+					failures--
+					if test.err != nil {
+						return nil, test.err
+					}
+					return nil, dialErr
+				}
+				if r.Body != nil {
+					if body, _ := ioutil.ReadAll(r.Body); string(body) != test.body {
+						t.Errorf("Body = %q, want: %q", body, test.body)
+					}
+				}
+				fake := httptest.NewRecorder()
+				fake.WriteHeader(http.StatusOK)
+				return fake.Result(), nil
+			})
+			ep := endpoints(namespace, revName, 3)
+			ep.Subsets[0].Ports = []corev1.EndpointPort{{Name: "http", Port: 8012}}
+			throttler := activator.NewThrottler(
+				breakerParams,
+				endpointsInformer(ep),
+				sksInformer(sks(namespace, revName)),
+				revisionLister(revision(namespace, revName)),
+				"",
+				TestLogger(t))
+
+			reporter := &fakeReporter{}
+			handler := activationHandler{
+				transport:             rt,
+				probeTransportFactory: rtFact(getRT(t, nil, 200, []string{}, nil, wantBody, "", nil)),
+				probeTimeout:          100 * time.Millisecond,
+				logger:                TestLogger(t),
+				reporter:              reporter,
+				throttler:             throttler,
+				revisionLister:        revisionLister(revision(testNamespace, testRevName)),
+				serviceLister:         serviceLister(service(testNamespace, testRevName, "http")),
+				sksLister:             sksLister(sks(testNamespace, testRevName)),
+			}
+
+			store := activatorconfig.NewStore(TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: activatorconfig.ActivatorConfigName},
+				Data:       test.config,
+			})
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: tracingconfig.ConfigName},
+			})
+
+			var body io.Reader
+			if test.body != "" {
+				body = strings.NewReader(test.body)
+			}
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://example.com", body)
+			req.Header.Set(activator.RevisionHeaderNamespace, namespace)
+			req.Header.Set(activator.RevisionHeaderName, revName)
+			handler.ServeHTTP(resp, req.WithContext(store.ToContext(req.Context())))
+
+			if resp.Code != test.wantStatus {
+				t.Errorf("StatusCode = %d, want: %d", resp.Code, test.wantStatus)
+			}
+			seen := make(map[string]bool, len(hosts))
+			for _, h := range hosts {
+				if seen[h] {
+					t.Errorf("Request sent to %s more than once, hosts: %v", h, hosts)
+				}
+				seen[h] = true
+			}
+			if len(hosts) != test.wantHosts {
+				t.Errorf("Request sent to %v, want %d pods", hosts, test.wantHosts)
+			}
+			for _, call := range reporter.calls {
+				if call.Op == "ReportRequestCount" && call.Attempts != test.wantAttempts {
+					t.Errorf("Attempts = %d, want: %d", call.Attempts, test.wantAttempts)
+				}
+			}
+		})
+	}
+}
+
+func TestActivationHandlerQueueLimits(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         map[string]string
+		annotations    map[string]string
+		canceled       bool
+		wantStatus     int
+		wantRetryAfter string
+	}{{
+		name:       "waits too long",
+		config:     map[string]string{"max-queue-wait": "50ms"},
+		wantStatus: http.StatusServiceUnavailable,
+	}, {
+		name: "config",
+		config: map[string]string{
+			"max-queue-wait":       "50ms",
+			"overload-status-code": "429",
+			"overload-retry-after": "5s",
+		},
+		wantStatus:     http.StatusTooManyRequests,
+		wantRetryAfter: "5",
+	}, {
+		name:   "annotations",
+		config: map[string]string{"max-queue-wait": "1m"},
+		annotations: map[string]string{
+			autoscaling.ActivatorMaxQueueWaitAnnotationKey:       "50ms",
+			autoscaling.ActivatorOverloadStatusCodeAnnotationKey: "429",
+			autoscaling.ActivatorOverloadRetryAfterAnnotationKey: "1500ms",
+		},
+		wantStatus:     http.StatusTooManyRequests,
+		wantRetryAfter: "2",
+	}, {
+		name:       "client goes away",
+		config:     map[string]string{"max-queue-wait": "1m"},
+		canceled:   true,
+		wantStatus: statusClientClosedRequest,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namespace, revName := testNamespace, testRevName
+			breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 0}
+			rev := revision(namespace, revName)
+			rev.Annotations = test.annotations
+
+			// Without endpoints, there's no capacity for the request.
+			throttler := activator.NewThrottler(
+				breakerParams,
+				endpointsInformer(endpoints(namespace, revName, 0)),
+				sksInformer(sks(namespace, revName)),
+				revisionLister(rev),
+				"",
+				TestLogger(t))
+
+			handler := (New(TestLogger(t), &fakeReporter{}, throttler,
+				revisionLister(rev),
+				serviceLister(service(namespace, revName, "http")),
+				sksLister(sks(namespace, revName)),
+			)).(*activationHandler)
+
+			store := activatorconfig.NewStore(TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: activatorconfig.ActivatorConfigName},
+				Data:       test.config,
+			})
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: tracingconfig.ConfigName},
+			})
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Header.Set(activator.RevisionHeaderNamespace, namespace)
+			req.Header.Set(activator.RevisionHeaderName, revName)
+			ctx := store.ToContext(req.Context())
+			if test.canceled {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			}
+			handler.ServeHTTP(resp, req.WithContext(ctx))
+
+			if resp.Code != test.wantStatus {
+				t.Errorf("StatusCode = %d, want: %d", resp.Code, test.wantStatus)
+			}
+			if got := resp.Header().Get("Retry-After"); got != test.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want: %q", got, test.wantRetryAfter)
+			}
+		})
+	}
+}
+
 func TestActivationHandlerTraceSpans(t *testing.T) {
 	// Setup transport
 	rt := getRT(t, nil, 200, []string{}, nil, "hello", "", nil)
@@ -491,8 +851,9 @@ func TestActivationHandlerTraceSpans(t *testing.T) {
 	throttler := activator.NewThrottler(
 		breakerParams,
 		endpointsInformer(endpoints(namespace, revName, breakerParams.InitialCapacity)),
-		sksLister(sks(namespace, revName)),
+		sksInformer(sks(namespace, revName)),
 		revisionLister(revision(namespace, revName)),
+		"",
 		TestLogger(t))
 
 	handler := activationHandler{
@@ -714,6 +1075,21 @@ func (f *fakeReporter) ReportResponseTime(ns, service, config, rev string, respo
 	return nil
 }
 
+func (f *fakeReporter) ReportQueueLength(ns, service, config, rev string, v int64) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls = append(f.calls, reporterCall{
+		Op:        "ReportQueueLength",
+		Namespace: ns,
+		Service:   service,
+		Config:    config,
+		Revision:  rev,
+		Value:     v,
+	})
+
+	return nil
+}
+
 func revision(namespace, name string) *v1alpha1.Revision {
 	return &v1alpha1.Revision{
 		ObjectMeta: metav1.ObjectMeta{
@@ -786,7 +1162,7 @@ func sks(namespace, name string) *nv1a1.ServerlessService {
 	}
 }
 
-func sksLister(skss ...*nv1a1.ServerlessService) netlisters.ServerlessServiceLister {
+func sksInformer(skss ...*nv1a1.ServerlessService) netinformers.ServerlessServiceInformer {
 	fake := servingfake.NewSimpleClientset()
 	informer := servinginformers.NewSharedInformerFactory(fake, 0)
 	services := informer.Networking().V1alpha1().ServerlessServices()
@@ -796,7 +1172,11 @@ func sksLister(skss ...*nv1a1.ServerlessService) netlisters.ServerlessServiceLis
 		services.Informer().GetIndexer().Add(sks)
 	}
 
-	return services.Lister()
+	return services
+}
+
+func sksLister(skss ...*nv1a1.ServerlessService) netlisters.ServerlessServiceLister {
+	return sksInformer(skss...).Lister()
 }
 
 func endpoints(namespace, name string, count int) *corev1.Endpoints {
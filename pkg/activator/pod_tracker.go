@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podTracker counts the requests this activator has in flight to a pod.
+type podTracker struct {
+	dest     string
+	inFlight int
+}
+
+// podTrackers balances the requests to a revision across its ready pods.
+// Each request goes to the pod with the fewest requests in flight, among
+// the pods with capacity to spare.
+type podTrackers struct {
+	mux  sync.Mutex
+	pods []*podTracker
+}
+
+// update replaces the tracked pods with the given destinations, keeping the
+// requests in flight to the pods that remain.
+func (pt *podTrackers) update(dests []string) {
+	pt.mux.Lock()
+	defer pt.mux.Unlock()
+
+	existing := make(map[string]*podTracker, len(pt.pods))
+	for _, p := range pt.pods {
+		existing[p.dest] = p
+	}
+	pods := make([]*podTracker, 0, len(dests))
+	for _, dest := range dests {
+		p, ok := existing[dest]
+		if !ok {
+			p = &podTracker{dest: dest}
+		}
+		pods = append(pods, p)
+	}
+	pt.pods = pods
+}
+
+// acquire picks the pod to send a request to and counts the request in
+// flight until the returned function is called. A pod has capacity to spare
+// while it has fewer than containerConcurrency requests in flight, 0 meaning
+// unlimited. The excluded pods are never picked. acquire returns false if no
+// pod has capacity to spare.
+func (pt *podTrackers) acquire(containerConcurrency int, exclude ...string) (string, func(), bool) {
+	pt.mux.Lock()
+	defer pt.mux.Unlock()
+
+	if len(pt.pods) == 0 {
+		return "", nil, false
+	}
+	// Start at a random pod, so that ties don't all go to the same pod
+	// across activators.
+	var picked *podTracker
+	start := rand.Intn(len(pt.pods))
+	for i := range pt.pods {
+		p := pt.pods[(start+i)%len(pt.pods)]
+		if containerConcurrency > 0 && p.inFlight >= containerConcurrency || excluded(p.dest, exclude) {
+			continue
+		}
+		if picked == nil || p.inFlight < picked.inFlight {
+			picked = p
+		}
+	}
+	if picked == nil {
+		return "", nil, false
+	}
+
+	picked.inFlight++
+	return picked.dest, func() {
+		pt.mux.Lock()
+		defer pt.mux.Unlock()
+		picked.inFlight--
+	}, true
+}
+
+func excluded(dest string, exclude []string) bool {
+	for _, e := range exclude {
+		if dest == e {
+			return true
+		}
+	}
+	return false
+}
+
+// readyDests returns the host:port destinations of the ready addresses of the
+// endpoints. The private service of a revision exposes a single port.
+func readyDests(endpoints *corev1.Endpoints) []string {
+	var dests []string
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Ports) == 0 {
+			continue
+		}
+		port := strconv.Itoa(int(subset.Ports[0].Port))
+		for _, addr := range subset.Addresses {
+			dests = append(dests, net.JoinHostPort(addr.IP, port))
+		}
+	}
+	return dests
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodTrackersAcquire(t *testing.T) {
+	tests := []struct {
+		name                 string
+		dests                []string
+		containerConcurrency int
+		exclude              []string
+		requests             int
+		want                 []string
+	}{{
+		name:     "no pods",
+		requests: 1,
+	}, {
+		name:     "spreads a burst across the pods",
+		dests:    []string{"10.0.0.1:8012", "10.0.0.2:8012", "10.0.0.3:8012"},
+		requests: 3,
+		want:     []string{"10.0.0.1:8012", "10.0.0.2:8012", "10.0.0.3:8012"},
+	}, {
+		name:     "unlimited concurrency",
+		dests:    []string{"10.0.0.1:8012", "10.0.0.2:8012"},
+		requests: 4,
+		want:     []string{"10.0.0.1:8012", "10.0.0.1:8012", "10.0.0.2:8012", "10.0.0.2:8012"},
+	}, {
+		name:                 "respects the container concurrency",
+		dests:                []string{"10.0.0.1:8012", "10.0.0.2:8012"},
+		containerConcurrency: 1,
+		requests:             3,
+		want:                 []string{"10.0.0.1:8012", "10.0.0.2:8012"},
+	}, {
+		name:     "skips the excluded pods",
+		dests:    []string{"10.0.0.1:8012", "10.0.0.2:8012", "10.0.0.3:8012"},
+		exclude:  []string{"10.0.0.1:8012", "10.0.0.3:8012"},
+		requests: 2,
+		want:     []string{"10.0.0.2:8012", "10.0.0.2:8012"},
+	}, {
+		name:     "all pods excluded",
+		dests:    []string{"10.0.0.1:8012"},
+		exclude:  []string{"10.0.0.1:8012"},
+		requests: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pt := &podTrackers{}
+			pt.update(test.dests)
+
+			var got []string
+			for i := 0; i < test.requests; i++ {
+				if dest, _, ok := pt.acquire(test.containerConcurrency, test.exclude...); ok {
+					got = append(got, dest)
+				}
+			}
+			sort.Strings(got)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("acquired = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPodTrackersRelease(t *testing.T) {
+	pt := &podTrackers{}
+	pt.update([]string{"10.0.0.1:8012"})
+
+	_, release, ok := pt.acquire(1)
+	if !ok {
+		t.Fatal("acquire() = false, want true")
+	}
+	if _, _, ok := pt.acquire(1); ok {
+		t.Error("acquire() on a busy pod = true, want false")
+	}
+
+	// The pod stays busy across updates.
+	pt.update([]string{"10.0.0.1:8012"})
+	if _, _, ok := pt.acquire(1); ok {
+		t.Error("acquire() on a busy pod after update = true, want false")
+	}
+
+	release()
+	if dest, _, ok := pt.acquire(1); !ok || dest != "10.0.0.1:8012" {
+		t.Errorf("acquire() after release = (%q, %v), want: (%q, true)", dest, ok, "10.0.0.1:8012")
+	}
+}
+
+func TestReadyDests(t *testing.T) {
+	ep := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+			Ports:     []corev1.EndpointPort{{Name: "http", Port: 8012}},
+		}, {
+			// No ports, no destinations.
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.3"}},
+		}},
+	}
+	want := []string{"10.0.0.1:8012", "10.0.0.2:8012"}
+	if got := readyDests(ep); !cmp.Equal(got, want) {
+		t.Errorf("readyDests() = %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import "sync"
+
+// readiness remembers whether a revision was probed ready since it last had
+// no ready pods. A single probe runs at a time, and all the requests waiting
+// for the revision to become ready share its result.
+type readiness struct {
+	mux   sync.Mutex
+	ready bool
+	// probing is closed once the probe in flight is done, nil without one.
+	probing chan struct{}
+	// generation changes on every reset, so that a probe started before a
+	// reset doesn't mark the revision ready after it.
+	generation int
+}
+
+// wait returns whether the revision is ready, running probe unless the
+// revision is known to be ready or another request is already probing it.
+// If the readiness is reset while waiting for another request's probe, wait
+// joins or starts a new probe. The returned attempts are those of probe, if
+// wait ran it.
+func (r *readiness) wait(probe func() (bool, int)) (ready bool, attempts int) {
+	r.mux.Lock()
+	for r.probing != nil && !r.ready {
+		probing, generation := r.probing, r.generation
+		r.mux.Unlock()
+		<-probing
+		r.mux.Lock()
+		if generation == r.generation {
+			// The probe we waited for settled the readiness.
+			defer r.mux.Unlock()
+			return r.ready, 0
+		}
+	}
+	if r.ready {
+		r.mux.Unlock()
+		return true, 0
+	}
+	probing := make(chan struct{})
+	r.probing = probing
+	generation := r.generation
+	r.mux.Unlock()
+
+	ready, attempts = probe()
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if generation == r.generation {
+		r.ready = ready
+		r.probing = nil
+	}
+	close(probing)
+	return ready, attempts
+}
+
+// reset forgets that the revision was probed ready.
+func (r *readiness) reset() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.ready = false
+	r.probing = nil
+	r.generation++
+}
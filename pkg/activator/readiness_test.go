@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadinessWait(t *testing.T) {
+	r := &readiness{}
+	probes := 0
+	probe := func(ready bool) func() (bool, int) {
+		return func() (bool, int) {
+			probes++
+			return ready, 3
+		}
+	}
+
+	if ready, attempts := r.wait(probe(false)); ready || attempts != 3 {
+		t.Errorf("wait() = (%v, %d), want: (false, 3)", ready, attempts)
+	}
+	if ready, attempts := r.wait(probe(true)); !ready || attempts != 3 {
+		t.Errorf("wait() = (%v, %d), want: (true, 3)", ready, attempts)
+	}
+	if ready, attempts := r.wait(probe(true)); !ready || attempts != 0 {
+		t.Errorf("wait() once ready = (%v, %d), want: (true, 0)", ready, attempts)
+	}
+	if probes != 2 {
+		t.Errorf("probes = %d, want: 2", probes)
+	}
+
+	r.reset()
+	if ready, attempts := r.wait(probe(true)); !ready || attempts != 3 {
+		t.Errorf("wait() after reset() = (%v, %d), want: (true, 3)", ready, attempts)
+	}
+	if probes != 3 {
+		t.Errorf("probes = %d, want: 3", probes)
+	}
+}
+
+func TestReadinessWaitSharesProbe(t *testing.T) {
+	const waiters = 5
+	r := &readiness{}
+	probing := make(chan struct{})
+	unblock := make(chan struct{})
+	probes := 0
+	probe := func() (bool, int) {
+		probes++
+		close(probing)
+		<-unblock
+		return true, 1
+	}
+
+	results := make(chan int, waiters+1)
+	go func() {
+		_, attempts := r.wait(probe)
+		results <- attempts
+	}()
+	<-probing
+
+	var wg sync.WaitGroup
+	wg.Add(waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			defer wg.Done()
+			ready, attempts := r.wait(probe)
+			if !ready {
+				t.Error("wait() = false, want: true")
+			}
+			results <- attempts
+		}()
+	}
+	close(unblock)
+	wg.Wait()
+
+	total := 0
+	for i := 0; i < waiters+1; i++ {
+		total += <-results
+	}
+	if probes != 1 || total != 1 {
+		t.Errorf("(probes, attempts) = (%d, %d), want: (1, 1)", probes, total)
+	}
+}
+
+func TestReadinessResetWhileProbing(t *testing.T) {
+	r := &readiness{}
+	ready, _ := r.wait(func() (bool, int) {
+		r.reset()
+		return true, 1
+	})
+	if !ready {
+		t.Error("wait() = false, want: true")
+	}
+	if r.ready {
+		t.Error("ready after a reset() during the probe = true, want: false")
+	}
+}
+
+func TestReadinessResetWhileWaiting(t *testing.T) {
+	const waiters = 5
+	r := &readiness{}
+	probing := make(chan struct{})
+	unblock := make(chan struct{})
+	var probesMux sync.Mutex
+	probes := 0
+	probe := func() (bool, int) {
+		probesMux.Lock()
+		probes++
+		first := probes == 1
+		probesMux.Unlock()
+		if first {
+			// The revision's endpoints change while the first probe runs.
+			close(probing)
+			<-unblock
+			r.reset()
+		}
+		return true, 1
+	}
+
+	go r.wait(probe)
+	<-probing
+
+	var wg sync.WaitGroup
+	wg.Add(waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			defer wg.Done()
+			if ready, _ := r.wait(probe); !ready {
+				t.Error("wait() after a reset() during the probe = false, want: true")
+			}
+		}()
+	}
+	// Give the waiters time to wait for the first probe.
+	time.Sleep(10 * time.Millisecond)
+	close(unblock)
+	wg.Wait()
+
+	if !r.ready {
+		t.Error("ready after the second probe = false, want: true")
+	}
+	if probes != 2 {
+		t.Errorf("probes = %d, want: 2", probes)
+	}
+}
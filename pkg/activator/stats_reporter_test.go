@@ -33,6 +33,7 @@ func unregister() {
 	for _, s := range []string{
 		"request_count",
 		"request_latencies",
+		"request_queue_length",
 	} {
 		if v := view.Find(s); v != nil {
 			view.Unregister(v)
@@ -85,6 +86,17 @@ func TestActivatorReporter(t *testing.T) {
 		return r.ReportResponseTime("testns", "testsvc", "testconfig", "testrev", 200, 9100*time.Millisecond)
 	})
 	checkDistributionData(t, "request_latencies", wantTags3, 2, 1100.0, 9100.0)
+
+	// test ReportQueueLength
+	wantTags4 := map[string]string{
+		metricskey.LabelNamespaceName:     "testns",
+		metricskey.LabelServiceName:       "testsvc",
+		metricskey.LabelConfigurationName: "testconfig",
+		metricskey.LabelRevisionName:      "testrev",
+	}
+	expectSuccess(t, func() error { return r.ReportQueueLength("testns", "testsvc", "testconfig", "testrev", 7) })
+	expectSuccess(t, func() error { return r.ReportQueueLength("testns", "testsvc", "testconfig", "testrev", 3) })
+	checkLastValueData(t, "request_queue_length", wantTags4, 3)
 }
 
 func TestReportRequestCount_EmptyServiceName(t *testing.T) {
@@ -191,3 +203,28 @@ func checkDistributionData(t *testing.T, name string, wantTags map[string]string
 		}
 	}
 }
+
+func checkLastValueData(t *testing.T, name string, wantTags map[string]string, wantValue float64) {
+	t.Helper()
+	if d, err := view.RetrieveData(name); err != nil {
+		t.Errorf("Unexpected reporter error: %v", err)
+	} else {
+		if len(d) != 1 {
+			t.Errorf("Reporter len(d) = %d, want: 1", len(d))
+		}
+		for _, got := range d[0].Tags {
+			n := got.Key.Name()
+			if want, ok := wantTags[n]; !ok {
+				t.Errorf("Reporter got an extra tag %v: %v", n, got.Value)
+			} else if got.Value != want {
+				t.Errorf("Reporter expected a different tag value for key: %s, got: %s, want: %s", n, got.Value, want)
+			}
+		}
+
+		if s, ok := d[0].Data.(*view.LastValueData); !ok {
+			t.Error("Reporter expected a LastValueData type")
+		} else if s.Value != wantValue {
+			t.Errorf("For %s value = %v, want: %v", name, s.Value, wantValue)
+		}
+	}
+}
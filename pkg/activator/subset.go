@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"github.com/knative/serving/pkg/autoscaler/sharding"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AssignedActivators returns the IPs of the n activators, out of the given
+// ones, that the revision is assigned to. The revisions are spread across
+// the activators by consistent hashing, so that activators coming and going
+// only move the revisions assigned to them. All the activators are assigned
+// when n is 0.
+func AssignedActivators(rev RevisionID, activators []string, n int) []string {
+	if n <= 0 || n >= len(activators) {
+		return activators
+	}
+	return sharding.NewRing(activators).Owners(rev.String(), n)
+}
+
+// SubsetEndpoints returns the endpoints of the n ready activators, out of
+// those behind the given endpoints, that the revision is assigned to. It
+// returns the given endpoints when n is 0.
+func SubsetEndpoints(rev RevisionID, activatorEps *corev1.Endpoints, n int) *corev1.Endpoints {
+	if n <= 0 {
+		return activatorEps
+	}
+	assigned := make(map[string]struct{}, n)
+	for _, ip := range AssignedActivators(rev, sharding.MembersFromEndpoints(activatorEps), n) {
+		assigned[ip] = struct{}{}
+	}
+
+	ret := activatorEps.DeepCopy()
+	ret.Subsets = nil
+	for _, subset := range activatorEps.Subsets {
+		var addresses []corev1.EndpointAddress
+		for _, addr := range subset.Addresses {
+			if _, ok := assigned[addr.IP]; ok {
+				addresses = append(addresses, addr)
+			}
+		}
+		if len(addresses) == 0 {
+			continue
+		}
+		ret.Subsets = append(ret.Subsets, corev1.EndpointSubset{
+			Addresses: addresses,
+			Ports:     subset.Ports,
+		})
+	}
+	return ret
+}
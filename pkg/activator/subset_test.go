@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAssignedActivators(t *testing.T) {
+	activators := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}
+
+	if got := AssignedActivators(revID, activators, 0); !cmp.Equal(got, activators) {
+		t.Errorf("AssignedActivators(0) = %v, want: %v", got, activators)
+	}
+	if got := AssignedActivators(revID, activators, 5); !cmp.Equal(got, activators) {
+		t.Errorf("AssignedActivators(5) = %v, want: %v", got, activators)
+	}
+	got := AssignedActivators(revID, activators, 2)
+	if len(got) != 2 {
+		t.Fatalf("AssignedActivators(2) = %v, want 2 activators", got)
+	}
+	// The assignment doesn't depend on the order of the activators.
+	reversed := []string{"10.0.0.4", "10.0.0.3", "10.0.0.2", "10.0.0.1"}
+	if again := AssignedActivators(revID, reversed, 2); !cmp.Equal(got, again) {
+		t.Errorf("AssignedActivators(2) = %v, then %v, want the same", got, again)
+	}
+}
+
+func TestSubsetEndpoints(t *testing.T) {
+	port := corev1.EndpointPort{Name: "http", Port: 8012}
+	eps := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+			NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.5"}},
+			Ports:             []corev1.EndpointPort{port},
+		}, {
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.3"}, {IP: "10.0.0.4"}},
+			Ports:     []corev1.EndpointPort{port},
+		}},
+	}
+
+	if got := SubsetEndpoints(revID, eps, 0); got != eps {
+		t.Errorf("SubsetEndpoints(0) = %v, want: the given endpoints", got)
+	}
+
+	got := SubsetEndpoints(revID, eps, 2)
+	var ips []string
+	for _, subset := range got.Subsets {
+		if !cmp.Equal(subset.Ports, []corev1.EndpointPort{port}) {
+			t.Errorf("Ports = %v, want: %v", subset.Ports, []corev1.EndpointPort{port})
+		}
+		if len(subset.NotReadyAddresses) != 0 {
+			t.Errorf("NotReadyAddresses = %v, want none", subset.NotReadyAddresses)
+		}
+		for _, addr := range subset.Addresses {
+			ips = append(ips, addr.IP)
+		}
+	}
+	sort.Strings(ips)
+	want := AssignedActivators(revID, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}, 2)
+	sort.Strings(want)
+	if !cmp.Equal(ips, want) {
+		t.Errorf("SubsetEndpoints(2) has activators %v, want: %v", ips, want)
+	}
+	if len(eps.Subsets[0].Addresses) != 2 || len(eps.Subsets[1].Addresses) != 2 {
+		t.Errorf("SubsetEndpoints() changed the given endpoints to %v", eps)
+	}
+}
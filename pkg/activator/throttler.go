@@ -17,6 +17,7 @@ limitations under the License.
 package activator
 
 import (
+	"context"
 	"errors"
 	"sync"
 
@@ -26,7 +27,10 @@ import (
 	"github.com/knative/pkg/logging/logkey"
 	"github.com/knative/pkg/system"
 	"github.com/knative/serving/pkg/apis/networking"
+	nv1a1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
+	netinformers "github.com/knative/serving/pkg/client/informers/externalversions/networking/v1alpha1"
 	netlisters "github.com/knative/serving/pkg/client/listers/networking/v1alpha1"
 	servinglisters "github.com/knative/serving/pkg/client/listers/serving/v1alpha1"
 	"github.com/knative/serving/pkg/queue"
@@ -39,8 +43,16 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
-// ErrActivatorOverload indicates that throttler has no free slots to buffer the request.
-var ErrActivatorOverload = errors.New("activator overload")
+var (
+	// ErrActivatorOverload indicates that throttler has no free slots to buffer the request.
+	ErrActivatorOverload = errors.New("activator overload")
+	// ErrActivatorQueueTimeout indicates that the request waited in the throttler's queue
+	// for longer than allowed.
+	ErrActivatorQueueTimeout = errors.New("activator queue timeout")
+	// ErrActivatorRequestCanceled indicates that the request was canceled, e.g. the client
+	// went away, while it waited in the throttler's queue.
+	ErrActivatorRequestCanceled = errors.New("activator request canceled")
+)
 
 // Throttler keeps the mapping of Revisions to Breakers
 // and allows updating max concurrency dynamically of respective Breakers.
@@ -48,33 +60,52 @@ var ErrActivatorOverload = errors.New("activator overload")
 // The manipulation of the parameter is done via `UpdateCapacity()` method.
 // It enables the use case to start with max concurrency set to 0 (no requests are sent because no endpoints are available)
 // and gradually increase its value depending on the external condition (e.g. new endpoints become available)
+// The Throttler also tracks the ready pods of the Revisions, to balance the requests across them,
+// and whether the Revisions were probed ready since they last had no ready pods.
+// The capacity of a Revision is shared by the activators it is assigned to, see AssignedActivators.
 type Throttler struct {
 	breakersMux sync.Mutex
 	breakers    map[RevisionID]*queue.Breaker
 
+	podsMux sync.RWMutex
+	pods    map[RevisionID]*podTrackers
+
+	readinessMux sync.Mutex
+	readiness    map[RevisionID]*readiness
+
 	breakerParams   queue.BreakerParams
 	logger          *zap.SugaredLogger
 	endpointsLister corev1listers.EndpointsLister
 	revisionLister  servinglisters.RevisionLister
 	sksLister       netlisters.ServerlessServiceLister
-	numActivators   int
+
+	// podIP identifies this activator among the activators, if known.
+	podIP string
+
+	activatorsMux sync.RWMutex
+	numActivators int
+	activators    []string
 }
 
 // NewThrottler creates a new Throttler.
 func NewThrottler(
 	params queue.BreakerParams,
 	endpointsInformer corev1informers.EndpointsInformer,
-	sksLister netlisters.ServerlessServiceLister,
+	sksInformer netinformers.ServerlessServiceInformer,
 	revisionLister servinglisters.RevisionLister,
+	podIP string,
 	logger *zap.SugaredLogger) *Throttler {
 
 	throttler := &Throttler{
 		breakers:        make(map[RevisionID]*queue.Breaker),
+		pods:            make(map[RevisionID]*podTrackers),
+		readiness:       make(map[RevisionID]*readiness),
 		breakerParams:   params,
 		logger:          logger,
 		endpointsLister: endpointsInformer.Lister(),
 		revisionLister:  revisionLister,
-		sksLister:       sksLister,
+		sksLister:       sksInformer.Lister(),
+		podIP:           podIP,
 	}
 
 	// Update/create the breaker in the throttler when the number of endpoints changes.
@@ -107,14 +138,79 @@ func NewThrottler(
 		},
 	})
 
+	// Update the capacity of the revision when the number of activators it is assigned to changes.
+	sksInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: throttler.sksUpdated,
+	})
+
 	return throttler
 }
 
-// Remove deletes the breaker from the bookkeeping.
+// Remove deletes the breaker, the pods and the readiness from the bookkeeping.
 func (t *Throttler) Remove(rev RevisionID) {
 	t.breakersMux.Lock()
-	defer t.breakersMux.Unlock()
 	delete(t.breakers, rev)
+	t.breakersMux.Unlock()
+
+	t.podsMux.Lock()
+	delete(t.pods, rev)
+	t.podsMux.Unlock()
+
+	t.readinessMux.Lock()
+	defer t.readinessMux.Unlock()
+	delete(t.readiness, rev)
+}
+
+// WaitReady returns whether the revision is ready to serve requests. Once
+// the revision is probed ready, WaitReady returns true right away until the
+// revision has no ready pods again. Until then, a single call at a time runs
+// probe, returning its attempts, while the others wait for its result.
+func (t *Throttler) WaitReady(rev RevisionID, probe func() (bool, int)) (bool, int) {
+	t.readinessMux.Lock()
+	r, ok := t.readiness[rev]
+	if !ok {
+		r = &readiness{}
+		t.readiness[rev] = r
+	}
+	t.readinessMux.Unlock()
+	return r.wait(probe)
+}
+
+// resetReadiness forgets that the revision was probed ready.
+func (t *Throttler) resetReadiness(rev RevisionID) {
+	t.readinessMux.Lock()
+	r, ok := t.readiness[rev]
+	t.readinessMux.Unlock()
+	if ok {
+		r.reset()
+	}
+}
+
+// PickPod returns the host:port of the ready pod of the revision to send a
+// request to, along with a function to call once the request is done. The
+// pod is the one with the fewest requests in flight from this activator,
+// among those with fewer than containerConcurrency, 0 meaning unlimited, and
+// other than the excluded ones. PickPod returns false if no such pod is known.
+func (t *Throttler) PickPod(rev RevisionID, containerConcurrency int, exclude ...string) (string, func(), bool) {
+	t.podsMux.RLock()
+	pods, ok := t.pods[rev]
+	t.podsMux.RUnlock()
+	if !ok {
+		return "", nil, false
+	}
+	return pods.acquire(containerConcurrency, exclude...)
+}
+
+// updatePods tracks the ready pods of the revision in the endpoints.
+func (t *Throttler) updatePods(rev RevisionID, endpoints *corev1.Endpoints) {
+	t.podsMux.Lock()
+	pods, ok := t.pods[rev]
+	if !ok {
+		pods = &podTrackers{}
+		t.pods[rev] = pods
+	}
+	t.podsMux.Unlock()
+	pods.update(readyDests(endpoints))
 }
 
 // UpdateCapacity updates the max concurrency of the Breaker corresponding to a revision.
@@ -123,35 +219,91 @@ func (t *Throttler) UpdateCapacity(rev RevisionID, size int) error {
 	if err != nil {
 		return err
 	}
+	var sks *nv1a1.ServerlessService
+	if t.podIP != "" {
+		// Without the SKS, the revision is assigned to all the activators.
+		sks, _ = t.sksLister.ServerlessServices(rev.Namespace).Get(rev.Name)
+	}
 	breaker, _ := t.getOrCreateBreaker(rev)
-	return t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, minOneOrValue(t.numActivators))
+	return t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, t.activatorCount(rev, sks))
 }
 
 // Try potentially registers a new breaker in our bookkeeping
 // and executes the `function` on the Breaker.
 // It returns an error if either breaker doesn't have enough capacity,
 // or breaker's registration didn't succeed, e.g. getting endpoints or update capacity failed.
-func (t *Throttler) Try(rev RevisionID, function func()) error {
+// At most maxQueued requests wait for capacity, 0 leaving the bound to the breaker, and they
+// wait until the context is done.
+func (t *Throttler) Try(ctx context.Context, rev RevisionID, maxQueued int, function func()) error {
 	breaker, existed := t.getOrCreateBreaker(rev)
-	activatorCount := minOneOrValue(t.numActivators)
 	if !existed {
 		// Need to fetch the latest endpoints state, in case we missed the update.
-		if err := t.forceUpdateCapacity(rev, breaker, activatorCount); err != nil {
+		if err := t.forceUpdateCapacity(rev, breaker); err != nil {
 			return err
 		}
 	}
-	if !breaker.Maybe(function) {
+	switch err := breaker.MaybeContext(ctx, maxQueued, function); err {
+	case nil:
+		return nil
+	case queue.ErrRequestQueueFull:
 		return ErrActivatorOverload
+	case context.DeadlineExceeded:
+		return ErrActivatorQueueTimeout
+	case context.Canceled:
+		return ErrActivatorRequestCanceled
+	default:
+		return err
+	}
+}
+
+// ReportQueueLengths reports the number of requests waiting for capacity for each revision.
+func (t *Throttler) ReportQueueLengths(reporter StatsReporter) {
+	t.breakersMux.Lock()
+	lengths := make(map[RevisionID]int, len(t.breakers))
+	for rev, breaker := range t.breakers {
+		lengths[rev] = breaker.QueueLength()
+	}
+	t.breakersMux.Unlock()
+
+	for rev, length := range lengths {
+		var serviceName, configurationName string
+		if revision, err := t.revisionLister.Revisions(rev.Namespace).Get(rev.Name); err == nil && revision.Labels != nil {
+			configurationName = revision.Labels[serving.ConfigurationLabelKey]
+			serviceName = revision.Labels[serving.ServiceLabelKey]
+		}
+		if err := reporter.ReportQueueLength(rev.Namespace, serviceName, configurationName, rev.Name, int64(length)); err != nil {
+			t.logger.With(zap.String(logkey.Key, rev.String())).Errorw("reporting queue length failed", zap.Error(err))
+		}
 	}
-	return nil
 }
 
 func (t *Throttler) activatorEndpointsUpdated(newObj interface{}) {
 	endpoints := newObj.(*corev1.Endpoints)
+	t.activatorsMux.Lock()
 	t.numActivators = resources.ReadyAddressCount(endpoints)
+	t.activators = sharding.MembersFromEndpoints(endpoints)
+	t.activatorsMux.Unlock()
 	t.updateAllBreakerCapacity()
 }
 
+// activatorCount returns the number of activators sharing the capacity of the
+// revision: the activators it is assigned to if this activator is one of them,
+// all the activators otherwise.
+func (t *Throttler) activatorCount(rev RevisionID, sks *nv1a1.ServerlessService) int {
+	t.activatorsMux.RLock()
+	defer t.activatorsMux.RUnlock()
+	if t.podIP == "" || sks == nil || sks.Spec.NumActivators == 0 {
+		return minOneOrValue(t.numActivators)
+	}
+	assigned := AssignedActivators(rev, t.activators, int(sks.Spec.NumActivators))
+	for _, ip := range assigned {
+		if ip == t.podIP {
+			return minOneOrValue(len(assigned))
+		}
+	}
+	return minOneOrValue(t.numActivators)
+}
+
 // minOneOrValue function returns num if its greater than 1
 // else the function returns 1
 func minOneOrValue(num int) int {
@@ -191,7 +343,7 @@ func (t *Throttler) getOrCreateBreaker(rev RevisionID) (*queue.Breaker, bool) {
 // forceUpdateCapacity fetches the endpoints and updates the capacity of the newly created breaker.
 // This avoids a potential deadlock in case if we missed the updates from the Endpoints informer.
 // This could happen because of a restart of the Activator or when a new one is added as part of scale out.
-func (t *Throttler) forceUpdateCapacity(rev RevisionID, breaker *queue.Breaker, activatorCount int) (err error) {
+func (t *Throttler) forceUpdateCapacity(rev RevisionID, breaker *queue.Breaker) (err error) {
 	revision, err := t.revisionLister.Revisions(rev.Namespace).Get(rev.Name)
 	if err != nil {
 		return err
@@ -206,42 +358,69 @@ func (t *Throttler) forceUpdateCapacity(rev RevisionID, breaker *queue.Breaker,
 	// We have to read the private service endpoints in activator
 	// in order to count the serving pod count, since the public one
 	// may point at ourselves.
-	podCounter := resources.NewScopedEndpointsCounter(t.endpointsLister, sks.Namespace, sks.Status.PrivateServiceName)
-	size, err := podCounter.ReadyCount()
+	endpoints, err := t.endpointsLister.Endpoints(sks.Namespace).Get(sks.Status.PrivateServiceName)
 	if err != nil {
 		return err
 	}
+	t.updatePods(rev, endpoints)
 
-	return t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, activatorCount)
+	return t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), resources.ReadyAddressCount(endpoints), t.activatorCount(rev, sks))
 }
 
 // updateAllBreakerCapacity updates the capacity of all breakers.
 func (t *Throttler) updateAllBreakerCapacity() {
 	t.breakersMux.Lock()
 	defer t.breakersMux.Unlock()
-	activatorCount := minOneOrValue(t.numActivators)
 	for revID, breaker := range t.breakers {
-		if err := t.forceUpdateCapacity(revID, breaker, activatorCount); err != nil {
+		if err := t.forceUpdateCapacity(revID, breaker); err != nil {
 			t.logger.With(zap.String(logkey.Key, revID.String())).Errorw("updating capacity failed", zap.Error(err))
 		}
 	}
 }
 
 // endpointsUpdated is a handler function to be used by the Endpoints informer.
-// It updates the endpoints in the Throttler if the number of hosts changed and
-// the revision already exists (we don't want to create/update throttlers for the endpoints
-// that do not belong to any revision).
+// It tracks the ready pods of the revision, and updates the endpoints in the Throttler if
+// the number of hosts changed and the revision already exists (we don't want to create/update
+// throttlers for the endpoints that do not belong to any revision). The pods are tracked even
+// before the revision is known, so that the requests go straight to them right away.
+// Once the revision has no ready pods, it has to be probed again before serving requests.
 //
 // This function must not be called in parallel to not induce a wrong order of events.
 func (t *Throttler) endpointsUpdated(newObj interface{}) {
 	endpoints := newObj.(*corev1.Endpoints)
 	addresses := resources.ReadyAddressCount(endpoints)
 	revID := RevisionID{endpoints.Namespace, resources.ParentResourceFromService(endpoints.Name)}
+	if addresses == 0 {
+		t.resetReadiness(revID)
+	}
+	t.updatePods(revID, endpoints)
 	if err := t.UpdateCapacity(revID, addresses); err != nil {
 		t.logger.With(zap.String(logkey.Key, revID.String())).Errorw("updating capacity failed", zap.Error(err))
 	}
 }
 
+// sksUpdated is a handler function to be used by the ServerlessService informer.
+// It updates the capacity of the revision, if it already exists, when the number
+// of activators the revision is assigned to changes.
+func (t *Throttler) sksUpdated(oldObj, newObj interface{}) {
+	oldSKS := oldObj.(*nv1a1.ServerlessService)
+	newSKS := newObj.(*nv1a1.ServerlessService)
+	if oldSKS.Spec.NumActivators == newSKS.Spec.NumActivators {
+		return
+	}
+
+	revID := RevisionID{newSKS.Namespace, newSKS.Name}
+	t.breakersMux.Lock()
+	defer t.breakersMux.Unlock()
+	breaker, ok := t.breakers[revID]
+	if !ok {
+		return
+	}
+	if err := t.forceUpdateCapacity(revID, breaker); err != nil {
+		t.logger.With(zap.String(logkey.Key, revID.String())).Errorw("updating capacity failed", zap.Error(err))
+	}
+}
+
 // endpointsDeleted is a handler function to be used by the Endpoints informer.
 // It removes the Breaker from the Throttler bookkeeping.
 func (t *Throttler) endpointsDeleted(obj interface{}) {
@@ -17,9 +17,11 @@ limitations under the License.
 package activator
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"go.uber.org/zap"
@@ -35,7 +37,7 @@ import (
 	"github.com/knative/serving/pkg/apis/serving/v1beta1"
 	servingfake "github.com/knative/serving/pkg/client/clientset/versioned/fake"
 	servinginformers "github.com/knative/serving/pkg/client/informers/externalversions"
-	netlisters "github.com/knative/serving/pkg/client/listers/networking/v1alpha1"
+	netinformers "github.com/knative/serving/pkg/client/informers/externalversions/networking/v1alpha1"
 	servinglisters "github.com/knative/serving/pkg/client/listers/serving/v1alpha1"
 	"github.com/knative/serving/pkg/queue"
 
@@ -111,7 +113,7 @@ func TestThrottlerUpdateCapacity(t *testing.T) {
 				s.maxConcurrency,
 				s.revisionLister,
 				endpointsInformer(testNamespace, testRevision, 1),
-				nil, /*sksLister*/
+				sksInformer(testNamespace, testRevision),
 				TestLogger(t),
 				initCapacity)
 
@@ -179,7 +181,7 @@ func TestThrottlerActivatorEndpoints(t *testing.T) {
 				defaultMaxConcurrency,
 				revisionLister(testNamespace, testRevision, v1beta1.RevisionContainerConcurrencyType(s.revisionConcurrency)),
 				endpoints,
-				sksLister(testNamespace, testRevision),
+				sksInformer(testNamespace, testRevision),
 				TestLogger(t),
 				initCapacity,
 			)
@@ -201,6 +203,129 @@ func TestThrottlerActivatorEndpoints(t *testing.T) {
 	}
 }
 
+func TestThrottlerActivatorCount(t *testing.T) {
+	activators := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}
+	assigned := AssignedActivators(revID, activators, 2)
+	var unassigned string
+	for _, ip := range activators {
+		if ip != assigned[0] && ip != assigned[1] {
+			unassigned = ip
+		}
+	}
+
+	tests := []struct {
+		name          string
+		podIP         string
+		numActivators int32
+		noSKS         bool
+		want          int
+	}{{
+		name:          "unknown pod IP",
+		numActivators: 2,
+		want:          4,
+	}, {
+		name:  "assigned to all the activators",
+		podIP: assigned[0],
+		want:  4,
+	}, {
+		name:          "assigned to this activator",
+		podIP:         assigned[1],
+		numActivators: 2,
+		want:          2,
+	}, {
+		name:          "assigned to other activators",
+		podIP:         unassigned,
+		numActivators: 2,
+		want:          4,
+	}, {
+		name:  "no SKS",
+		podIP: assigned[0],
+		noSKS: true,
+		want:  4,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			throttler := NewThrottler(queue.BreakerParams{QueueDepth: 1, MaxConcurrency: defaultMaxConcurrency},
+				endpointsInformer(testNamespace, testRevision, 1), sksInformer(testNamespace, testRevision),
+				revisionLister(testNamespace, testRevision, 1), test.podIP, TestLogger(t))
+			activatorEp := &corev1.Endpoints{
+				Subsets: []corev1.EndpointSubset{{}},
+			}
+			for _, ip := range activators {
+				activatorEp.Subsets[0].Addresses = append(activatorEp.Subsets[0].Addresses, corev1.EndpointAddress{IP: ip})
+			}
+			throttler.activatorEndpointsUpdated(activatorEp)
+
+			var sks *nv1a1.ServerlessService
+			if !test.noSKS {
+				sks = &nv1a1.ServerlessService{
+					Spec: nv1a1.ServerlessServiceSpec{NumActivators: test.numActivators},
+				}
+			}
+			if got := throttler.activatorCount(revID, sks); got != test.want {
+				t.Errorf("activatorCount() = %d, want: %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestThrottlerSKSUpdated(t *testing.T) {
+	const (
+		updatePollInterval = 10 * time.Millisecond
+		updatePollTimeout  = 3 * time.Second
+	)
+
+	activators := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}
+	assigned := AssignedActivators(revID, activators, 2)
+
+	sks := &nv1a1.ServerlessService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testRevision,
+		},
+		Status: nv1a1.ServerlessServiceStatus{
+			PrivateServiceName: testRevision,
+			ServiceName:        helpers.AppendRandomString(testRevision),
+		},
+	}
+	fake := servingfake.NewSimpleClientset(sks)
+	informer := servinginformers.NewSharedInformerFactory(fake, 0)
+	skss := informer.Networking().V1alpha1().ServerlessServices()
+
+	throttler := NewThrottler(queue.BreakerParams{QueueDepth: 1, MaxConcurrency: defaultMaxConcurrency},
+		endpointsInformer(testNamespace, testRevision, 1), skss,
+		revisionLister(testNamespace, testRevision, 12), assigned[0], TestLogger(t))
+	activatorEp := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{}},
+	}
+	for _, ip := range activators {
+		activatorEp.Subsets[0].Addresses = append(activatorEp.Subsets[0].Addresses, corev1.EndpointAddress{IP: ip})
+	}
+	throttler.activatorEndpointsUpdated(activatorEp)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	controller.StartInformers(stopCh, skss.Informer())
+
+	throttler.UpdateCapacity(revID, 1) // This sets the initial breaker
+	breaker := throttler.breakers[revID]
+	if got, want := breaker.Capacity(), 3; got != want {
+		t.Fatalf("Capacity() = %d, want %d", got, want)
+	}
+
+	// Assign the revision to 2 activators, this one among them.
+	sks = sks.DeepCopy()
+	sks.Spec.NumActivators = 2
+	fake.Networking().ServerlessServices(testNamespace).Update(sks)
+
+	if err := wait.PollImmediate(updatePollInterval, updatePollTimeout, func() (bool, error) {
+		return breaker.Capacity() == 6, nil
+	}); err != nil {
+		t.Errorf("Capacity() = %d, want %d", breaker.Capacity(), 6)
+	}
+}
+
 func TestThrottlerTry(t *testing.T) {
 	defer ClearAll()
 	samples := []struct {
@@ -208,7 +333,7 @@ func TestThrottlerTry(t *testing.T) {
 		addCapacity       bool
 		revisionLister    servinglisters.RevisionLister
 		endpointsInformer corev1informers.EndpointsInformer
-		sksLister         netlisters.ServerlessServiceLister
+		sksInformer       netinformers.ServerlessServiceInformer
 		wantCalls         int
 		wantError         bool
 	}{{
@@ -216,28 +341,28 @@ func TestThrottlerTry(t *testing.T) {
 		addCapacity:       true,
 		revisionLister:    revisionLister(testNamespace, testRevision, 10),
 		endpointsInformer: endpointsInformer(testNamespace, testRevision, 0),
-		sksLister:         sksLister(testNamespace, testRevision),
+		sksInformer:       sksInformer(testNamespace, testRevision),
 		wantCalls:         1,
 	}, {
 		label:             "non-existing revision",
 		addCapacity:       true,
 		revisionLister:    revisionLister("bogus-namespace", testRevision, 10),
 		endpointsInformer: endpointsInformer(testNamespace, testRevision, 0),
-		sksLister:         sksLister(testNamespace, testRevision),
+		sksInformer:       sksInformer(testNamespace, testRevision),
 		wantCalls:         0,
 		wantError:         true,
 	}, {
 		label:             "error getting SKS",
 		revisionLister:    revisionLister(testNamespace, testRevision, 10),
 		endpointsInformer: endpointsInformer(testNamespace, testRevision, 1),
-		sksLister:         sksLister("bogus-namespace", testRevision),
+		sksInformer:       sksInformer("bogus-namespace", testRevision),
 		wantCalls:         0,
 		wantError:         true,
 	}, {
 		label:             "error getting endpoint",
 		revisionLister:    revisionLister(testNamespace, testRevision, 10),
 		endpointsInformer: endpointsInformer("bogus-namespace", testRevision, 0),
-		sksLister:         sksLister(testNamespace, testRevision),
+		sksInformer:       sksInformer(testNamespace, testRevision),
 		wantCalls:         0,
 		wantError:         true,
 	}}
@@ -248,14 +373,14 @@ func TestThrottlerTry(t *testing.T) {
 				defaultMaxConcurrency,
 				s.revisionLister,
 				s.endpointsInformer,
-				s.sksLister,
+				s.sksInformer,
 				TestLogger(t),
 				initCapacity)
 
 			if s.addCapacity {
 				throttler.UpdateCapacity(revID, 1)
 			}
-			err := throttler.Try(revID, func() {
+			err := throttler.Try(context.Background(), revID, 0, func() {
 				called++
 			})
 			if err == nil && s.wantError {
@@ -278,7 +403,7 @@ func TestThrottlerTryOverload(t *testing.T) {
 		maxConcurrency,
 		revisionLister(testNamespace, testRevision, 1),
 		endpointsInformer(testNamespace, testRevision, 1),
-		sksLister(testNamespace, testRevision),
+		sksInformer(testNamespace, testRevision),
 		TestLogger(t),
 		initialCapacity)
 
@@ -289,7 +414,7 @@ func TestThrottlerTryOverload(t *testing.T) {
 	allowedRequests := initialCapacity + queueLength
 	for i := 0; i < allowedRequests+1; i++ {
 		go func() {
-			err := th.Try(revID, func() {
+			err := th.Try(context.Background(), revID, 0, func() {
 				doneCh <- struct{}{} // Blocks forever
 			})
 			if err != nil {
@@ -317,12 +442,62 @@ func TestThrottlerTryOverload(t *testing.T) {
 	}
 }
 
+func TestThrottlerTryQueueLimits(t *testing.T) {
+	th := getThrottler(
+		defaultMaxConcurrency,
+		revisionLister(testNamespace, testRevision, 1),
+		endpointsInformer(testNamespace, testRevision, 0),
+		sksInformer(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+
+	// Without capacity, the first request waits until its deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	errCh := make(chan error)
+	go func() {
+		errCh <- th.Try(ctx, revID, 1, func() {
+			t.Error("The function was called without capacity")
+		})
+	}()
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		th.breakersMux.Lock()
+		defer th.breakersMux.Unlock()
+		breaker, ok := th.breakers[revID]
+		return ok && breaker.QueueLength() == 1, nil
+	}); err != nil {
+		t.Fatal("The first request was never queued")
+	}
+
+	// The second one finds the queue full.
+	if err := th.Try(context.Background(), revID, 1, func() {}); err != ErrActivatorOverload {
+		t.Errorf("Try() = %v, want: %v", err, ErrActivatorOverload)
+	}
+
+	reporter := &fakeReporter{}
+	th.ReportQueueLengths(reporter)
+	if got, want := reporter.queueLengths, map[string]int64{testRevision: 1}; !cmp.Equal(got, want) {
+		t.Errorf("Reported queue lengths = %v, want: %v", got, want)
+	}
+
+	if err := <-errCh; err != ErrActivatorQueueTimeout {
+		t.Errorf("Try() = %v, want: %v", err, ErrActivatorQueueTimeout)
+	}
+
+	// A request whose client went away stops waiting.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := th.Try(ctx, revID, 1, func() {}); err != ErrActivatorRequestCanceled {
+		t.Errorf("Try() = %v, want: %v", err, ErrActivatorRequestCanceled)
+	}
+}
+
 func TestThrottlerRemove(t *testing.T) {
 	throttler := getThrottler(
 		defaultMaxConcurrency,
 		revisionLister(testNamespace, testRevision, 10),
 		endpointsInformer(testNamespace, testRevision, 0),
-		sksLister(testNamespace, testRevision),
+		sksInformer(testNamespace, testRevision),
 		TestLogger(t),
 		initCapacity)
 
@@ -337,6 +512,127 @@ func TestThrottlerRemove(t *testing.T) {
 	}
 }
 
+func TestThrottlerPickPod(t *testing.T) {
+	endpoints := endpointsInformer(testNamespace, testRevision, 0)
+	ep := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testRevision,
+			Namespace: testNamespace,
+		},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "http", Port: 8012}},
+		}},
+	}
+	endpoints.Informer().GetIndexer().Update(ep)
+	throttler := getThrottler(
+		defaultMaxConcurrency,
+		revisionLister(testNamespace, testRevision, 1),
+		endpoints,
+		sksInformer(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+
+	if _, _, ok := throttler.PickPod(revID, 1); ok {
+		t.Error("PickPod() before the revision is known = true, want false")
+	}
+
+	// Trying the revision the first time reads its endpoints.
+	if err := throttler.Try(context.Background(), revID, 0, func() {}); err != nil {
+		t.Fatalf("Try() = %v", err)
+	}
+	dest, release, ok := throttler.PickPod(revID, 1)
+	if !ok || dest != "10.0.0.1:8012" {
+		t.Fatalf("PickPod() = (%q, %v), want: (%q, true)", dest, ok, "10.0.0.1:8012")
+	}
+	if _, _, ok := throttler.PickPod(revID, 1); ok {
+		t.Error("PickPod() with the pod at its container concurrency = true, want false")
+	}
+	release()
+
+	throttler.Remove(revID)
+	if _, _, ok := throttler.PickPod(revID, 1); ok {
+		t.Error("PickPod() after Remove() = true, want false")
+	}
+}
+
+func TestThrottlerPickPodBeforeRevision(t *testing.T) {
+	throttler := getThrottler(
+		defaultMaxConcurrency,
+		revisionLister(testNamespace, "another-revision", 1),
+		endpointsInformer(testNamespace, testRevision, 0),
+		sksInformer(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+
+	// The revision isn't in the lister yet, so the capacity can't be updated,
+	// but the pods are tracked all the same.
+	throttler.endpointsUpdated(&corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testRevision + "-service",
+			Namespace: testNamespace,
+		},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "http", Port: 8012}},
+		}},
+	})
+	dest, release, ok := throttler.PickPod(revID, 1)
+	if !ok || dest != "10.0.0.1:8012" {
+		t.Fatalf("PickPod() = (%q, %v), want: (%q, true)", dest, ok, "10.0.0.1:8012")
+	}
+	release()
+}
+
+func TestThrottlerWaitReady(t *testing.T) {
+	throttler := getThrottler(
+		defaultMaxConcurrency,
+		revisionLister(testNamespace, testRevision, 1),
+		endpointsInformer(testNamespace, testRevision, 1),
+		sksInformer(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+
+	probes := 0
+	probe := func() (bool, int) {
+		probes++
+		return true, 1
+	}
+	throttler.WaitReady(revID, probe)
+	throttler.WaitReady(revID, probe)
+	if probes != 1 {
+		t.Errorf("probes once ready = %d, want: 1", probes)
+	}
+
+	// Pods coming and going keep the revision ready.
+	ep := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testRevision + "-service",
+			Namespace: testNamespace,
+		},
+		Subsets: endpointsSubset(2, 1),
+	}
+	throttler.endpointsUpdated(ep)
+	throttler.WaitReady(revID, probe)
+	if probes != 1 {
+		t.Errorf("probes after scaling up = %d, want: 1", probes)
+	}
+
+	// Scaling to zero requires probing again.
+	ep.Subsets = endpointsSubset(0, 1)
+	throttler.endpointsUpdated(ep)
+	throttler.WaitReady(revID, probe)
+	if probes != 2 {
+		t.Errorf("probes after scaling to zero = %d, want: 2", probes)
+	}
+
+	throttler.Remove(revID)
+	throttler.WaitReady(revID, probe)
+	if probes != 3 {
+		t.Errorf("probes after Remove() = %d, want: 3", probes)
+	}
+}
+
 func TestHelper_ReactToEndpoints(t *testing.T) {
 	const updatePollInterval = 10 * time.Millisecond
 	const updatePollTimeout = 3 * time.Second
@@ -365,7 +661,7 @@ func TestHelper_ReactToEndpoints(t *testing.T) {
 		200,
 		revisionLister(testNamespace, testRevision, 10),
 		endpointsInformer,
-		sksLister(testNamespace, testRevision),
+		sksInformer(testNamespace, testRevision),
 		TestLogger(t),
 		initCapacity)
 
@@ -441,7 +737,7 @@ func endpointsInformer(namespace, name string, count int) corev1informers.Endpoi
 	return endpoints
 }
 
-func sksLister(namespace, name string) netlisters.ServerlessServiceLister {
+func sksInformer(namespace, name string) netinformers.ServerlessServiceInformer {
 	sks := &nv1a1.ServerlessService{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
@@ -458,14 +754,14 @@ func sksLister(namespace, name string) netlisters.ServerlessServiceLister {
 	skss := informer.Networking().V1alpha1().ServerlessServices()
 	skss.Informer().GetIndexer().Add(sks)
 
-	return skss.Lister()
+	return skss
 }
 
 func getThrottler(
 	maxConcurrency int,
 	revisionLister servinglisters.RevisionLister,
 	endpointsInformer corev1informers.EndpointsInformer,
-	sksLister netlisters.ServerlessServiceLister,
+	sksInformer netinformers.ServerlessServiceInformer,
 	logger *zap.SugaredLogger,
 	initCapacity int) *Throttler {
 	params := queue.BreakerParams{
@@ -473,7 +769,27 @@ func getThrottler(
 		MaxConcurrency:  maxConcurrency,
 		InitialCapacity: initCapacity,
 	}
-	return NewThrottler(params, endpointsInformer, sksLister, revisionLister, logger)
+	return NewThrottler(params, endpointsInformer, sksInformer, revisionLister, "", logger)
+}
+
+type fakeReporter struct {
+	queueLengths map[string]int64
+}
+
+func (f *fakeReporter) ReportRequestCount(ns, service, config, rev string, responseCode, numTries int, v int64) error {
+	return nil
+}
+
+func (f *fakeReporter) ReportResponseTime(ns, service, config, rev string, responseCode int, d time.Duration) error {
+	return nil
+}
+
+func (f *fakeReporter) ReportQueueLength(ns, service, config, rev string, v int64) error {
+	if f.queueLengths == nil {
+		f.queueLengths = make(map[string]int64)
+	}
+	f.queueLengths[rev] = v
+	return nil
 }
 
 func breakerCount(t *Throttler) int {
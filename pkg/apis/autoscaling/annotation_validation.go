@@ -17,10 +17,13 @@ limitations under the License.
 package autoscaling
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/knative/pkg/apis"
+	"github.com/knative/serving/pkg/apis/config"
 )
 
 func getIntGE0(m map[string]string, k string) (int64, *apis.FieldError) {
@@ -38,7 +41,64 @@ func getIntGE0(m map[string]string, k string) (int64, *apis.FieldError) {
 	return i, nil
 }
 
-func ValidateAnnotations(annotations map[string]string) *apis.FieldError {
+func getFloatGT0(m map[string]string, k string) (float64, *apis.FieldError) {
+	v, ok := m[k]
+	if !ok {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return 0, &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a number greater than 0", k),
+			Paths:   []string{k},
+		}
+	}
+	return f, nil
+}
+
+func getDurationInRange(m map[string]string, k string, min, max time.Duration) *apis.FieldError {
+	v, ok := m[k]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < min || d > max {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration between %v and %v", k, min, max),
+			Paths:   []string{k},
+		}
+	}
+	return nil
+}
+
+// validateIdleTimeoutWindow checks that the scale to zero idle timeout isn't
+// shorter than the stable window, in which the autoscaler can't tell that
+// there's no traffic.
+func validateIdleTimeoutWindow(m map[string]string, stableWindow time.Duration) *apis.FieldError {
+	v, ok := m[ScaleToZeroIdleTimeoutAnnotationKey]
+	if !ok {
+		return nil
+	}
+	idle, err := time.ParseDuration(v)
+	if err != nil {
+		return nil
+	}
+	if w, err := time.ParseDuration(m[WindowAnnotationKey]); err == nil {
+		stableWindow = w
+	}
+	if idle < stableWindow {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must not be shorter than the stable window %v",
+				ScaleToZeroIdleTimeoutAnnotationKey, stableWindow),
+			Paths: []string{ScaleToZeroIdleTimeoutAnnotationKey},
+		}
+	}
+	return nil
+}
+
+// ValidateAnnotations validates the autoscaling annotations, against the
+// autoscaler configuration in the context.
+func ValidateAnnotations(ctx context.Context, annotations map[string]string) *apis.FieldError {
 	if len(annotations) == 0 {
 		return nil
 	}
@@ -59,5 +119,145 @@ func ValidateAnnotations(annotations map[string]string) *apis.FieldError {
 		}
 	}
 
+	if v, ok := annotations[KeepMinScaleAnnotationKey]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be true or false", KeepMinScaleAnnotationKey),
+				Paths:   []string{KeepMinScaleAnnotationKey},
+			}
+		}
+	}
+
+	if _, err := getIntGE0(annotations, InitialScaleAnnotationKey); err != nil {
+		return err
+	}
+
+	if v, ok := annotations[ScaleScheduleAnnotationKey]; ok {
+		if _, err := ParseScaleSchedule(v); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: %v", ScaleScheduleAnnotationKey, err),
+				Paths:   []string{ScaleScheduleAnnotationKey},
+			}
+		}
+	}
+
+	if v, ok := annotations[FreezeAnnotationKey]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be true or false", FreezeAnnotationKey),
+				Paths:   []string{FreezeAnnotationKey},
+			}
+		}
+	}
+	if _, err := getIntGE0(annotations, FrozenScaleAnnotationKey); err != nil {
+		return err
+	}
+
+	cfg := config.FromContextOrDefaults(ctx).Autoscaler
+	if err := getDurationInRange(annotations, ScaleToZeroIdleTimeoutAnnotationKey,
+		cfg.ScaleToZeroIdleTimeoutMin, cfg.ScaleToZeroIdleTimeoutMax); err != nil {
+		return err
+	}
+	if err := validateIdleTimeoutWindow(annotations, cfg.StableWindow); err != nil {
+		return err
+	}
+	if err := getDurationInRange(annotations, ScaleToZeroGracePeriodAnnotationKey,
+		cfg.ScaleToZeroGracePeriodMin, cfg.ScaleToZeroGracePeriodMax); err != nil {
+		return err
+	}
+
+	if _, err := getFloatGT0(annotations, TargetRPSAnnotationKey); err != nil {
+		return err
+	}
+
+	if v, ok := annotations[TargetBurstCapacityAnnotationKey]; ok {
+		if f, err := strconv.ParseFloat(v, 64); err != nil || f < 0 && f != -1 {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be -1 or a number equal or greater than 0", TargetBurstCapacityAnnotationKey),
+				Paths:   []string{TargetBurstCapacityAnnotationKey},
+			}
+		}
+	}
+
+	if v, ok := annotations[ActivatorMaxQueuedRequestsAnnotationKey]; ok {
+		if i, err := strconv.ParseInt(v, 10, 32); err != nil || i < 1 || i > ActivatorMaxQueuedRequestsMax {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be an integer between 1 and %d", ActivatorMaxQueuedRequestsAnnotationKey, ActivatorMaxQueuedRequestsMax),
+				Paths:   []string{ActivatorMaxQueuedRequestsAnnotationKey},
+			}
+		}
+	}
+	for _, k := range []string{ActivatorMaxQueueWaitAnnotationKey, ActivatorOverloadRetryAfterAnnotationKey} {
+		if v, ok := annotations[k]; ok {
+			if d, err := time.ParseDuration(v); err != nil || d < 0 {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("Invalid %s annotation value: must be a duration equal or greater than 0", k),
+					Paths:   []string{k},
+				}
+			}
+		}
+	}
+	if v, ok := annotations[ActivatorOverloadStatusCodeAnnotationKey]; ok {
+		switch v {
+		case "429", "503":
+		default:
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be one of 429 or 503", ActivatorOverloadStatusCodeAnnotationKey),
+				Paths:   []string{ActivatorOverloadStatusCodeAnnotationKey},
+			}
+		}
+	}
+
+	if v, ok := annotations[TargetLatencyAnnotationKey]; ok {
+		if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be a duration greater than 0", TargetLatencyAnnotationKey),
+				Paths:   []string{TargetLatencyAnnotationKey},
+			}
+		}
+	}
+
+	if v, ok := annotations[TargetLatencyPercentileAnnotationKey]; ok {
+		switch v {
+		case "50", "95", "99":
+		default:
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be one of 50, 95 or 99", TargetLatencyPercentileAnnotationKey),
+				Paths:   []string{TargetLatencyPercentileAnnotationKey},
+			}
+		}
+	}
+
+	if v, ok := annotations[MaxScaleDownRateAnnotationKey]; ok {
+		if f, err := strconv.ParseFloat(v, 64); err != nil || f <= MaxScaleDownRateMin {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: must be a number greater than %v", MaxScaleDownRateAnnotationKey, MaxScaleDownRateMin),
+				Paths:   []string{MaxScaleDownRateAnnotationKey},
+			}
+		}
+	}
+
+	if v, ok := annotations[AlgorithmAnnotationKey]; ok {
+		switch v {
+		case StablePanicAlgorithm, EWMAAlgorithm, PIDAlgorithm:
+		default:
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: unknown algorithm %q", AlgorithmAnnotationKey, v),
+				Paths:   []string{AlgorithmAnnotationKey},
+			}
+		}
+	}
+
+	if v, ok := annotations[AggregationAnnotationKey]; ok {
+		switch v {
+		case AverageAggregation, EWMAAggregation, LinearDecayAggregation:
+		default:
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation value: unknown aggregation %q", AggregationAnnotationKey, v),
+				Paths:   []string{AggregationAnnotationKey},
+			}
+		}
+	}
+
 	return nil
 }
@@ -17,11 +17,14 @@ limitations under the License.
 package autoscaling
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/knative/pkg/apis"
+	"github.com/knative/serving/pkg/apis/config"
 )
 
 func TestValidateScaleBoundAnnotations(t *testing.T) {
@@ -99,11 +102,290 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 			MaxScaleAnnotationKey: "0",
 		},
 		expectErr: nil,
+	}, {
+		name:        "targetRPS is 150",
+		annotations: map[string]string{TargetRPSAnnotationKey: "150"},
+		expectErr:   nil,
+	}, {
+		name:        "targetRPS is 0.5",
+		annotations: map[string]string{TargetRPSAnnotationKey: "0.5"},
+		expectErr:   nil,
+	}, {
+		name:        "targetRPS is 0",
+		annotations: map[string]string{TargetRPSAnnotationKey: "0"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a number greater than 0", TargetRPSAnnotationKey),
+			Paths:   []string{TargetRPSAnnotationKey},
+		},
+	}, {
+		name:        "maxScaleDownRate is 2",
+		annotations: map[string]string{MaxScaleDownRateAnnotationKey: "2"},
+		expectErr:   nil,
+	}, {
+		name:        "maxScaleDownRate is 1",
+		annotations: map[string]string{MaxScaleDownRateAnnotationKey: "1"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a number greater than 1", MaxScaleDownRateAnnotationKey),
+			Paths:   []string{MaxScaleDownRateAnnotationKey},
+		},
+	}, {
+		name:        "maxScaleDownRate is qux",
+		annotations: map[string]string{MaxScaleDownRateAnnotationKey: "qux"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a number greater than 1", MaxScaleDownRateAnnotationKey),
+			Paths:   []string{MaxScaleDownRateAnnotationKey},
+		},
+	}, {
+		name:        "targetRPS is baz",
+		annotations: map[string]string{TargetRPSAnnotationKey: "baz"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a number greater than 0", TargetRPSAnnotationKey),
+			Paths:   []string{TargetRPSAnnotationKey},
+		},
+	}, {
+		name:        "algorithm is ewma",
+		annotations: map[string]string{AlgorithmAnnotationKey: EWMAAlgorithm},
+		expectErr:   nil,
+	}, {
+		name:        "algorithm is pid",
+		annotations: map[string]string{AlgorithmAnnotationKey: PIDAlgorithm},
+		expectErr:   nil,
+	}, {
+		name:        "algorithm is unknown",
+		annotations: map[string]string{AlgorithmAnnotationKey: "magic"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: unknown algorithm %q", AlgorithmAnnotationKey, "magic"),
+			Paths:   []string{AlgorithmAnnotationKey},
+		},
+	}, {
+		name:        "aggregation is linear-decay",
+		annotations: map[string]string{AggregationAnnotationKey: LinearDecayAggregation},
+		expectErr:   nil,
+	}, {
+		name:        "aggregation is unknown",
+		annotations: map[string]string{AggregationAnnotationKey: "median"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: unknown aggregation %q", AggregationAnnotationKey, "median"),
+			Paths:   []string{AggregationAnnotationKey},
+		},
+	}, {
+		name: "target latency",
+		annotations: map[string]string{
+			TargetLatencyAnnotationKey:           "300ms",
+			TargetLatencyPercentileAnnotationKey: "99",
+		},
+		expectErr: nil,
+	}, {
+		name:        "target latency is 0",
+		annotations: map[string]string{TargetLatencyAnnotationKey: "0s"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration greater than 0", TargetLatencyAnnotationKey),
+			Paths:   []string{TargetLatencyAnnotationKey},
+		},
+	}, {
+		name:        "target latency percentile is unsupported",
+		annotations: map[string]string{TargetLatencyPercentileAnnotationKey: "90"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be one of 50, 95 or 99", TargetLatencyPercentileAnnotationKey),
+			Paths:   []string{TargetLatencyPercentileAnnotationKey},
+		},
+	}, {
+		name:        "scale schedule is valid",
+		annotations: map[string]string{ScaleScheduleAnnotationKey: "minScale=10 on weekdays 08:00-18:00 UTC"},
+		expectErr:   nil,
+	}, {
+		name:        "scale schedule is invalid",
+		annotations: map[string]string{ScaleScheduleAnnotationKey: "minScale=10 on someday 08:00-18:00"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: invalid entry %q: unknown day %q",
+				ScaleScheduleAnnotationKey, "minScale=10 on someday 08:00-18:00", "someday"),
+			Paths: []string{ScaleScheduleAnnotationKey},
+		},
+	}, {
+		name:        "freeze with a frozen scale",
+		annotations: map[string]string{FreezeAnnotationKey: "true", FrozenScaleAnnotationKey: "0"},
+		expectErr:   nil,
+	}, {
+		name:        "freeze is not a bool",
+		annotations: map[string]string{FreezeAnnotationKey: "please"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be true or false", FreezeAnnotationKey),
+			Paths:   []string{FreezeAnnotationKey},
+		},
+	}, {
+		name:        "frozen scale is negative",
+		annotations: map[string]string{FreezeAnnotationKey: "true", FrozenScaleAnnotationKey: "-1"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer equal or greater than 0", FrozenScaleAnnotationKey),
+			Paths:   []string{FrozenScaleAnnotationKey},
+		},
+	}, {
+		name:        "keep min scale",
+		annotations: map[string]string{MinScaleAnnotationKey: "3", KeepMinScaleAnnotationKey: "true"},
+		expectErr:   nil,
+	}, {
+		name:        "keep min scale is not a bool",
+		annotations: map[string]string{KeepMinScaleAnnotationKey: "always"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be true or false", KeepMinScaleAnnotationKey),
+			Paths:   []string{KeepMinScaleAnnotationKey},
+		},
+	}, {
+		name: "scale to zero idle timeout and grace period",
+		annotations: map[string]string{
+			ScaleToZeroIdleTimeoutAnnotationKey: "15m",
+			ScaleToZeroGracePeriodAnnotationKey: "45s",
+		},
+		expectErr: nil,
+	}, {
+		name:        "scale to zero idle timeout too large",
+		annotations: map[string]string{ScaleToZeroIdleTimeoutAnnotationKey: "2h"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration between 0s and 1h0m0s", ScaleToZeroIdleTimeoutAnnotationKey),
+			Paths:   []string{ScaleToZeroIdleTimeoutAnnotationKey},
+		},
+	}, {
+		name:        "scale to zero idle timeout shorter than the stable window",
+		annotations: map[string]string{ScaleToZeroIdleTimeoutAnnotationKey: "30s"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must not be shorter than the stable window 1m0s", ScaleToZeroIdleTimeoutAnnotationKey),
+			Paths:   []string{ScaleToZeroIdleTimeoutAnnotationKey},
+		},
+	}, {
+		name: "scale to zero idle timeout shorter than the window annotation",
+		annotations: map[string]string{
+			WindowAnnotationKey:                 "2m",
+			ScaleToZeroIdleTimeoutAnnotationKey: "90s",
+		},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must not be shorter than the stable window 2m0s", ScaleToZeroIdleTimeoutAnnotationKey),
+			Paths:   []string{ScaleToZeroIdleTimeoutAnnotationKey},
+		},
+	}, {
+		name: "scale to zero idle timeout as long as the window annotation",
+		annotations: map[string]string{
+			WindowAnnotationKey:                 "10s",
+			ScaleToZeroIdleTimeoutAnnotationKey: "10s",
+		},
+		expectErr: nil,
+	}, {
+		name:        "scale to zero grace period too large",
+		annotations: map[string]string{ScaleToZeroGracePeriodAnnotationKey: "2h"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration between 30s and 1h0m0s", ScaleToZeroGracePeriodAnnotationKey),
+			Paths:   []string{ScaleToZeroGracePeriodAnnotationKey},
+		},
+	}, {
+		name:        "scale to zero grace period is not a duration",
+		annotations: map[string]string{ScaleToZeroGracePeriodAnnotationKey: "soon"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration between 30s and 1h0m0s", ScaleToZeroGracePeriodAnnotationKey),
+			Paths:   []string{ScaleToZeroGracePeriodAnnotationKey},
+		},
+	}, {
+		name:        "target burst capacity always in path",
+		annotations: map[string]string{TargetBurstCapacityAnnotationKey: "-1"},
+		expectErr:   nil,
+	}, {
+		name:        "target burst capacity is negative",
+		annotations: map[string]string{TargetBurstCapacityAnnotationKey: "-10"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be -1 or a number equal or greater than 0", TargetBurstCapacityAnnotationKey),
+			Paths:   []string{TargetBurstCapacityAnnotationKey},
+		},
+	}, {
+		name:        "initial scale is zero",
+		annotations: map[string]string{InitialScaleAnnotationKey: "0"},
+		expectErr:   nil,
+	}, {
+		name:        "initial scale is not an integer",
+		annotations: map[string]string{InitialScaleAnnotationKey: "many"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer equal or greater than 0", InitialScaleAnnotationKey),
+			Paths:   []string{InitialScaleAnnotationKey},
+		},
+	}, {
+		name: "activator queueing",
+		annotations: map[string]string{
+			ActivatorMaxQueuedRequestsAnnotationKey:  "100",
+			ActivatorMaxQueueWaitAnnotationKey:       "10s",
+			ActivatorOverloadStatusCodeAnnotationKey: "429",
+			ActivatorOverloadRetryAfterAnnotationKey: "0s",
+		},
+		expectErr: nil,
+	}, {
+		name:        "activator max queued requests is 0",
+		annotations: map[string]string{ActivatorMaxQueuedRequestsAnnotationKey: "0"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer between 1 and 10000", ActivatorMaxQueuedRequestsAnnotationKey),
+			Paths:   []string{ActivatorMaxQueuedRequestsAnnotationKey},
+		},
+	}, {
+		name:        "activator max queue wait is negative",
+		annotations: map[string]string{ActivatorMaxQueueWaitAnnotationKey: "-1s"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration equal or greater than 0", ActivatorMaxQueueWaitAnnotationKey),
+			Paths:   []string{ActivatorMaxQueueWaitAnnotationKey},
+		},
+	}, {
+		name:        "activator overload status code is unsupported",
+		annotations: map[string]string{ActivatorOverloadStatusCodeAnnotationKey: "500"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be one of 429 or 503", ActivatorOverloadStatusCodeAnnotationKey),
+			Paths:   []string{ActivatorOverloadStatusCodeAnnotationKey},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateAnnotations(context.Background(), c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateScaleToZeroAnnotationsWithConfig(t *testing.T) {
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Autoscaler = &config.Autoscaler{
+		StableWindow:              30 * time.Second,
+		ScaleToZeroIdleTimeoutMin: time.Minute,
+		ScaleToZeroIdleTimeoutMax: 20 * time.Minute,
+		ScaleToZeroGracePeriodMin: time.Minute,
+		ScaleToZeroGracePeriodMax: 2 * time.Minute,
+	}
+	ctx := config.ToContext(context.Background(), cfg)
+
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name: "within the cluster bounds",
+		annotations: map[string]string{
+			ScaleToZeroIdleTimeoutAnnotationKey: "15m",
+			ScaleToZeroGracePeriodAnnotationKey: "90s",
+		},
+	}, {
+		name:        "idle timeout below the cluster minimum",
+		annotations: map[string]string{ScaleToZeroIdleTimeoutAnnotationKey: "45s"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration between 1m0s and 20m0s", ScaleToZeroIdleTimeoutAnnotationKey),
+			Paths:   []string{ScaleToZeroIdleTimeoutAnnotationKey},
+		},
+	}, {
+		name:        "grace period above the cluster maximum",
+		annotations: map[string]string{ScaleToZeroGracePeriodAnnotationKey: "3m"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a duration between 1m0s and 2m0s", ScaleToZeroGracePeriodAnnotationKey),
+			Paths:   []string{ScaleToZeroGracePeriodAnnotationKey},
+		},
 	}}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			err := ValidateAnnotations(c.annotations)
+			err := ValidateAnnotations(ctx, c.annotations)
 			if !reflect.DeepEqual(c.expectErr, err) {
 				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
 			}
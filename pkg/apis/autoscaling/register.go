@@ -41,6 +41,69 @@ const (
 	// the PodAutoscaler should provision. For example,
 	//   autoscaling.knative.dev/maxScale: "10"
 	MaxScaleAnnotationKey = GroupName + "/maxScale"
+	// KeepMinScaleAnnotationKey is the annotation to keep the minimum number
+	// of Pods of a Revision that no Route refers to. Otherwise the
+	// PodAutoscaler ignores minScale for such a Revision and scales it to
+	// zero once it's idle. For example,
+	//   autoscaling.knative.dev/minScale: "3"
+	//   autoscaling.knative.dev/keepMinScale: "true"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the keepMinScale annotation.
+	KeepMinScaleAnnotationKey = GroupName + "/keepMinScale"
+	// ScaleScheduleAnnotationKey is the annotation to specify windows of time
+	// recurring weekly during which the minimum and maximum number of Pods
+	// differ from the minScale and maxScale annotations. Entries are separated
+	// by semicolons or newlines, later entries take precedence. For example,
+	//   autoscaling.knative.dev/scaleSchedule: "minScale=10 on weekdays 08:00-18:00 UTC"
+	ScaleScheduleAnnotationKey = GroupName + "/scaleSchedule"
+	// InitialScaleAnnotationKey is the annotation to specify the number of
+	// Pods a new Revision starts with, until the PodAutoscaler first reaches
+	// it. Defaults to initial-scale in config-autoscaler, and it can only be
+	// 0 if allow-zero-initial-scale is enabled there. For example,
+	//   autoscaling.knative.dev/initialScale: "5"
+	InitialScaleAnnotationKey = GroupName + "/initialScale"
+
+	// FreezeAnnotationKey is the annotation to stop the PodAutoscaler from
+	// changing the number of Pods, while it keeps collecting metrics and
+	// making recommendations. Set on a Revision, it's propagated to its
+	// PodAutoscaler. For example,
+	//   autoscaling.knative.dev/freeze: "true"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the freeze annotation.
+	FreezeAnnotationKey = GroupName + "/freeze"
+	// FrozenScaleAnnotationKey is the annotation to specify the number of
+	// Pods to pin a frozen PodAutoscaler to. Without it the number of Pods
+	// stays what it was when the freeze began. For example,
+	//   autoscaling.knative.dev/freeze: "true"
+	//   autoscaling.knative.dev/frozenScale: "5"
+	FrozenScaleAnnotationKey = GroupName + "/frozenScale"
+	// FreezePropagatedAnnotationKey is the annotation listing the freeze
+	// annotations of a PodAutoscaler that were propagated from its Revision.
+	// Only those are removed from the PodAutoscaler with the Revision's,
+	// a freeze set on the PodAutoscaler directly is left alone.
+	FreezePropagatedAnnotationKey = InternalGroupName + "/freezePropagated"
+
+	// ScaleToZeroIdleTimeoutAnnotationKey is the annotation to specify how
+	// long a PodAutoscaler stays active without traffic before it's marked
+	// inactive on the way to zero. Defaults to the stable window. It must be
+	// within scale-to-zero-idle-timeout-min and scale-to-zero-idle-timeout-max
+	// in config-autoscaler, and not shorter than the stable window, as the
+	// autoscaler can't tell there's no traffic any sooner. For example,
+	//   autoscaling.knative.dev/scaleToZeroIdleTimeout: "15m"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the scaleToZeroIdleTimeout annotation.
+	ScaleToZeroIdleTimeoutAnnotationKey = GroupName + "/scaleToZeroIdleTimeout"
+
+	// ScaleToZeroGracePeriodAnnotationKey is the annotation to specify how
+	// long an inactive PodAutoscaler keeps its last Pod before it scales to
+	// zero, giving the activator time to get on the request path. Defaults
+	// to scale-to-zero-grace-period in config-autoscaler. It must be within
+	// scale-to-zero-grace-period-min and scale-to-zero-grace-period-max in
+	// config-autoscaler. For example,
+	//   autoscaling.knative.dev/scaleToZeroGracePeriod: "45s"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the scaleToZeroGracePeriod annotation.
+	ScaleToZeroGracePeriodAnnotationKey = GroupName + "/scaleToZeroGracePeriod"
 
 	// MetricAnnotationKey is the annotation to specify what metric the PodAutoscaler
 	// should be scaled on. For example,
@@ -50,6 +113,8 @@ const (
 	Concurrency = "concurrency"
 	// CPU is the amount of the requested cpu actually being consumed by the Pod.
 	CPU = "cpu"
+	// RPS is the number of requests per second reaching the Pod.
+	RPS = "rps"
 
 	// TargetAnnotationKey is the annotation to specify what metric value the
 	// PodAutoscaler should attempt to maintain. For example,
@@ -60,6 +125,32 @@ const (
 	// zero don't make sense.
 	TargetMin = 1
 
+	// TargetRPSAnnotationKey is the annotation to specify the number of
+	// requests per second each Pod should receive when the PodAutoscaler
+	// scales on the rps metric. For example,
+	//   autoscaling.knative.dev/metric: rps
+	//   autoscaling.knative.dev/targetRPS: "150"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the targetRPS annotation.
+	TargetRPSAnnotationKey = GroupName + "/targetRPS"
+
+	// TargetLatencyAnnotationKey is the annotation to specify the request
+	// latency the PodAutoscaler should attempt to maintain at the
+	// TargetLatencyPercentileAnnotationKey percentile by tuning the target
+	// concurrency. For example,
+	//   autoscaling.knative.dev/targetLatency: "300ms"
+	//   autoscaling.knative.dev/targetLatencyPercentile: "95"
+	// Only the kpa.autoscaling.knative.dev class autoscaler scaling on the
+	// concurrency metric supports the targetLatency annotation.
+	TargetLatencyAnnotationKey = GroupName + "/targetLatency"
+	// TargetLatencyPercentileAnnotationKey is the annotation to specify the
+	// percentile of the request latency, one of 50, 95 or 99, the
+	// TargetLatencyAnnotationKey applies to. Defaults to
+	// TargetLatencyPercentileDefault.
+	TargetLatencyPercentileAnnotationKey = GroupName + "/targetLatencyPercentile"
+	// TargetLatencyPercentileDefault is the default latency percentile.
+	TargetLatencyPercentileDefault = 95
+
 	// WindowAnnotationKey is the annotation to specify the time
 	// interval over which to calculate the average metric.  Larger
 	// values result in more smoothing. For example,
@@ -129,6 +220,93 @@ const (
 	// smallest useful value.
 	PanicThresholdPercentageMin = 110.0
 
+	// MaxScaleDownRateAnnotationKey is the annotation to specify the
+	// maximum ratio of observed pods versus desired pods the autoscaler
+	// may remove in a single decision. For example,
+	//   autoscaling.knative.dev/maxScaleDownRate: "2.0"
+	// means the autoscaler will at most halve the number of pods on
+	// each tick. Only the kpa.autoscaling.knative.dev class autoscaler
+	// supports the maxScaleDownRate annotation.
+	MaxScaleDownRateAnnotationKey = GroupName + "/maxScaleDownRate"
+	// MaxScaleDownRateMin is the exclusive lower bound of the max scale
+	// down rate. A rate of 1 or less would never allow the autoscaler
+	// to remove a pod.
+	MaxScaleDownRateMin = 1.0
+
+	// AlgorithmAnnotationKey is the annotation to specify which decider
+	// algorithm the autoscaler should use to compute the desired number of
+	// Pods from the observed metric. For example,
+	//   autoscaling.knative.dev/algorithm: ewma
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the algorithm annotation.
+	AlgorithmAnnotationKey = GroupName + "/algorithm"
+	// StablePanicAlgorithm is the default decider algorithm. It averages
+	// the metric over a stable and a panic window and never scales down
+	// while panicking.
+	StablePanicAlgorithm = "stable-panic"
+	// EWMAAlgorithm scales on an exponentially weighted moving average of
+	// the metric observed over the panic window.
+	EWMAAlgorithm = "ewma"
+	// PIDAlgorithm scales with a proportional-integral-derivative
+	// controller on the relative error between observed and target load.
+	PIDAlgorithm = "pid"
+
+	// AggregationAnnotationKey is the annotation to specify how the
+	// autoscaler aggregates the metric observed within the stable and the
+	// panic window. For example,
+	//   autoscaling.knative.dev/aggregation: linear-decay
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the aggregation annotation.
+	AggregationAnnotationKey = GroupName + "/aggregation"
+	// AverageAggregation weighs all data in the window equally.
+	AverageAggregation = "average"
+	// EWMAAggregation weighs data with an exponential decay by its age.
+	EWMAAggregation = "ewma"
+	// LinearDecayAggregation weighs data with a linear decay by its age,
+	// down to zero at the far end of the window.
+	LinearDecayAggregation = "linear-decay"
+
+	// TargetBurstCapacityAnnotationKey is the annotation to specify the
+	// spare capacity, the number of requests the ready Pods can take on top
+	// of the observed load, below which the activator stays in the request
+	// path to buffer bursts. 0 disables it and -1 keeps the activator in the
+	// request path always. For example,
+	//   autoscaling.knative.dev/targetBurstCapacity: "200"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the targetBurstCapacity annotation.
+	TargetBurstCapacityAnnotationKey = GroupName + "/targetBurstCapacity"
+
+	// ActivatorMaxQueuedRequestsAnnotationKey is the annotation to specify
+	// the number of requests each activator queues for the revision while
+	// it has no capacity for them, before it rejects the next ones. Defaults
+	// to max-queued-requests in config-activator. For example,
+	//   autoscaling.knative.dev/activatorMaxQueuedRequests: "100"
+	ActivatorMaxQueuedRequestsAnnotationKey = GroupName + "/activatorMaxQueuedRequests"
+	// ActivatorMaxQueuedRequestsMax is the maximum allowable number of
+	// queued requests.
+	ActivatorMaxQueuedRequestsMax = 10000
+
+	// ActivatorMaxQueueWaitAnnotationKey is the annotation to specify how
+	// long a request waits in the activator's queue for capacity before it's
+	// rejected. 0 waits for as long as the client does. Defaults to
+	// max-queue-wait in config-activator. For example,
+	//   autoscaling.knative.dev/activatorMaxQueueWait: "10s"
+	ActivatorMaxQueueWaitAnnotationKey = GroupName + "/activatorMaxQueueWait"
+
+	// ActivatorOverloadStatusCodeAnnotationKey is the annotation to specify
+	// the status code, 429 or 503, of the responses to the requests the
+	// activator rejects because the queue is full or they waited too long.
+	// Defaults to overload-status-code in config-activator. For example,
+	//   autoscaling.knative.dev/activatorOverloadStatusCode: "429"
+	ActivatorOverloadStatusCodeAnnotationKey = GroupName + "/activatorOverloadStatusCode"
+
+	// ActivatorOverloadRetryAfterAnnotationKey is the annotation to specify
+	// the Retry-After header, rounded up to seconds, of the responses to the
+	// requests the activator rejects. 0 leaves the header out. Defaults to
+	// overload-retry-after in config-activator. For example,
+	//   autoscaling.knative.dev/activatorOverloadRetryAfter: "5s"
+	ActivatorOverloadRetryAfterAnnotationKey = GroupName + "/activatorOverloadRetryAfter"
+
 	// KPALabelKey is the label key attached to a K8s Service to hint to the KPA
 	// which services/endpoints should trigger reconciles.
 	KPALabelKey = GroupName + "/kpa"
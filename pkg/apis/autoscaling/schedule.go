@@ -0,0 +1,299 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// weekdayNames maps the accepted names of the days of the week to them.
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ScaleSchedule is a list of entries overriding the scale bounds during
+// recurring windows of time. Later entries take precedence over earlier
+// ones when their windows overlap.
+type ScaleSchedule []ScaleScheduleEntry
+
+// ScaleScheduleEntry overrides the scale bounds during a window of time
+// recurring on some days of the week, e.g.
+//
+//	minScale=10 on weekdays 08:00-18:00 UTC
+type ScaleScheduleEntry struct {
+	// MinScale and MaxScale are the bounds during the window, or nil if
+	// the entry does not override them.
+	MinScale *int32
+	MaxScale *int32
+
+	// Days are the days of the week the window starts on.
+	Days [7]bool
+	// Start and End are the minutes since midnight the window starts and
+	// ends at. An End not after the Start ends the window on the next day.
+	Start, End int
+	// Location is the time zone of the window.
+	Location *time.Location
+
+	// Text is the entry as written in the schedule.
+	Text string
+}
+
+// ParseScaleSchedule parses the entries of a schedule separated by
+// semicolons or newlines. Each entry has the form
+//
+//	<bounds> on <days> <start>-<end> [<time zone>]
+//
+// where
+//   - bounds are minScale=<n> and/or maxScale=<n>, separated by a comma,
+//   - days are daily, weekdays, weekends or a comma separated list of days
+//     and ranges of days like mon-fri,sun,
+//   - start and end are times of the day like 08:00 and 18:00, where an
+//     end before the start lasts into the next day,
+//   - the time zone is an IANA time zone name, UTC by default.
+func ParseScaleSchedule(schedule string) (ScaleSchedule, error) {
+	var entries ScaleSchedule
+	for _, text := range strings.FieldsFunc(schedule, func(r rune) bool { return r == ';' || r == '\n' }) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		entry, err := parseScaleScheduleEntry(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %q: %v", text, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseScaleScheduleEntry(text string) (ScaleScheduleEntry, error) {
+	entry := ScaleScheduleEntry{Text: text, Location: time.UTC}
+
+	// Allow spaces and en dashes around the time range.
+	normalized := strings.Replace(text, "–", "-", -1)
+	normalized = strings.Replace(normalized, " - ", "-", -1)
+	fields := strings.Fields(normalized)
+	if len(fields) < 4 || len(fields) > 5 || fields[1] != "on" {
+		return entry, fmt.Errorf("want <bounds> on <days> <start>-<end> [<time zone>]")
+	}
+
+	if err := entry.parseBounds(fields[0]); err != nil {
+		return entry, err
+	}
+	if err := entry.parseDays(fields[2]); err != nil {
+		return entry, err
+	}
+	if err := entry.parseWindow(fields[3]); err != nil {
+		return entry, err
+	}
+	if len(fields) == 5 {
+		loc, err := time.LoadLocation(fields[4])
+		if err != nil {
+			return entry, fmt.Errorf("unknown time zone %q", fields[4])
+		}
+		entry.Location = loc
+	}
+	return entry, nil
+}
+
+func (e *ScaleScheduleEntry) parseBounds(s string) error {
+	for _, bound := range strings.Split(s, ",") {
+		parts := strings.SplitN(bound, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid bound %q, want minScale=<n> or maxScale=<n>", bound)
+		}
+		i, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil || i < 0 {
+			return fmt.Errorf("invalid bound %q, must be an integer equal or greater than 0", bound)
+		}
+		v := int32(i)
+		switch parts[0] {
+		case "minScale":
+			e.MinScale = &v
+		case "maxScale":
+			e.MaxScale = &v
+		default:
+			return fmt.Errorf("invalid bound %q, want minScale=<n> or maxScale=<n>", bound)
+		}
+	}
+	if e.MinScale != nil && e.MaxScale != nil && *e.MaxScale != 0 && *e.MaxScale < *e.MinScale {
+		return fmt.Errorf("maxScale=%d is less than minScale=%d", *e.MaxScale, *e.MinScale)
+	}
+	return nil
+}
+
+func (e *ScaleScheduleEntry) parseDays(s string) error {
+	switch strings.ToLower(s) {
+	case "daily":
+		s = "sun-sat"
+	case "weekdays":
+		s = "mon-fri"
+	case "weekends":
+		s = "sat,sun"
+	}
+	for _, days := range strings.Split(strings.ToLower(s), ",") {
+		parts := strings.SplitN(days, "-", 2)
+		first, ok := weekdayNames[parts[0]]
+		if !ok {
+			return fmt.Errorf("unknown day %q", parts[0])
+		}
+		last := first
+		if len(parts) == 2 {
+			if last, ok = weekdayNames[parts[1]]; !ok {
+				return fmt.Errorf("unknown day %q", parts[1])
+			}
+		}
+		// Ranges may wrap around the end of the week, e.g. fri-mon.
+		for d := first; ; d = (d + 1) % 7 {
+			e.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (e *ScaleScheduleEntry) parseWindow(s string) error {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid window %q, want <start>-<end>", s)
+	}
+	var err error
+	if e.Start, err = parseTimeOfDay(parts[0]); err != nil {
+		return err
+	}
+	if e.End, err = parseTimeOfDay(parts[1]); err != nil {
+		return err
+	}
+	if e.Start == e.End || (e.Start == minutesPerDay) {
+		return fmt.Errorf("invalid window %q, must not be empty", s)
+	}
+	return nil
+}
+
+// parseTimeOfDay parses HH:MM into minutes since midnight. 24:00 is allowed
+// to end a window at midnight.
+func parseTimeOfDay(s string) (int, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) == 2 {
+		h, herr := strconv.Atoi(parts[0])
+		m, merr := strconv.Atoi(parts[1])
+		if herr == nil && merr == nil && len(parts[1]) == 2 && h >= 0 && m >= 0 && m < 60 &&
+			(h < 24 || (h == 24 && m == 0)) {
+			return h*60 + m, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time of day %q, want HH:MM", s)
+}
+
+// window returns the window starting on the given day.
+func (e *ScaleScheduleEntry) window(year int, month time.Month, day int) (start, end time.Time) {
+	endMinute := e.End
+	if endMinute <= e.Start {
+		endMinute += minutesPerDay
+	}
+	return time.Date(year, month, day, 0, e.Start, 0, 0, e.Location),
+		time.Date(year, month, day, 0, endMinute, 0, 0, e.Location)
+}
+
+// IsActive returns whether the given time falls into a window of the entry.
+func (e *ScaleScheduleEntry) IsActive(now time.Time) bool {
+	local := now.In(e.Location)
+	// A window active now started either today or yesterday.
+	for offset := -1; offset <= 0; offset++ {
+		year, month, day := local.AddDate(0, 0, offset).Date()
+		start, end := e.window(year, month, day)
+		if e.Days[start.Weekday()] && !now.Before(start) && now.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextChange returns the first start or end of a window after the given
+// time.
+func (e *ScaleScheduleEntry) nextChange(now time.Time) time.Time {
+	local := now.In(e.Location)
+	var next time.Time
+	// Windows are at most a day long, so some start or end falls into
+	// the next week.
+	for offset := -1; offset <= 7; offset++ {
+		year, month, day := local.AddDate(0, 0, offset).Date()
+		start, end := e.window(year, month, day)
+		if !e.Days[start.Weekday()] {
+			continue
+		}
+		for _, t := range []time.Time{start, end} {
+			if t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+	}
+	return next
+}
+
+// Active returns the entries whose windows the given time falls into.
+func (s ScaleSchedule) Active(now time.Time) ScaleSchedule {
+	var active ScaleSchedule
+	for i := range s {
+		if s[i].IsActive(now) {
+			active = append(active, s[i])
+		}
+	}
+	return active
+}
+
+// NextChange returns the first time after the given time any window of the
+// schedule starts or ends, or false if the schedule is empty.
+func (s ScaleSchedule) NextChange(now time.Time) (time.Time, bool) {
+	var next time.Time
+	for i := range s {
+		if t := s[i].nextChange(now); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// ScaleBounds applies the active entries to the given bounds. A minimum
+// greater than the maximum raises the maximum.
+func (s ScaleSchedule) ScaleBounds(now time.Time, min, max int32) (int32, int32) {
+	for _, entry := range s.Active(now) {
+		if entry.MinScale != nil {
+			min = *entry.MinScale
+		}
+		if entry.MaxScale != nil {
+			max = *entry.MaxScale
+		}
+	}
+	if max != 0 && max < min {
+		max = min
+	}
+	return min, max
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScaleSchedule(t *testing.T) {
+	ten, five := int32(10), int32(5)
+	weekdays := [7]bool{false, true, true, true, true, true, false}
+
+	cases := []struct {
+		name     string
+		schedule string
+		want     []ScaleScheduleEntry
+		wantErr  string
+	}{{
+		name:     "empty",
+		schedule: " ; \n",
+	}, {
+		name:     "weekdays",
+		schedule: "minScale=10 on weekdays 08:00-18:00 UTC",
+		want: []ScaleScheduleEntry{{
+			MinScale: &ten,
+			Days:     weekdays,
+			Start:    8 * 60,
+			End:      18 * 60,
+			Text:     "minScale=10 on weekdays 08:00-18:00 UTC",
+		}},
+	}, {
+		name:     "max less than min",
+		schedule: "minScale=10,maxScale=5 on Mon-Fri 08:00 – 18:00",
+		wantErr:  "maxScale=5 is less than minScale=10",
+	}, {
+		name:     "several entries",
+		schedule: "maxScale=5 on sat,sunday 22:00–06:30;\nminScale=10 on fri-mon 00:00-24:00",
+		want: []ScaleScheduleEntry{{
+			MaxScale: &five,
+			Days:     [7]bool{true, false, false, false, false, false, true},
+			Start:    22 * 60,
+			End:      6*60 + 30,
+			Text:     "maxScale=5 on sat,sunday 22:00–06:30",
+		}, {
+			MinScale: &ten,
+			Days:     [7]bool{true, true, false, false, false, true, true},
+			Start:    0,
+			End:      24 * 60,
+			Text:     "minScale=10 on fri-mon 00:00-24:00",
+		}},
+	}, {
+		name:     "missing days",
+		schedule: "minScale=10 08:00-18:00",
+		wantErr:  "want <bounds> on <days> <start>-<end> [<time zone>]",
+	}, {
+		name:     "unknown bound",
+		schedule: "targetScale=10 on daily 08:00-18:00",
+		wantErr:  `invalid bound "targetScale=10"`,
+	}, {
+		name:     "negative bound",
+		schedule: "minScale=-1 on daily 08:00-18:00",
+		wantErr:  "must be an integer equal or greater than 0",
+	}, {
+		name:     "unknown day",
+		schedule: "minScale=1 on mon-someday 08:00-18:00",
+		wantErr:  `unknown day "someday"`,
+	}, {
+		name:     "invalid time",
+		schedule: "minScale=1 on daily 8-18",
+		wantErr:  `invalid time of day "8"`,
+	}, {
+		name:     "past midnight",
+		schedule: "minScale=1 on daily 08:00-24:30",
+		wantErr:  `invalid time of day "24:30"`,
+	}, {
+		name:     "empty window",
+		schedule: "minScale=1 on daily 08:00-08:00",
+		wantErr:  "must not be empty",
+	}, {
+		name:     "unknown time zone",
+		schedule: "minScale=1 on daily 08:00-18:00 Mars/Olympus",
+		wantErr:  `unknown time zone "Mars/Olympus"`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseScaleSchedule(tc.schedule)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ParseScaleSchedule() = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseScaleSchedule() = %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(tc.want))
+			}
+			for i, want := range tc.want {
+				e := got[i]
+				if !equalBound(e.MinScale, want.MinScale) || !equalBound(e.MaxScale, want.MaxScale) {
+					t.Errorf("entry %d bounds = (%v, %v), want (%v, %v)", i, e.MinScale, e.MaxScale, want.MinScale, want.MaxScale)
+				}
+				if e.Days != want.Days || e.Start != want.Start || e.End != want.End || e.Text != want.Text {
+					t.Errorf("entry %d = %+v, want %+v", i, e, want)
+				}
+				if e.Location != time.UTC {
+					t.Errorf("entry %d location = %v, want UTC", i, e.Location)
+				}
+			}
+		})
+	}
+}
+
+func equalBound(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func TestScaleScheduleActive(t *testing.T) {
+	schedule, err := ParseScaleSchedule("minScale=10 on weekdays 08:00-18:00 America/New_York;" +
+		"maxScale=5 on fri 22:00-02:00")
+	if err != nil {
+		t.Fatalf("ParseScaleSchedule() = %v", err)
+	}
+
+	cases := []struct {
+		name string
+		now  time.Time
+		want []string
+	}{{
+		name: "monday morning in New York",
+		now:  time.Date(2019, time.June, 3, 12, 0, 0, 0, time.UTC),
+		want: []string{"minScale=10 on weekdays 08:00-18:00 America/New_York"},
+	}, {
+		name: "monday evening in New York",
+		now:  time.Date(2019, time.June, 3, 21, 59, 0, 0, time.UTC),
+		want: []string{"minScale=10 on weekdays 08:00-18:00 America/New_York"},
+	}, {
+		name: "monday end of the window in New York",
+		now:  time.Date(2019, time.June, 3, 22, 0, 0, 0, time.UTC),
+	}, {
+		name: "tuesday before the window",
+		now:  time.Date(2019, time.June, 4, 11, 59, 0, 0, time.UTC),
+	}, {
+		name: "friday night",
+		now:  time.Date(2019, time.June, 7, 23, 0, 0, 0, time.UTC),
+		want: []string{"maxScale=5 on fri 22:00-02:00"},
+	}, {
+		name: "past midnight into saturday",
+		now:  time.Date(2019, time.June, 8, 1, 59, 0, 0, time.UTC),
+		want: []string{"maxScale=5 on fri 22:00-02:00"},
+	}, {
+		name: "saturday end of the window",
+		now:  time.Date(2019, time.June, 8, 2, 0, 0, 0, time.UTC),
+	}, {
+		name: "saturday night",
+		now:  time.Date(2019, time.June, 8, 23, 0, 0, 0, time.UTC),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, e := range schedule.Active(tc.now) {
+				got = append(got, e.Text)
+			}
+			if strings.Join(got, ";") != strings.Join(tc.want, ";") {
+				t.Errorf("Active() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScaleScheduleNextChange(t *testing.T) {
+	schedule, err := ParseScaleSchedule("minScale=10 on weekdays 08:00-18:00;maxScale=5 on fri 22:00-02:00")
+	if err != nil {
+		t.Fatalf("ParseScaleSchedule() = %v", err)
+	}
+
+	cases := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{{
+		name: "before the start",
+		now:  time.Date(2019, time.June, 3, 7, 0, 0, 0, time.UTC),
+		want: time.Date(2019, time.June, 3, 8, 0, 0, 0, time.UTC),
+	}, {
+		name: "at the start",
+		now:  time.Date(2019, time.June, 3, 8, 0, 0, 0, time.UTC),
+		want: time.Date(2019, time.June, 3, 18, 0, 0, 0, time.UTC),
+	}, {
+		name: "friday evening",
+		now:  time.Date(2019, time.June, 7, 19, 0, 0, 0, time.UTC),
+		want: time.Date(2019, time.June, 7, 22, 0, 0, 0, time.UTC),
+	}, {
+		name: "friday night",
+		now:  time.Date(2019, time.June, 7, 23, 0, 0, 0, time.UTC),
+		want: time.Date(2019, time.June, 8, 2, 0, 0, 0, time.UTC),
+	}, {
+		name: "weekend",
+		now:  time.Date(2019, time.June, 8, 12, 0, 0, 0, time.UTC),
+		want: time.Date(2019, time.June, 10, 8, 0, 0, 0, time.UTC),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := schedule.NextChange(tc.now)
+			if !ok || !got.Equal(tc.want) {
+				t.Errorf("NextChange() = %v, %v, want %v", got, ok, tc.want)
+			}
+		})
+	}
+
+	if _, ok := ScaleSchedule(nil).NextChange(time.Now()); ok {
+		t.Error("NextChange() of an empty schedule = true, want false")
+	}
+}
+
+func TestScaleScheduleScaleBounds(t *testing.T) {
+	schedule, err := ParseScaleSchedule("minScale=10 on daily 00:00-24:00;maxScale=3,minScale=2 on daily 00:00-24:00")
+	if err != nil {
+		t.Fatalf("ParseScaleSchedule() = %v", err)
+	}
+	now := time.Date(2019, time.June, 3, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name             string
+		schedule         ScaleSchedule
+		min, max         int32
+		wantMin, wantMax int32
+	}{{
+		name:    "no schedule",
+		min:     1,
+		max:     5,
+		wantMin: 1,
+		wantMax: 5,
+	}, {
+		name:     "later entries take precedence",
+		schedule: schedule,
+		min:      1,
+		max:      20,
+		wantMin:  2,
+		wantMax:  3,
+	}, {
+		name:     "min raises max",
+		schedule: schedule[:1],
+		min:      1,
+		max:      5,
+		wantMin:  10,
+		wantMax:  10,
+	}, {
+		name:     "unbounded max",
+		schedule: schedule[:1],
+		min:      1,
+		wantMin:  10,
+		wantMax:  0,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			min, max := tc.schedule.ScaleBounds(now, tc.min, tc.max)
+			if min != tc.wantMin || max != tc.wantMax {
+				t.Errorf("ScaleBounds() = (%d, %d), want (%d, %d)", min, max, tc.wantMin, tc.wantMax)
+			}
+		})
+	}
+}
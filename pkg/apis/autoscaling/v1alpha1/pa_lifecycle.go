@@ -54,6 +54,21 @@ func (pa *PodAutoscaler) Metric() string {
 	return defaultMetric(pa.Class())
 }
 
+// Algorithm returns the contents of the algorithm annotation or the default
+// stable/panic algorithm.
+func (pa *PodAutoscaler) Algorithm() string {
+	if a, ok := pa.Annotations[autoscaling.AlgorithmAnnotationKey]; ok {
+		return a
+	}
+	return autoscaling.StablePanicAlgorithm
+}
+
+// Aggregation returns the contents of the aggregation annotation, if present.
+func (pa *PodAutoscaler) Aggregation() (string, bool) {
+	a, ok := pa.Annotations[autoscaling.AggregationAnnotationKey]
+	return a, ok
+}
+
 func (pa *PodAutoscaler) annotationInt32(key string) int32 {
 	if s, ok := pa.Annotations[key]; ok {
 		// no error check: relying on validation
@@ -76,12 +91,54 @@ func (pa *PodAutoscaler) annotationFloat64(key string) (float64, bool) {
 }
 
 // ScaleBounds returns scale bounds annotations values as a tuple:
-// `(min, max int32)`. The value of 0 for any of min or max means the bound is
-// not set
+// `(min, max int32)`, overridden by the currently active entries of the scale
+// schedule. The value of 0 for any of min or max means the bound is not set
 func (pa *PodAutoscaler) ScaleBounds() (min, max int32) {
+	return pa.ScaleBoundsAt(time.Now())
+}
+
+// ScaleBoundsAt returns the scale bounds at the given time.
+func (pa *PodAutoscaler) ScaleBoundsAt(now time.Time) (min, max int32) {
 	min = pa.annotationInt32(autoscaling.MinScaleAnnotationKey)
 	max = pa.annotationInt32(autoscaling.MaxScaleAnnotationKey)
-	return
+	return pa.ScaleSchedule().ScaleBounds(now, min, max)
+}
+
+// KeepMinScale returns whether the keep min scale annotation asks to enforce
+// the minimum scale even when no Route refers to the revision of the PA.
+func (pa *PodAutoscaler) KeepMinScale() bool {
+	// no error check: relying on validation
+	keep, _ := strconv.ParseBool(pa.Annotations[autoscaling.KeepMinScaleAnnotationKey])
+	return keep
+}
+
+// ScaleSchedule returns the parsed scale schedule annotation, or nil if not
+// present or invalid.
+func (pa *PodAutoscaler) ScaleSchedule() autoscaling.ScaleSchedule {
+	if s, ok := pa.Annotations[autoscaling.ScaleScheduleAnnotationKey]; ok {
+		// no error check: relying on validation
+		schedule, _ := autoscaling.ParseScaleSchedule(s)
+		return schedule
+	}
+	return nil
+}
+
+// Frozen returns whether the freeze annotation stops the scaling of the PA.
+func (pa *PodAutoscaler) Frozen() bool {
+	// no error check: relying on validation
+	frozen, _ := strconv.ParseBool(pa.Annotations[autoscaling.FreezeAnnotationKey])
+	return frozen
+}
+
+// FrozenScale returns the frozen scale annotation value or false if not
+// present, or invalid.
+func (pa *PodAutoscaler) FrozenScale() (int32, bool) {
+	if s, ok := pa.Annotations[autoscaling.FrozenScaleAnnotationKey]; ok {
+		if i, err := strconv.ParseInt(s, 10, 32); err == nil && i >= 0 {
+			return int32(i), true
+		}
+	}
+	return 0, false
 }
 
 // Target returns the target annotation value or false if not present, or invalid.
@@ -98,6 +155,44 @@ func (pa *PodAutoscaler) Target() (float64, bool) {
 	return 0, false
 }
 
+// TargetRPS returns the target requests per second annotation value or false
+// if not present, or invalid.
+func (pa *PodAutoscaler) TargetRPS() (float64, bool) {
+	if target, ok := pa.annotationFloat64(autoscaling.TargetRPSAnnotationKey); ok && target > 0 {
+		return target, true
+	}
+	return 0, false
+}
+
+// TargetBurstCapacity returns the target burst capacity annotation value or
+// false if not present or invalid.
+func (pa *PodAutoscaler) TargetBurstCapacity() (float64, bool) {
+	if tbc, ok := pa.annotationFloat64(autoscaling.TargetBurstCapacityAnnotationKey); ok && (tbc >= 0 || tbc == -1) {
+		return tbc, true
+	}
+	return 0, false
+}
+
+// TargetLatency returns the target latency annotation value along with the
+// latency percentile it applies to, or false if not present or invalid.
+func (pa *PodAutoscaler) TargetLatency() (time.Duration, int, bool) {
+	s, ok := pa.Annotations[autoscaling.TargetLatencyAnnotationKey]
+	if !ok {
+		return 0, 0, false
+	}
+	latency, err := time.ParseDuration(s)
+	if err != nil || latency <= 0 {
+		return 0, 0, false
+	}
+	percentile := autoscaling.TargetLatencyPercentileDefault
+	if s, ok := pa.Annotations[autoscaling.TargetLatencyPercentileAnnotationKey]; ok {
+		if p, err := strconv.Atoi(s); err == nil && (p == 50 || p == 95 || p == 99) {
+			percentile = p
+		}
+	}
+	return latency, percentile, true
+}
+
 // Window returns the window annotation value or false if not present.
 func (pa *PodAutoscaler) Window() (window time.Duration, ok bool) {
 	if s, ok := pa.Annotations[autoscaling.WindowAnnotationKey]; ok {
@@ -113,6 +208,32 @@ func (pa *PodAutoscaler) Window() (window time.Duration, ok bool) {
 	return 0, false
 }
 
+// ScaleToZeroIdleTimeout returns the scale to zero idle timeout annotation
+// value or false if not present or invalid. The bounds in config-autoscaler
+// are checked by the validation.
+func (pa *PodAutoscaler) ScaleToZeroIdleTimeout() (time.Duration, bool) {
+	return pa.annotationDurationGT0(autoscaling.ScaleToZeroIdleTimeoutAnnotationKey)
+}
+
+// ScaleToZeroGracePeriod returns the scale to zero grace period annotation
+// value or false if not present or invalid. The bounds in config-autoscaler
+// are checked by the validation.
+func (pa *PodAutoscaler) ScaleToZeroGracePeriod() (time.Duration, bool) {
+	return pa.annotationDurationGT0(autoscaling.ScaleToZeroGracePeriodAnnotationKey)
+}
+
+func (pa *PodAutoscaler) annotationDurationGT0(key string) (time.Duration, bool) {
+	s, ok := pa.Annotations[key]
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
 // PanicWindowPercentage returns panic window annotation value or false if not present.
 func (pa *PodAutoscaler) PanicWindowPercentage() (percentage float64, ok bool) {
 	percentage, ok = pa.annotationFloat64(autoscaling.PanicWindowPercentageAnnotationKey)
@@ -132,6 +253,16 @@ func (pa *PodAutoscaler) PanicThresholdPercentage() (percentage float64, ok bool
 	return percentage, ok
 }
 
+// MaxScaleDownRate returns the max scale down rate annotation value or false
+// if not present, or invalid.
+func (pa *PodAutoscaler) MaxScaleDownRate() (rate float64, ok bool) {
+	rate, ok = pa.annotationFloat64(autoscaling.MaxScaleDownRateAnnotationKey)
+	if !ok || rate <= autoscaling.MaxScaleDownRateMin {
+		return 0, false
+	}
+	return rate, ok
+}
+
 // IsReady looks at the conditions and if the Status has a condition
 // PodAutoscalerConditionReady returns true if ConditionStatus is True
 func (pas *PodAutoscalerStatus) IsReady() bool {
@@ -151,6 +282,13 @@ func (pas *PodAutoscalerStatus) IsInactive() bool {
 	return cond != nil && cond.Status == corev1.ConditionFalse
 }
 
+// IsScaleTargetInitialized returns true if the scale target of the PA has
+// reached its initial scale.
+func (pas *PodAutoscalerStatus) IsScaleTargetInitialized() bool {
+	cond := pas.GetCondition(PodAutoscalerConditionScaleTargetInitialized)
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
+
 // GetCondition gets the condition `t`.
 func (pas *PodAutoscalerStatus) GetCondition(t apis.ConditionType) *apis.Condition {
 	return podCondSet.Manage(pas.duck()).GetCondition(t)
@@ -176,6 +314,41 @@ func (pas *PodAutoscalerStatus) MarkInactive(reason, message string) {
 	podCondSet.Manage(pas.duck()).MarkFalse(PodAutoscalerConditionActive, reason, message)
 }
 
+// MarkAutoscalingPaused marks the scaling of the PA as frozen.
+func (pas *PodAutoscalerStatus) MarkAutoscalingPaused(message string) {
+	podCondSet.Manage(pas.duck()).SetCondition(apis.Condition{
+		Type:     PodAutoscalerConditionAutoscalingPaused,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+		Reason:   "Frozen",
+		Message:  message,
+	})
+}
+
+// MarkAutoscalingResumed removes the AutoscalingPaused condition, if any.
+func (pas *PodAutoscalerStatus) MarkAutoscalingResumed() {
+	if pas.GetCondition(PodAutoscalerConditionAutoscalingPaused) == nil {
+		return
+	}
+	conditions := make(duckv1beta1.Conditions, 0, len(pas.Conditions))
+	for _, c := range pas.Conditions {
+		if c.Type != PodAutoscalerConditionAutoscalingPaused {
+			conditions = append(conditions, c)
+		}
+	}
+	pas.Conditions = conditions
+}
+
+// MarkScaleTargetInitialized marks the scale target of the PA as having
+// reached its initial scale.
+func (pas *PodAutoscalerStatus) MarkScaleTargetInitialized() {
+	podCondSet.Manage(pas.duck()).SetCondition(apis.Condition{
+		Type:     PodAutoscalerConditionScaleTargetInitialized,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
+
 // MarkResourceNotOwned changes the "Active" condition to false to reflect that the
 // resource of the given kind and name has already been created, and we do not own it.
 func (pas *PodAutoscalerStatus) MarkResourceNotOwned(kind, name string) {
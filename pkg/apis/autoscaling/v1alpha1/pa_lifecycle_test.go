@@ -428,6 +428,124 @@ func TestTargetAnnotation(t *testing.T) {
 	}
 }
 
+func TestTargetRPSAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		pa         *PodAutoscaler
+		wantTarget float64
+		wantOk     bool
+	}{{
+		name:       "not present",
+		pa:         pa(map[string]string{}),
+		wantTarget: 0,
+		wantOk:     false,
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.TargetRPSAnnotationKey: "150",
+		}),
+		wantTarget: 150,
+		wantOk:     true,
+	}, {
+		name: "fractional",
+		pa: pa(map[string]string{
+			autoscaling.TargetRPSAnnotationKey: "0.5",
+		}),
+		wantTarget: 0.5,
+		wantOk:     true,
+	}, {
+		name: "invalid zero",
+		pa: pa(map[string]string{
+			autoscaling.TargetRPSAnnotationKey: "0",
+		}),
+		wantTarget: 0,
+		wantOk:     false,
+	}, {
+		name: "invalid format",
+		pa: pa(map[string]string{
+			autoscaling.TargetRPSAnnotationKey: "sandwich",
+		}),
+		wantTarget: 0,
+		wantOk:     false,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotTarget, gotOk := tc.pa.TargetRPS()
+			if gotTarget != tc.wantTarget {
+				t.Errorf("got target: %v wanted: %v", gotTarget, tc.wantTarget)
+			}
+			if gotOk != tc.wantOk {
+				t.Errorf("got ok: %v wanted %v", gotOk, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestTargetLatencyAnnotation(t *testing.T) {
+	cases := []struct {
+		name           string
+		pa             *PodAutoscaler
+		wantLatency    time.Duration
+		wantPercentile int
+		wantOk         bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "default percentile",
+		pa: pa(map[string]string{
+			autoscaling.TargetLatencyAnnotationKey: "300ms",
+		}),
+		wantLatency:    300 * time.Millisecond,
+		wantPercentile: 95,
+		wantOk:         true,
+	}, {
+		name: "with percentile",
+		pa: pa(map[string]string{
+			autoscaling.TargetLatencyAnnotationKey:           "1s",
+			autoscaling.TargetLatencyPercentileAnnotationKey: "99",
+		}),
+		wantLatency:    time.Second,
+		wantPercentile: 99,
+		wantOk:         true,
+	}, {
+		name: "invalid percentile",
+		pa: pa(map[string]string{
+			autoscaling.TargetLatencyAnnotationKey:           "1s",
+			autoscaling.TargetLatencyPercentileAnnotationKey: "90",
+		}),
+		wantLatency:    time.Second,
+		wantPercentile: 95,
+		wantOk:         true,
+	}, {
+		name: "invalid format",
+		pa: pa(map[string]string{
+			autoscaling.TargetLatencyAnnotationKey: "fast",
+		}),
+	}, {
+		name: "invalid zero",
+		pa: pa(map[string]string{
+			autoscaling.TargetLatencyAnnotationKey: "0s",
+		}),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotLatency, gotPercentile, gotOk := tc.pa.TargetLatency()
+			if gotLatency != tc.wantLatency {
+				t.Errorf("got latency: %v wanted: %v", gotLatency, tc.wantLatency)
+			}
+			if gotPercentile != tc.wantPercentile {
+				t.Errorf("got percentile: %v wanted: %v", gotPercentile, tc.wantPercentile)
+			}
+			if gotOk != tc.wantOk {
+				t.Errorf("got ok: %v wanted %v", gotOk, tc.wantOk)
+			}
+		})
+	}
+}
+
 func TestScaleBounds(t *testing.T) {
 	cases := []struct {
 		name    string
@@ -477,6 +595,23 @@ func TestScaleBounds(t *testing.T) {
 		}),
 		wantMin: 0,
 		wantMax: 0,
+	}, {
+		name: "scheduled",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleAnnotationKey:      "1",
+			autoscaling.MaxScaleAnnotationKey:      "5",
+			autoscaling.ScaleScheduleAnnotationKey: "minScale=10 on daily 00:00-24:00",
+		}),
+		wantMin: 10,
+		wantMax: 10,
+	}, {
+		name: "malformed schedule",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleAnnotationKey:      "1",
+			autoscaling.ScaleScheduleAnnotationKey: "minScale=10 always",
+		}),
+		wantMin: 1,
+		wantMax: 0,
 	}}
 
 	for _, tc := range cases {
@@ -492,6 +627,53 @@ func TestScaleBounds(t *testing.T) {
 	}
 }
 
+func TestScaleBoundsAt(t *testing.T) {
+	pa := pa(map[string]string{
+		autoscaling.MinScaleAnnotationKey: "1",
+		autoscaling.MaxScaleAnnotationKey: "20",
+		autoscaling.ScaleScheduleAnnotationKey: "minScale=10 on weekdays 08:00-18:00 UTC;" +
+			"maxScale=5 on daily 22:00-06:00 UTC",
+	})
+	cases := []struct {
+		name    string
+		now     time.Time
+		wantMin int32
+		wantMax int32
+	}{{
+		name:    "weekday business hours",
+		now:     time.Date(2019, time.June, 3, 12, 0, 0, 0, time.UTC), // Monday
+		wantMin: 10,
+		wantMax: 20,
+	}, {
+		name:    "weekend business hours",
+		now:     time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC), // Saturday
+		wantMin: 1,
+		wantMax: 20,
+	}, {
+		name:    "night",
+		now:     time.Date(2019, time.June, 4, 3, 0, 0, 0, time.UTC),
+		wantMin: 1,
+		wantMax: 5,
+	}, {
+		name:    "evening",
+		now:     time.Date(2019, time.June, 4, 20, 0, 0, 0, time.UTC),
+		wantMin: 1,
+		wantMax: 20,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			min, max := pa.ScaleBoundsAt(tc.now)
+			if min != tc.wantMin {
+				t.Errorf("got min: %v wanted: %v", min, tc.wantMin)
+			}
+			if max != tc.wantMax {
+				t.Errorf("got max: %v wanted: %v", max, tc.wantMax)
+			}
+		})
+	}
+}
+
 func TestMarkResourceNotOwned(t *testing.T) {
 	pa := pa(map[string]string{})
 	pa.Status.MarkResourceNotOwned("doesn't", "matter")
@@ -504,6 +686,133 @@ func TestMarkResourceNotOwned(t *testing.T) {
 	}
 }
 
+func TestFreeze(t *testing.T) {
+	cases := []struct {
+		name        string
+		pa          *PodAutoscaler
+		wantFrozen  bool
+		wantScale   int32
+		wantScaleOK bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "frozen",
+		pa: pa(map[string]string{
+			autoscaling.FreezeAnnotationKey: "true",
+		}),
+		wantFrozen: true,
+	}, {
+		name: "frozen at a scale",
+		pa: pa(map[string]string{
+			autoscaling.FreezeAnnotationKey:      "true",
+			autoscaling.FrozenScaleAnnotationKey: "3",
+		}),
+		wantFrozen:  true,
+		wantScale:   3,
+		wantScaleOK: true,
+	}, {
+		name: "not frozen",
+		pa: pa(map[string]string{
+			autoscaling.FreezeAnnotationKey:      "false",
+			autoscaling.FrozenScaleAnnotationKey: "0",
+		}),
+		wantScaleOK: true,
+	}, {
+		name: "invalid",
+		pa: pa(map[string]string{
+			autoscaling.FreezeAnnotationKey:      "maybe",
+			autoscaling.FrozenScaleAnnotationKey: "-3",
+		}),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pa.Frozen(); got != tc.wantFrozen {
+				t.Errorf("Frozen() = %v, want: %v", got, tc.wantFrozen)
+			}
+			if got, ok := tc.pa.FrozenScale(); got != tc.wantScale || ok != tc.wantScaleOK {
+				t.Errorf("FrozenScale() = (%d, %v), want: (%d, %v)", got, ok, tc.wantScale, tc.wantScaleOK)
+			}
+		})
+	}
+}
+
+func TestKeepMinScale(t *testing.T) {
+	cases := []struct {
+		name string
+		pa   *PodAutoscaler
+		want bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "keep",
+		pa: pa(map[string]string{
+			autoscaling.KeepMinScaleAnnotationKey: "true",
+		}),
+		want: true,
+	}, {
+		name: "release",
+		pa: pa(map[string]string{
+			autoscaling.KeepMinScaleAnnotationKey: "false",
+		}),
+	}, {
+		name: "invalid",
+		pa: pa(map[string]string{
+			autoscaling.KeepMinScaleAnnotationKey: "sometimes",
+		}),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pa.KeepMinScale(); got != tc.want {
+				t.Errorf("KeepMinScale() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMarkAutoscalingPaused(t *testing.T) {
+	pa := &PodAutoscalerStatus{}
+	pa.InitializeConditions()
+	pa.MarkActive()
+
+	pa.MarkAutoscalingPaused("frozen at 3")
+	paused := pa.GetCondition(PodAutoscalerConditionAutoscalingPaused)
+	if paused == nil || paused.Status != corev1.ConditionTrue || paused.Message != "frozen at 3" {
+		t.Errorf("AutoscalingPaused = %+v, want True with message %q", paused, "frozen at 3")
+	}
+	if !pa.IsReady() {
+		t.Error("IsReady() = false, pausing must not affect readiness")
+	}
+
+	pa.MarkAutoscalingResumed()
+	if paused := pa.GetCondition(PodAutoscalerConditionAutoscalingPaused); paused != nil {
+		t.Errorf("AutoscalingPaused = %+v, want no condition", paused)
+	}
+	if !pa.IsReady() {
+		t.Error("IsReady() = false after resuming")
+	}
+}
+
+func TestMarkScaleTargetInitialized(t *testing.T) {
+	pa := &PodAutoscalerStatus{}
+	pa.InitializeConditions()
+	if pa.IsScaleTargetInitialized() {
+		t.Error("IsScaleTargetInitialized() = true, want false")
+	}
+
+	pa.MarkActivating("Queued", "")
+	pa.MarkScaleTargetInitialized()
+	if !pa.IsScaleTargetInitialized() {
+		t.Error("IsScaleTargetInitialized() = false, want true")
+	}
+	if !pa.IsActivating() {
+		t.Error("IsActivating() = false, initializing must not affect the Active condition")
+	}
+}
+
 func TestMarkResourceFailedCreation(t *testing.T) {
 	pa := &PodAutoscalerStatus{}
 	pa.MarkResourceFailedCreation("doesn't", "matter")
@@ -645,6 +954,97 @@ func TestWindowAnnotation(t *testing.T) {
 	}
 }
 
+func TestTargetBurstCapacity(t *testing.T) {
+	cases := []struct {
+		name   string
+		pa     *PodAutoscaler
+		want   float64
+		wantOk bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.TargetBurstCapacityAnnotationKey: "200",
+		}),
+		want:   200,
+		wantOk: true,
+	}, {
+		name: "always in path",
+		pa: pa(map[string]string{
+			autoscaling.TargetBurstCapacityAnnotationKey: "-1",
+		}),
+		want:   -1,
+		wantOk: true,
+	}, {
+		name: "invalid negative",
+		pa: pa(map[string]string{
+			autoscaling.TargetBurstCapacityAnnotationKey: "-5",
+		}),
+	}, {
+		name: "invalid format",
+		pa: pa(map[string]string{
+			autoscaling.TargetBurstCapacityAnnotationKey: "lots",
+		}),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, ok := tc.pa.TargetBurstCapacity(); got != tc.want || ok != tc.wantOk {
+				t.Errorf("TargetBurstCapacity() = (%v, %v), want: (%v, %v)", got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestScaleToZeroAnnotations(t *testing.T) {
+	cases := []struct {
+		name            string
+		pa              *PodAutoscaler
+		wantIdleTimeout time.Duration
+		wantIdleOk      bool
+		wantGracePeriod time.Duration
+		wantGraceOk     bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.ScaleToZeroIdleTimeoutAnnotationKey: "15m",
+			autoscaling.ScaleToZeroGracePeriodAnnotationKey: "45s",
+		}),
+		wantIdleTimeout: 15 * time.Minute,
+		wantIdleOk:      true,
+		wantGracePeriod: 45 * time.Second,
+		wantGraceOk:     true,
+	}, {
+		name: "invalid not positive",
+		pa: pa(map[string]string{
+			autoscaling.ScaleToZeroIdleTimeoutAnnotationKey: "0s",
+			autoscaling.ScaleToZeroGracePeriodAnnotationKey: "-1m",
+		}),
+	}, {
+		name: "invalid format",
+		pa: pa(map[string]string{
+			autoscaling.ScaleToZeroIdleTimeoutAnnotationKey: "sandwich",
+			autoscaling.ScaleToZeroGracePeriodAnnotationKey: "sandwich",
+		}),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, ok := tc.pa.ScaleToZeroIdleTimeout(); got != tc.wantIdleTimeout || ok != tc.wantIdleOk {
+				t.Errorf("ScaleToZeroIdleTimeout() = (%v, %v), want: (%v, %v)", got, ok, tc.wantIdleTimeout, tc.wantIdleOk)
+			}
+			if got, ok := tc.pa.ScaleToZeroGracePeriod(); got != tc.wantGracePeriod || ok != tc.wantGraceOk {
+				t.Errorf("ScaleToZeroGracePeriod() = (%v, %v), want: (%v, %v)", got, ok, tc.wantGracePeriod, tc.wantGraceOk)
+			}
+		})
+	}
+}
+
 func TestPanicWindowPercentageAnnotation(t *testing.T) {
 	cases := []struct {
 		name           string
@@ -739,6 +1139,107 @@ func TestPanicThresholdPercentage(t *testing.T) {
 	}
 }
 
+func TestAlgorithm(t *testing.T) {
+	cases := []struct {
+		name string
+		pa   *PodAutoscaler
+		want string
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+		want: autoscaling.StablePanicAlgorithm,
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.AlgorithmAnnotationKey: autoscaling.PIDAlgorithm,
+		}),
+		want: autoscaling.PIDAlgorithm,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pa.Algorithm(); got != tc.want {
+				t.Errorf("Algorithm() = %q, want: %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAggregation(t *testing.T) {
+	cases := []struct {
+		name   string
+		pa     *PodAutoscaler
+		want   string
+		wantOk bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.AggregationAnnotationKey: autoscaling.EWMAAggregation,
+		}),
+		want:   autoscaling.EWMAAggregation,
+		wantOk: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotOk := tc.pa.Aggregation()
+			if got != tc.want || gotOk != tc.wantOk {
+				t.Errorf("Aggregation() = (%q, %v), want: (%q, %v)", got, gotOk, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestMaxScaleDownRate(t *testing.T) {
+	cases := []struct {
+		name     string
+		pa       *PodAutoscaler
+		wantRate float64
+		wantOk   bool
+	}{{
+		name:     "not present",
+		pa:       pa(map[string]string{}),
+		wantRate: 0.0,
+		wantOk:   false,
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.MaxScaleDownRateAnnotationKey: "1.5",
+		}),
+		wantRate: 1.5,
+		wantOk:   true,
+	}, {
+		name: "too small",
+		pa: pa(map[string]string{
+			autoscaling.MaxScaleDownRateAnnotationKey: "1.0",
+		}),
+		wantRate: 0.0,
+		wantOk:   false,
+	}, {
+		name: "invalid format",
+		pa: pa(map[string]string{
+			autoscaling.MaxScaleDownRateAnnotationKey: "sandwich",
+		}),
+		wantRate: 0.0,
+		wantOk:   false,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotRate, gotOk := tc.pa.MaxScaleDownRate()
+			if gotRate != tc.wantRate {
+				t.Errorf("%q expected rate: %v got: %v", tc.name, tc.wantRate, gotRate)
+			}
+			if gotOk != tc.wantOk {
+				t.Errorf("%q expected ok: %v got %v", tc.name, tc.wantOk, gotOk)
+			}
+		})
+	}
+}
+
 func pa(annotations map[string]string) *PodAutoscaler {
 	p := &PodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
@@ -101,6 +101,14 @@ const (
 	PodAutoscalerConditionReady = apis.ConditionReady
 	// PodAutoscalerConditionActive is set when the PodAutoscaler's ScaleTargetRef is receiving traffic.
 	PodAutoscalerConditionActive apis.ConditionType = "Active"
+	// PodAutoscalerConditionAutoscalingPaused is set while the scaling of
+	// the PodAutoscaler's ScaleTargetRef is frozen. It does not affect the
+	// readiness of the PodAutoscaler.
+	PodAutoscalerConditionAutoscalingPaused apis.ConditionType = "AutoscalingPaused"
+	// PodAutoscalerConditionScaleTargetInitialized is set when the
+	// PodAutoscaler's ScaleTargetRef first reached its initial scale. It does
+	// not affect the readiness of the PodAutoscaler.
+	PodAutoscalerConditionScaleTargetInitialized apis.ConditionType = "ScaleTargetInitialized"
 )
 
 // PodAutoscalerStatus communicates the observed state of the PodAutoscaler (from the controller).
@@ -114,6 +122,16 @@ type PodAutoscalerStatus struct {
 	// MetricsServiceName is the K8s Service name that provides revision metrics.
 	// The service is managed by the PA object.
 	MetricsServiceName string `json:"metricsServiceName"`
+
+	// ActiveScaleSchedule lists the entries of the scale schedule annotation
+	// currently overriding the scale bounds.
+	// +optional
+	ActiveScaleSchedule []string `json:"activeScaleSchedule,omitempty"`
+
+	// TunedTarget is the target concurrency the autoscaler currently tunes
+	// to meet the target latency annotation, if present.
+	// +optional
+	TunedTarget float64 `json:"tunedTarget,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -202,6 +202,48 @@ func TestPodAutoscalerValidation(t *testing.T) {
 			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer equal or greater than 0", autoscaling.MinScaleAnnotationKey),
 			Paths:   []string{autoscaling.MinScaleAnnotationKey},
 		}).ViaField("annotations").ViaField("metadata"),
+	}, {
+		name: "kpa class with rps metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:     autoscaling.KPA,
+					autoscaling.MetricAnnotationKey:    autoscaling.RPS,
+					autoscaling.TargetRPSAnnotationKey: "150",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "hpa class with rps metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: autoscaling.RPS,
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: fmt.Sprintf("Unsupported metric %q for PodAutoscaler class %q", autoscaling.RPS, autoscaling.HPA),
+			Paths:   []string{"annotations[autoscaling.knative.dev/metric]"},
+		},
 	}, {
 		name: "empty spec",
 		r: &PodAutoscaler{
@@ -107,6 +107,11 @@ func (in *PodAutoscalerSpec) DeepCopy() *PodAutoscalerSpec {
 func (in *PodAutoscalerStatus) DeepCopyInto(out *PodAutoscalerStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.ActiveScaleSchedule != nil {
+		in, out := &in.ActiveScaleSchedule, &out.ActiveScaleSchedule
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
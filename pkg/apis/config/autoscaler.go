@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AutoscalerConfigName is the name of config map of the autoscaler.
+	AutoscalerConfigName = "config-autoscaler"
+
+	// DefaultStableWindow will be used if stable-window is not specified.
+	DefaultStableWindow = 60 * time.Second
+
+	// DefaultScaleToZeroIdleTimeoutMax will be used if
+	// scale-to-zero-idle-timeout-max is not specified.
+	DefaultScaleToZeroIdleTimeoutMax = time.Hour
+
+	// DefaultScaleToZeroGracePeriodMin will be used if
+	// scale-to-zero-grace-period-min is not specified.
+	DefaultScaleToZeroGracePeriodMin = 30 * time.Second
+
+	// DefaultScaleToZeroGracePeriodMax will be used if
+	// scale-to-zero-grace-period-max is not specified.
+	DefaultScaleToZeroGracePeriodMax = time.Hour
+)
+
+// NewAutoscalerConfigFromMap creates an Autoscaler from the supplied Map
+func NewAutoscalerConfigFromMap(data map[string]string) (*Autoscaler, error) {
+	ac := &Autoscaler{}
+
+	// Process Duration fields
+	for _, dur := range []struct {
+		key          string
+		field        *time.Duration
+		defaultValue time.Duration
+	}{{
+		key:          "stable-window",
+		field:        &ac.StableWindow,
+		defaultValue: DefaultStableWindow,
+	}, {
+		key:   "scale-to-zero-idle-timeout-min",
+		field: &ac.ScaleToZeroIdleTimeoutMin,
+	}, {
+		key:          "scale-to-zero-idle-timeout-max",
+		field:        &ac.ScaleToZeroIdleTimeoutMax,
+		defaultValue: DefaultScaleToZeroIdleTimeoutMax,
+	}, {
+		key:          "scale-to-zero-grace-period-min",
+		field:        &ac.ScaleToZeroGracePeriodMin,
+		defaultValue: DefaultScaleToZeroGracePeriodMin,
+	}, {
+		key:          "scale-to-zero-grace-period-max",
+		field:        &ac.ScaleToZeroGracePeriodMax,
+		defaultValue: DefaultScaleToZeroGracePeriodMax,
+	}} {
+		if raw, ok := data[dur.key]; !ok {
+			*dur.field = dur.defaultValue
+		} else if val, err := time.ParseDuration(raw); err != nil {
+			return nil, err
+		} else if val < 0 {
+			return nil, fmt.Errorf("%s cannot be negative, got %v", dur.key, val)
+		} else {
+			*dur.field = val
+		}
+	}
+
+	if ac.ScaleToZeroIdleTimeoutMin > ac.ScaleToZeroIdleTimeoutMax {
+		return nil, fmt.Errorf("scale-to-zero-idle-timeout-min (%v) cannot be greater than scale-to-zero-idle-timeout-max (%v)",
+			ac.ScaleToZeroIdleTimeoutMin, ac.ScaleToZeroIdleTimeoutMax)
+	}
+	if ac.ScaleToZeroGracePeriodMin > ac.ScaleToZeroGracePeriodMax {
+		return nil, fmt.Errorf("scale-to-zero-grace-period-min (%v) cannot be greater than scale-to-zero-grace-period-max (%v)",
+			ac.ScaleToZeroGracePeriodMin, ac.ScaleToZeroGracePeriodMax)
+	}
+
+	return ac, nil
+}
+
+// NewAutoscalerConfigFromConfigMap creates an Autoscaler from the supplied configMap
+func NewAutoscalerConfigFromConfigMap(config *corev1.ConfigMap) (*Autoscaler, error) {
+	return NewAutoscalerConfigFromMap(config.Data)
+}
+
+// Autoscaler includes the parts of the autoscaler configuration the webhook
+// checks the autoscaling annotations of the revisions against.
+type Autoscaler struct {
+	// StableWindow is used for the revisions without the window annotation.
+	StableWindow time.Duration
+
+	// The bounds of the scaleToZeroIdleTimeout annotation. The annotation
+	// must not be shorter than the stable window of the revision either.
+	ScaleToZeroIdleTimeoutMin time.Duration
+	ScaleToZeroIdleTimeoutMax time.Duration
+
+	// The bounds of the scaleToZeroGracePeriod annotation.
+	ScaleToZeroGracePeriodMin time.Duration
+	ScaleToZeroGracePeriodMax time.Duration
+}
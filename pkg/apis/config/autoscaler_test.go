@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	. "github.com/knative/pkg/configmap/testing"
+	_ "github.com/knative/pkg/system/testing"
+)
+
+func TestAutoscalerConfigurationFromFile(t *testing.T) {
+	cm, example := ConfigMapsFromTestFile(t, AutoscalerConfigName)
+
+	if _, err := NewAutoscalerConfigFromConfigMap(cm); err != nil {
+		t.Errorf("NewAutoscalerConfigFromConfigMap(actual) = %v", err)
+	}
+
+	if _, err := NewAutoscalerConfigFromConfigMap(example); err != nil {
+		t.Errorf("NewAutoscalerConfigFromConfigMap(example) = %v", err)
+	}
+}
+
+func TestAutoscalerConfiguration(t *testing.T) {
+	configTests := []struct {
+		name           string
+		wantErr        bool
+		wantAutoscaler *Autoscaler
+		data           map[string]string
+	}{{
+		name: "defaults configuration",
+		wantAutoscaler: &Autoscaler{
+			StableWindow:              DefaultStableWindow,
+			ScaleToZeroIdleTimeoutMax: DefaultScaleToZeroIdleTimeoutMax,
+			ScaleToZeroGracePeriodMin: DefaultScaleToZeroGracePeriodMin,
+			ScaleToZeroGracePeriodMax: DefaultScaleToZeroGracePeriodMax,
+		},
+		data: map[string]string{},
+	}, {
+		name: "specified values",
+		wantAutoscaler: &Autoscaler{
+			StableWindow:              2 * time.Minute,
+			ScaleToZeroIdleTimeoutMin: 3 * time.Minute,
+			ScaleToZeroIdleTimeoutMax: 30 * time.Minute,
+			ScaleToZeroGracePeriodMin: time.Minute,
+			ScaleToZeroGracePeriodMax: 5 * time.Minute,
+		},
+		data: map[string]string{
+			"stable-window":                  "2m",
+			"scale-to-zero-idle-timeout-min": "3m",
+			"scale-to-zero-idle-timeout-max": "30m",
+			"scale-to-zero-grace-period-min": "1m",
+			"scale-to-zero-grace-period-max": "5m",
+		},
+	}, {
+		name:    "bad duration",
+		wantErr: true,
+		data: map[string]string{
+			"scale-to-zero-idle-timeout-max": "forever",
+		},
+	}, {
+		name:    "negative duration",
+		wantErr: true,
+		data: map[string]string{
+			"scale-to-zero-grace-period-min": "-1s",
+		},
+	}, {
+		name:    "idle timeout min bigger than max",
+		wantErr: true,
+		data: map[string]string{
+			"scale-to-zero-idle-timeout-min": "2h",
+		},
+	}, {
+		name:    "grace period min bigger than max",
+		wantErr: true,
+		data: map[string]string{
+			"scale-to-zero-grace-period-min": "5m",
+			"scale-to-zero-grace-period-max": "1m",
+		},
+	}}
+
+	for _, tt := range configTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAutoscalerConfigFromMap(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAutoscalerConfigFromMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantAutoscaler, got); diff != "" {
+				t.Errorf("NewAutoscalerConfigFromMap() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
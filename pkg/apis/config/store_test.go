@@ -38,7 +38,10 @@ func TestStoreLoadWithContext(t *testing.T) {
 
 	defaultsConfig := ConfigMapFromTestFile(t, DefaultsConfigName)
 
+	autoscalerConfig := ConfigMapFromTestFile(t, AutoscalerConfigName)
+
 	store.OnConfigChanged(defaultsConfig)
+	store.OnConfigChanged(autoscalerConfig)
 
 	config := FromContextOrDefaults(store.ToContext(context.Background()))
 
@@ -48,12 +51,20 @@ func TestStoreLoadWithContext(t *testing.T) {
 			t.Errorf("Unexpected defaults config (-want, +got): %v", diff)
 		}
 	})
+
+	t.Run("autoscaler", func(t *testing.T) {
+		expected, _ := NewAutoscalerConfigFromConfigMap(autoscalerConfig)
+		if diff := cmp.Diff(expected, config.Autoscaler); diff != "" {
+			t.Errorf("Unexpected autoscaler config (-want, +got): %v", diff)
+		}
+	})
 }
 
 func TestStoreLoadWithContextOrDefaults(t *testing.T) {
 	defer logtesting.ClearAll()
 
 	defaultsConfig := ConfigMapFromTestFile(t, DefaultsConfigName)
+	autoscalerConfig := ConfigMapFromTestFile(t, AutoscalerConfigName)
 	config := FromContextOrDefaults(context.Background())
 
 	t.Run("defaults", func(t *testing.T) {
@@ -62,6 +73,13 @@ func TestStoreLoadWithContextOrDefaults(t *testing.T) {
 			t.Errorf("Unexpected defaults config (-want, +got): %v", diff)
 		}
 	})
+
+	t.Run("autoscaler", func(t *testing.T) {
+		expected, _ := NewAutoscalerConfigFromConfigMap(autoscalerConfig)
+		if diff := cmp.Diff(expected, config.Autoscaler); diff != "" {
+			t.Errorf("Unexpected autoscaler config (-want, +got): %v", diff)
+		}
+	})
 }
 
 func TestStoreImmutableConfig(t *testing.T) {
@@ -69,14 +87,19 @@ func TestStoreImmutableConfig(t *testing.T) {
 	store := NewStore(logtesting.TestLogger(t))
 
 	store.OnConfigChanged(ConfigMapFromTestFile(t, DefaultsConfigName))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, AutoscalerConfigName))
 
 	config := store.Load()
 
 	config.Defaults.RevisionTimeoutSeconds = 1234
+	config.Autoscaler.StableWindow = 1234
 
 	newConfig := store.Load()
 
 	if newConfig.Defaults.RevisionTimeoutSeconds == 1234 {
 		t.Error("Defaults config is not immutable")
 	}
+	if newConfig.Autoscaler.StableWindow == 1234 {
+		t.Error("Autoscaler config is not immutable")
+	}
 }
@@ -100,6 +100,12 @@ type ServerlessServiceSpec struct {
 	// The application-layer protocol. Matches `RevisionProtocolType` set on the owning pa/revision.
 	// serving imports networking, so just use string.
 	ProtocolType networking.ProtocolType
+
+	// NumActivators is the number of activators the revision is assigned to,
+	// the only ones in its request path while the activator is. 0 assigns
+	// the revision to all the activators.
+	// +optional
+	NumActivators int32 `json:"numActivators,omitempty"`
 }
 
 // ServerlessServiceStatus describes the current state of the ServerlessService.
@@ -48,5 +48,9 @@ func (spec *ServerlessServiceSpec) Validate(ctx context.Context) *apis.FieldErro
 
 	all = all.Also(serving.ValidateNamespacedObjectReference(&spec.ObjectRef).ViaField("objectRef"))
 
+	if spec.NumActivators < 0 {
+		all = all.Also(apis.ErrInvalidValue(spec.NumActivators, "numActivators"))
+	}
+
 	return all.Also(spec.ProtocolType.Validate(ctx).ViaField("protocolType"))
 }
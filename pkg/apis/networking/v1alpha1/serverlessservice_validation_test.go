@@ -67,6 +67,32 @@ func TestServerlessServiceSpecValidation(t *testing.T) {
 			ProtocolType: networking.ProtocolType("gRPC"),
 		},
 		want: apis.ErrInvalidValue("gRPC", "protocolType"),
+	}, {
+		name: "valid number of activators",
+		skss: &ServerlessServiceSpec{
+			Mode: SKSOperationModeProxy,
+			ObjectRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "foo",
+			},
+			ProtocolType:  networking.ProtocolHTTP1,
+			NumActivators: 3,
+		},
+		want: nil,
+	}, {
+		name: "negative number of activators",
+		skss: &ServerlessServiceSpec{
+			Mode: SKSOperationModeProxy,
+			ObjectRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "foo",
+			},
+			ProtocolType:  networking.ProtocolHTTP1,
+			NumActivators: -1,
+		},
+		want: apis.ErrInvalidValue(int32(-1), "numActivators"),
 	}, {
 		name: "wrong mode",
 		skss: &ServerlessServiceSpec{
@@ -17,6 +17,7 @@ limitations under the License.
 package serving
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
@@ -99,7 +100,7 @@ func TestValidateObjectMetadata(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 
-			err := ValidateObjectMetadata(c.objectMeta)
+			err := ValidateObjectMetadata(context.Background(), c.objectMeta)
 
 			if !reflect.DeepEqual(c.expectErr, err) {
 				t.Errorf("Expected: '%#v', Got: '%#v'", c.expectErr, err)
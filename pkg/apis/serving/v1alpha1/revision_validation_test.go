@@ -238,6 +238,9 @@ func TestRevisionSpecValidation(t *testing.T) {
 					"revision-timeout-seconds":     "25",
 					"max-revision-timeout-seconds": "50"},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.AutoscalerConfigName},
+			})
 			return s.ToContext(ctx)
 		},
 		want: apis.ErrOutOfBoundsValue(100, 0, 50, "timeoutSeconds"),
@@ -538,6 +541,9 @@ func TestImmutableFields(t *testing.T) {
 					"revision-timeout-seconds":     "25",
 					"max-revision-timeout-seconds": "50"},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.AutoscalerConfigName},
+			})
 			return s.ToContext(ctx)
 		},
 		want: nil,
@@ -72,6 +72,9 @@ func TestRevisionDefaulting(t *testing.T) {
 					"revision-timeout-seconds": "123",
 				},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.AutoscalerConfigName},
+			})
 
 			return s.ToContext(ctx)
 		},
@@ -16,7 +16,10 @@ limitations under the License.
 
 package aggregation
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Accumulator is a function accumulating buckets and their time..
 type Accumulator func(time time.Time, bucket float64Bucket)
@@ -50,3 +53,80 @@ func (a *Average) Value() float64 {
 	}
 	return a.sum / a.count
 }
+
+// Averager accumulates buckets into a single average value.
+type Averager interface {
+	// Accumulate adds the bucket recorded at the given time.
+	Accumulate(time time.Time, bucket float64Bucket)
+	// Value returns the average of all accumulated buckets.
+	Value() float64
+}
+
+// EWMATimeConstantsPerWindow is the number of time constants of the
+// exponential decay that fit in the window. With three, a bucket as old as
+// the window weighs less than 5% of a fresh one.
+const EWMATimeConstantsPerWindow = 3
+
+// WeightedAverage is used to keep the values necessary to compute an average
+// in which each bucket is weighted by its age.
+type WeightedAverage struct {
+	now    time.Time
+	weight func(age time.Duration) float64
+
+	sum     float64
+	weights float64
+}
+
+// NewEWMA creates a WeightedAverage over a window ending at now, whose
+// weights decay exponentially with the age of the bucket.
+func NewEWMA(now time.Time, window time.Duration) *WeightedAverage {
+	tau := float64(window) / EWMATimeConstantsPerWindow
+	return &WeightedAverage{
+		now: now,
+		weight: func(age time.Duration) float64 {
+			if tau <= 0 {
+				return 1
+			}
+			return math.Exp(-float64(age) / tau)
+		},
+	}
+}
+
+// NewLinearDecay creates a WeightedAverage over a window ending at now, whose
+// weights decay linearly from one for a fresh bucket to zero for a bucket as
+// old as the window.
+func NewLinearDecay(now time.Time, window time.Duration) *WeightedAverage {
+	return &WeightedAverage{
+		now: now,
+		weight: func(age time.Duration) float64 {
+			if window <= 0 {
+				return 1
+			}
+			return math.Max(0, 1-float64(age)/float64(window))
+		},
+	}
+}
+
+// Accumulate accumulates the values needed to compute a weighted average.
+func (a *WeightedAverage) Accumulate(time time.Time, bucket float64Bucket) {
+	// Buckets recorded after now count as fresh.
+	w := a.weight(maxDuration(0, a.now.Sub(time)))
+	a.sum += w * bucket.Sum()
+	a.weights += w
+}
+
+// Value returns the weighted average. If no values were accumulated the
+// result is zero.
+func (a *WeightedAverage) Value() float64 {
+	if a.weights == 0 {
+		return 0
+	}
+	return a.sum / a.weights
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package aggregation
 
 import (
+	"math"
 	"testing"
 	"time"
 )
@@ -52,6 +53,87 @@ func TestAverage(t *testing.T) {
 	}
 }
 
+func TestWeightedAverage(t *testing.T) {
+	now := time.Now()
+
+	type value struct {
+		age   time.Duration
+		value float64
+	}
+	tests := []struct {
+		name   string
+		newAvg func(time.Time, time.Duration) *WeightedAverage
+		window time.Duration
+		values []value
+		want   float64
+	}{{
+		name:   "ewma empty",
+		newAvg: NewEWMA,
+		window: 30 * time.Second,
+		want:   0.0,
+	}, {
+		name:   "ewma equal values",
+		newAvg: NewEWMA,
+		window: 30 * time.Second,
+		values: []value{{0, 5.0}, {10 * time.Second, 5.0}, {20 * time.Second, 5.0}},
+		want:   5.0,
+	}, {
+		name:   "ewma one time constant apart",
+		newAvg: NewEWMA,
+		window: 30 * time.Second,
+		values: []value{{0, 10.0}, {10 * time.Second, 20.0}},
+		want:   (10.0 + 20.0/math.E) / (1 + 1/math.E),
+	}, {
+		name:   "ewma zero window",
+		newAvg: NewEWMA,
+		values: []value{{0, 10.0}, {10 * time.Second, 20.0}},
+		want:   15.0,
+	}, {
+		name:   "ewma future bucket counts as fresh",
+		newAvg: NewEWMA,
+		window: 30 * time.Second,
+		values: []value{{-time.Second, 10.0}, {10 * time.Second, 20.0}},
+		want:   (10.0 + 20.0/math.E) / (1 + 1/math.E),
+	}, {
+		name:   "linear decay empty",
+		newAvg: NewLinearDecay,
+		window: 10 * time.Second,
+		want:   0.0,
+	}, {
+		name:   "linear decay half way",
+		newAvg: NewLinearDecay,
+		window: 10 * time.Second,
+		values: []value{{0, 10.0}, {5 * time.Second, 20.0}},
+		want:   20.0 / 1.5,
+	}, {
+		name:   "linear decay drops the edge of the window",
+		newAvg: NewLinearDecay,
+		window: 10 * time.Second,
+		values: []value{{0, 10.0}, {10 * time.Second, 20.0}},
+		want:   10.0,
+	}, {
+		name:   "linear decay only the edge of the window",
+		newAvg: NewLinearDecay,
+		window: 10 * time.Second,
+		values: []value{{10 * time.Second, 20.0}},
+		want:   0.0,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			average := tt.newAvg(now, tt.window)
+			for _, v := range tt.values {
+				bucket := float64Bucket{}
+				bucket.Record("pod", v.value)
+				average.Accumulate(now.Add(-v.age), bucket)
+			}
+			if got := average.Value(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestYoungerThan(t *testing.T) {
 	t0 := time.Now()
 	t1 := t0.Add(1 * time.Second)
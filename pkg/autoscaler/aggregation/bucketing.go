@@ -17,6 +17,7 @@ limitations under the License.
 package aggregation
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
@@ -84,6 +85,57 @@ func (t *TimedFloat64Buckets) RemoveOlderThan(time time.Time) {
 	}
 }
 
+// Snapshot is the serializable state of TimedFloat64Buckets.
+type Snapshot struct {
+	Buckets []BucketSnapshot
+}
+
+// BucketSnapshot is the serializable state of a single bucket. Only the sum
+// of the bucket is kept, so that the size of a snapshot doesn't depend on
+// the number of pods.
+type BucketSnapshot struct {
+	Time  time.Time
+	Value float64
+}
+
+// restoredName is the name the value of a restored bucket is recorded as.
+const restoredName = ""
+
+// Snapshot returns a copy of the current state of the buckets, ordered
+// by time.
+func (t *TimedFloat64Buckets) Snapshot() Snapshot {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+
+	snapshot := Snapshot{Buckets: make([]BucketSnapshot, 0, len(t.buckets))}
+	for bucketTime, bucket := range t.buckets {
+		snapshot.Buckets = append(snapshot.Buckets, BucketSnapshot{Time: bucketTime, Value: bucket.Sum()})
+	}
+	sort.Slice(snapshot.Buckets, func(i, j int) bool {
+		return snapshot.Buckets[i].Time.Before(snapshot.Buckets[j].Time)
+	})
+	return snapshot
+}
+
+// Restore adds the buckets of the snapshot that are not older than the
+// given time to the current state. Buckets that have been collected since
+// are kept as they are.
+func (t *TimedFloat64Buckets) Restore(snapshot Snapshot, oldest time.Time) {
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+
+	for _, b := range snapshot.Buckets {
+		if b.Time.Before(oldest) {
+			continue
+		}
+		bucketKey := b.Time.Truncate(t.granularity)
+		if _, ok := t.buckets[bucketKey]; ok {
+			continue
+		}
+		t.buckets[bucketKey] = float64Bucket{restoredName: {sum: b.Value, count: 1}}
+	}
+}
+
 // float64Bucket keeps all the stats that fall into a defined bucket.
 type float64Bucket map[string]float64Value
 
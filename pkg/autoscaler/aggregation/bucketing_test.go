@@ -206,6 +206,86 @@ func TestTimedFloat64Buckets_RemoveOlderThan(t *testing.T) {
 	}
 }
 
+func TestTimedFloat64Buckets_SnapshotRestore(t *testing.T) {
+	granularity := 1 * time.Second
+	now := time.Now().Truncate(granularity)
+	buckets := NewTimedFloat64Buckets(granularity)
+
+	buckets.Record(now.Add(-3*time.Second), "pod1", 1.0)
+	buckets.Record(now.Add(-2*time.Second), "pod1", 2.0)
+	buckets.Record(now.Add(-2*time.Second), "pod1", 4.0)
+	buckets.Record(now, "pod2", 5.0)
+	buckets.Record(now, "pod3", 2.0)
+
+	snapshot := buckets.Snapshot()
+	want := Snapshot{Buckets: []BucketSnapshot{{
+		Time:  now.Add(-3 * time.Second),
+		Value: 1,
+	}, {
+		Time:  now.Add(-2 * time.Second),
+		Value: 3,
+	}, {
+		Time:  now,
+		Value: 7,
+	}}}
+	if diff := cmp.Diff(want, snapshot); diff != "" {
+		t.Errorf("Snapshot() differed from expected (-want, +got): %v", diff)
+	}
+
+	tests := []struct {
+		name      string
+		oldest    time.Time
+		collected map[time.Time]float64
+		want      map[time.Time]float64
+	}{{
+		name:   "everything",
+		oldest: now.Add(-3 * time.Second),
+		want: map[time.Time]float64{
+			now.Add(-3 * time.Second): 1,
+			now.Add(-2 * time.Second): 3,
+			now:                       7,
+		},
+	}, {
+		name:   "drop old",
+		oldest: now.Add(-2 * time.Second),
+		want: map[time.Time]float64{
+			now.Add(-2 * time.Second): 3,
+			now:                       7,
+		},
+	}, {
+		name:      "keep collected",
+		oldest:    now.Add(-3 * time.Second),
+		collected: map[time.Time]float64{now: 4},
+		want: map[time.Time]float64{
+			now.Add(-3 * time.Second): 1,
+			now.Add(-2 * time.Second): 3,
+			now:                       4,
+		},
+	}, {
+		name:   "drop all",
+		oldest: now.Add(time.Second),
+		want:   map[time.Time]float64{},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := NewTimedFloat64Buckets(granularity)
+			for k, v := range tt.collected {
+				restored.Record(k, "pod4", v)
+			}
+			restored.Restore(snapshot, tt.oldest)
+
+			got := make(map[time.Time]float64)
+			for k, v := range restored.buckets {
+				got[k] = v.Sum()
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected values (-want +got): %v", diff)
+			}
+		})
+	}
+}
+
 func TestFloat64Bucket(t *testing.T) {
 	tests := []struct {
 		name  string
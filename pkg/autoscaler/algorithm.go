@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/resources"
+)
+
+// AlgorithmFactory creates a UniScaler implementing a particular decider
+// algorithm for the given revision.
+type AlgorithmFactory func(
+	namespace string,
+	revision string,
+	metricClient MetricClient,
+	podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec,
+	reporter StatsReporter) (UniScaler, error)
+
+var (
+	algorithmsMux sync.RWMutex
+	algorithms    = make(map[string]AlgorithmFactory)
+)
+
+func init() {
+	RegisterAlgorithm(autoscaling.StablePanicAlgorithm, newStablePanic)
+	RegisterAlgorithm(autoscaling.EWMAAlgorithm, newEWMA)
+	RegisterAlgorithm(autoscaling.PIDAlgorithm, newPID)
+}
+
+// RegisterAlgorithm makes a decider algorithm available under the given name.
+// Registering a name twice replaces the previous factory.
+func RegisterAlgorithm(name string, factory AlgorithmFactory) {
+	algorithmsMux.Lock()
+	defer algorithmsMux.Unlock()
+	algorithms[name] = factory
+}
+
+// NewUniScaler creates a UniScaler using the decider algorithm named in the
+// DeciderSpec, falling back to the stable/panic algorithm if none is set.
+func NewUniScaler(
+	namespace string,
+	revision string,
+	metricClient MetricClient,
+	podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec,
+	reporter StatsReporter) (UniScaler, error) {
+	name := deciderSpec.Algorithm
+	if name == "" {
+		name = autoscaling.StablePanicAlgorithm
+	}
+
+	algorithmsMux.RLock()
+	factory, ok := algorithms[name]
+	algorithmsMux.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown decider algorithm %q", name)
+	}
+	return factory(namespace, revision, metricClient, podCounter, deciderSpec, reporter)
+}
+
+func newStablePanic(namespace, revision string, metricClient MetricClient, podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec, reporter StatsReporter) (UniScaler, error) {
+	// Return an untyped nil on error, rather than a nil *Autoscaler.
+	a, err := New(namespace, revision, metricClient, podCounter, deciderSpec, reporter)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func newEWMA(namespace, revision string, metricClient MetricClient, podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec, reporter StatsReporter) (UniScaler, error) {
+	e, err := NewEWMAScaler(namespace, revision, metricClient, podCounter, deciderSpec, reporter)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func newPID(namespace, revision string, metricClient MetricClient, podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec, reporter StatsReporter) (UniScaler, error) {
+	p, err := NewPIDScaler(namespace, revision, metricClient, podCounter, deciderSpec, reporter)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/resources"
+)
+
+func TestNewUniScaler(t *testing.T) {
+	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		want      UniScaler
+		wantErr   bool
+	}{{
+		name: "default",
+		want: &Autoscaler{},
+	}, {
+		name:      "stable-panic",
+		algorithm: autoscaling.StablePanicAlgorithm,
+		want:      &Autoscaler{},
+	}, {
+		name:      "ewma",
+		algorithm: autoscaling.EWMAAlgorithm,
+		want:      &EWMAScaler{},
+	}, {
+		name:      "pid",
+		algorithm: autoscaling.PIDAlgorithm,
+		want:      &PIDScaler{},
+	}, {
+		name:      "unknown",
+		algorithm: "magic",
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := DeciderSpec{
+				Algorithm:   test.algorithm,
+				TargetValue: 10,
+				ServiceName: testService,
+			}
+			got, err := NewUniScaler(testNamespace, testRevision, &testMetricClient{}, podCounter, spec, &mockReporter{})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewUniScaler() = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				if got != nil {
+					t.Errorf("NewUniScaler() = %v, want nil", got)
+				}
+				return
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(test.want) {
+				t.Errorf("NewUniScaler() = %T, want %T", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewUniScalerPropagatesErrors(t *testing.T) {
+	for _, algorithm := range []string{autoscaling.StablePanicAlgorithm, autoscaling.EWMAAlgorithm, autoscaling.PIDAlgorithm} {
+		t.Run(algorithm, func(t *testing.T) {
+			got, err := NewUniScaler(testNamespace, testRevision, &testMetricClient{}, nil,
+				DeciderSpec{Algorithm: algorithm, TargetValue: 10, ServiceName: testService}, &mockReporter{})
+			if err == nil {
+				t.Error("Expected error when ReadyPodCounter interface is nil, but got none.")
+			}
+			if got != nil {
+				t.Errorf("NewUniScaler() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	const name = "test-algorithm"
+	want := &fakeUniScaler{}
+	RegisterAlgorithm(name, func(string, string, MetricClient, resources.ReadyPodCounter, DeciderSpec, StatsReporter) (UniScaler, error) {
+		return want, nil
+	})
+	defer func() {
+		algorithmsMux.Lock()
+		defer algorithmsMux.Unlock()
+		delete(algorithms, name)
+	}()
+
+	got, err := NewUniScaler(testNamespace, testRevision, &testMetricClient{}, nil, DeciderSpec{Algorithm: name}, &mockReporter{})
+	if err != nil {
+		t.Fatalf("NewUniScaler() = %v", err)
+	}
+	if got != want {
+		t.Errorf("NewUniScaler() = %v, want %v", got, want)
+	}
+}
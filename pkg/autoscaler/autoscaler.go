@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/knative/pkg/logging"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/resources"
 	"go.uber.org/zap"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -42,10 +43,13 @@ type Autoscaler struct {
 	stateMux     sync.Mutex
 	panicTime    *time.Time
 	maxPanicPods int32
+	explanation  Explanation
 
 	// specMux guards the current DeciderSpec.
 	specMux     sync.RWMutex
 	deciderSpec DeciderSpec
+	// latencyTuner tunes the target of specs with a target latency.
+	latencyTuner latencyTuner
 }
 
 // New creates a new instance of autoscaler
@@ -91,44 +95,32 @@ func (a *Autoscaler) Update(deciderSpec DeciderSpec) error {
 func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (desiredPodCount int32, validScale bool) {
 	logger := logging.FromContext(ctx)
 
-	spec := a.currentSpec()
-	originalReadyPodsCount, err := a.podCounter.ReadyCount()
-	// If the error is NotFound, then presume 0.
-	if err != nil && !apierrors.IsNotFound(err) {
-		logger.Errorw("Failed to get Endpoints via K8S Lister", zap.Error(err))
+	metricKey := NewMetricKey(a.namespace, a.revision)
+	spec := a.latencyTuner.tune(logger, metricKey, a.currentSpec(), a.metricClient)
+	originalReadyPodsCount, observedStableValue, observedPanicValue, ok := observe(
+		logger, metricKey, spec, a.metricClient, a.podCounter)
+	if !ok {
 		return 0, false
 	}
 	// Use 1 if there are zero current pods.
 	readyPodsCount := math.Max(1, float64(originalReadyPodsCount))
 
-	metricKey := NewMetricKey(a.namespace, a.revision)
-	observedStableConcurrency, observedPanicConcurrency, err := a.metricClient.StableAndPanicConcurrency(metricKey)
-	if err != nil {
-		if err == ErrNoData {
-			logger.Debug("No data to scale on yet")
-		} else {
-			logger.Errorw("Failed to obtain metrics", zap.Error(err))
-		}
-		return 0, false
-	}
-
-	maxScaleUp := spec.MaxScaleUpRate * readyPodsCount
-	desiredStablePodCount := int32(math.Min(math.Ceil(observedStableConcurrency/spec.TargetConcurrency), maxScaleUp))
-	desiredPanicPodCount := int32(math.Min(math.Ceil(observedPanicConcurrency/spec.TargetConcurrency), maxScaleUp))
-
-	a.reporter.ReportStableRequestConcurrency(observedStableConcurrency)
-	a.reporter.ReportPanicRequestConcurrency(observedPanicConcurrency)
-	a.reporter.ReportTargetRequestConcurrency(spec.TargetConcurrency)
+	maxScaleUp, maxScaleDown := scaleLimits(spec, readyPodsCount)
+	desiredStablePodCount := int32(math.Min(math.Max(math.Ceil(observedStableValue/spec.TargetValue), maxScaleDown), maxScaleUp))
+	desiredPanicPodCount := int32(math.Min(math.Max(math.Ceil(observedPanicValue/spec.TargetValue), maxScaleDown), maxScaleUp))
 
-	logger.Debugf("STABLE: Observed average %0.3f concurrency, targeting %v.", observedStableConcurrency, spec.TargetConcurrency)
-	logger.Debugf("PANIC: Observed average %0.3f concurrency, targeting %v.", observedPanicConcurrency, spec.TargetConcurrency)
+	reportObserved(logger, a.reporter, spec, observedStableValue, observedPanicValue)
 
-	isOverPanicThreshold := observedPanicConcurrency/readyPodsCount >= spec.PanicThreshold
+	isOverPanicThreshold := observedPanicValue/readyPodsCount >= spec.PanicThreshold
+	observedQueueLength := observeQueueLength(logger, metricKey, spec, a.metricClient)
+	if spec.PanicQueueLength > 0 && observedQueueLength/readyPodsCount >= spec.PanicQueueLength {
+		isOverPanicThreshold = true
+	}
 
 	a.stateMux.Lock()
 	defer a.stateMux.Unlock()
 	if a.panicTime == nil && isOverPanicThreshold {
-		// Begin panicking when we cross the concurrency threshold in the panic window.
+		// Begin panicking when we cross the threshold in the panic window.
 		logger.Info("PANICKING")
 		a.panicTime = &now
 		a.reporter.ReportPanic(1)
@@ -153,13 +145,109 @@ func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (desiredPodCount
 		logger.Debug("Operating in stable mode.")
 		desiredPodCount = desiredStablePodCount
 	}
+	a.explanation = Explanation{
+		ReadyPods:           originalReadyPodsCount,
+		ObservedStableValue: observedStableValue,
+		ObservedPanicValue:  observedPanicValue,
+		TargetValue:         spec.TargetValue,
+		ObservedQueueLength: observedQueueLength,
+		Panicking:           a.panicTime != nil,
+	}
 
 	a.reporter.ReportDesiredPodCount(int64(desiredPodCount))
 	return desiredPodCount, true
 }
 
+// Explain returns the inputs of the latest proposal.
+func (a *Autoscaler) Explain() Explanation {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	return a.explanation
+}
+
 func (a *Autoscaler) currentSpec() DeciderSpec {
 	a.specMux.RLock()
 	defer a.specMux.RUnlock()
 	return a.deciderSpec
 }
+
+// observe returns the number of ready pods along with the stable and panic
+// values of the scaling metric for the given key. The returned boolean is
+// false if there is nothing to scale on.
+func observe(logger *zap.SugaredLogger, metricKey string, spec DeciderSpec,
+	metricClient MetricClient, podCounter resources.ReadyPodCounter) (int, float64, float64, bool) {
+	readyPodsCount, err := podCounter.ReadyCount()
+	// If the error is NotFound, then presume 0.
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Errorw("Failed to get Endpoints via K8S Lister", zap.Error(err))
+		return 0, 0, 0, false
+	}
+
+	var observedStableValue, observedPanicValue float64
+	switch spec.ScalingMetric {
+	case autoscaling.RPS:
+		observedStableValue, observedPanicValue, err = metricClient.StableAndPanicRPS(metricKey)
+	default:
+		observedStableValue, observedPanicValue, err = metricClient.StableAndPanicConcurrency(metricKey)
+	}
+	if err != nil {
+		if err == ErrNoData {
+			logger.Debug("No data to scale on yet")
+		} else {
+			logger.Errorw("Failed to obtain metrics", zap.Error(err))
+		}
+		return 0, 0, 0, false
+	}
+	return readyPodsCount, observedStableValue, observedPanicValue, true
+}
+
+// observeQueueLength returns the number of requests queued in the
+// queue-proxies over the panic window, or 0 if the decider does not take
+// queue pressure into account or there is no data.
+func observeQueueLength(logger *zap.SugaredLogger, metricKey string, spec DeciderSpec, metricClient MetricClient) float64 {
+	if spec.PanicQueueLength <= 0 {
+		return 0
+	}
+	_, panicQueueLength, err := metricClient.StableAndPanicQueueLength(metricKey)
+	if err != nil {
+		if err != ErrNoData {
+			logger.Errorw("Failed to obtain the queue length", zap.Error(err))
+		}
+		return 0
+	}
+	return panicQueueLength
+}
+
+// scaleLimits returns the largest and the smallest pod count the decider
+// may propose given the number of ready pods.
+func scaleLimits(spec DeciderSpec, readyPodsCount float64) (maxScaleUp, maxScaleDown float64) {
+	maxScaleUp = spec.MaxScaleUpRate * readyPodsCount
+	// A zero MaxScaleDownRate means the scale down is not rate limited.
+	if spec.MaxScaleDownRate > 0 {
+		maxScaleDown = math.Floor(readyPodsCount / spec.MaxScaleDownRate)
+	}
+	return maxScaleUp, maxScaleDown
+}
+
+// reportObserved reports and logs the observed stable and panic values of
+// the scaling metric along with the target.
+func reportObserved(logger *zap.SugaredLogger, reporter StatsReporter, spec DeciderSpec, observedStableValue, observedPanicValue float64) {
+	reporter.ReportMaxScaleDownRate(spec.MaxScaleDownRate)
+
+	switch spec.ScalingMetric {
+	case autoscaling.RPS:
+		reporter.ReportStableRPS(observedStableValue)
+		reporter.ReportPanicRPS(observedPanicValue)
+		reporter.ReportTargetRPS(spec.TargetValue)
+
+		logger.Debugf("STABLE: Observed average %0.3f rps, targeting %v.", observedStableValue, spec.TargetValue)
+		logger.Debugf("PANIC: Observed average %0.3f rps, targeting %v.", observedPanicValue, spec.TargetValue)
+	default:
+		reporter.ReportStableRequestConcurrency(observedStableValue)
+		reporter.ReportPanicRequestConcurrency(observedPanicValue)
+		reporter.ReportTargetRequestConcurrency(spec.TargetValue)
+
+		logger.Debugf("STABLE: Observed average %0.3f concurrency, targeting %v.", observedStableValue, spec.TargetValue)
+		logger.Debugf("PANIC: Observed average %0.3f concurrency, targeting %v.", observedPanicValue, spec.TargetValue)
+	}
+}
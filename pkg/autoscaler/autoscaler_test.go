@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/resources"
 
 	. "github.com/knative/pkg/logging/testing"
@@ -39,7 +40,7 @@ var (
 )
 
 func TestNewErrorWhenGivenNilReadyPodCounter(t *testing.T) {
-	_, err := New(testNamespace, testRevision, &testMetricClient{}, nil, DeciderSpec{TargetConcurrency: 10, ServiceName: testService}, &mockReporter{})
+	_, err := New(testNamespace, testRevision, &testMetricClient{}, nil, DeciderSpec{TargetValue: 10, ServiceName: testService}, &mockReporter{})
 	if err == nil {
 		t.Error("Expected error when ReadyPodCounter interface is nil, but got none.")
 	}
@@ -51,7 +52,7 @@ func TestNewErrorWhenGivenNilStatsReporter(t *testing.T) {
 	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
 
 	_, err := New(testNamespace, testRevision, &testMetricClient{}, podCounter,
-		DeciderSpec{TargetConcurrency: 10, ServiceName: testService}, reporter)
+		DeciderSpec{TargetValue: 10, ServiceName: testService}, reporter)
 	if err == nil {
 		t.Error("Expected error when EndpointsInformer interface is nil, but got none.")
 	}
@@ -154,6 +155,70 @@ func TestAutoscalerPanicThenUnPanicScaleDown(t *testing.T) {
 	a.expectScale(t, panicTime.Add(61*time.Second), 1, true)
 }
 
+func TestAutoscalerPanicOnQueueLength(t *testing.T) {
+	defer ClearAll()
+	metrics := &testMetricClient{stableConcurrency: 10.0, panicConcurrency: 10.0, queueLength: 5.0}
+	a := newTestAutoscaler(1.0, metrics)
+	a.deciderSpec.PanicQueueLength = 1.0
+	endpoints(10)
+
+	// Half a request queued per pod is below the threshold.
+	a.expectScale(t, time.Now(), 10, true)
+	if a.Explain().Panicking {
+		t.Error("Explain().Panicking = true, want false")
+	}
+
+	// Two requests queued per pod engage panic mode although the
+	// concurrency is on target.
+	metrics.queueLength = 20.0
+	a.expectScale(t, time.Now(), 10, true)
+	if got, want := a.Explain().ObservedQueueLength, 20.0; got != want {
+		t.Errorf("Explain().ObservedQueueLength = %v, want %v", got, want)
+	}
+	if !a.Explain().Panicking {
+		t.Error("Explain().Panicking = false, want true")
+	}
+
+	// No scale down while panicking.
+	metrics.stableConcurrency, metrics.panicConcurrency = 5.0, 5.0
+	a.expectScale(t, time.Now(), 10, true)
+}
+
+func TestAutoscalerTargetLatency(t *testing.T) {
+	defer ClearAll()
+	metrics := &testMetricClient{stableConcurrency: 100.0, panicConcurrency: 100.0, latency: 1}
+	a := newTestAutoscaler(10.0, metrics)
+	a.deciderSpec.TickInterval = stableWindow
+	a.deciderSpec.TargetLatency = 500 * time.Millisecond
+	a.deciderSpec.LatencyPercentile = 95
+	endpoints(10)
+
+	// The latency is twice the target, so is the number of pods.
+	a.expectScale(t, time.Now(), 20, true)
+	if got, want := a.Explain().TargetValue, 5.0; got != want {
+		t.Errorf("Explain().TargetValue = %v, want %v", got, want)
+	}
+}
+
+func TestAutoscalerExplain(t *testing.T) {
+	defer ClearAll()
+	metrics := &testMetricClient{stableConcurrency: 100.0, panicConcurrency: 100.0}
+	a := newTestAutoscaler(10.0, metrics)
+	endpoints(10)
+	a.expectScale(t, time.Now(), 10, true)
+	want := Explanation{ReadyPods: 10, ObservedStableValue: 100, ObservedPanicValue: 100, TargetValue: 10}
+	if got := a.Explain(); got != want {
+		t.Errorf("Explain() = %+v, want %+v", got, want)
+	}
+
+	metrics.panicConcurrency = 1000.0
+	a.expectScale(t, time.Now(), 100, true)
+	want = Explanation{ReadyPods: 10, ObservedStableValue: 100, ObservedPanicValue: 1000, TargetValue: 10, Panicking: true}
+	if got := a.Explain(); got != want {
+		t.Errorf("Explain() = %+v, want %+v", got, want)
+	}
+}
+
 func TestAutoscalerRateLimitScaleUp(t *testing.T) {
 	metrics := &testMetricClient{stableConcurrency: 1000.0}
 	a := newTestAutoscaler(10.0, metrics)
@@ -167,6 +232,36 @@ func TestAutoscalerRateLimitScaleUp(t *testing.T) {
 	a.expectScale(t, time.Now(), 100, true)
 }
 
+func TestAutoscalerRateLimitScaleDown(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 1.0}
+	a := newTestAutoscaler(10.0, metrics)
+	a.Update(DeciderSpec{
+		TargetValue:      10.0,
+		PanicThreshold:   20.0,
+		MaxScaleUpRate:   10.0,
+		MaxScaleDownRate: 2.0,
+		StableWindow:     stableWindow,
+		ServiceName:      testService,
+	})
+	endpoints(100)
+
+	// Need 1 pod but only scale down by half.
+	a.expectScale(t, time.Now(), 50, true)
+
+	endpoints(50)
+	// Halve again.
+	a.expectScale(t, time.Now(), 25, true)
+
+	endpoints(2)
+	// Reach the desired scale once it's within the rate.
+	a.expectScale(t, time.Now(), 1, true)
+
+	metrics.stableConcurrency = 0.0
+	endpoints(1)
+	// A single pod can always be scaled to zero.
+	a.expectScale(t, time.Now(), 0, true)
+}
+
 func TestAutoscalerUseOnePodAsMinimumIfEndpointsNotFound(t *testing.T) {
 	metrics := &testMetricClient{stableConcurrency: 1000.0}
 	a := newTestAutoscaler(10.0, metrics)
@@ -191,15 +286,34 @@ func TestAutoscalerUpdateTarget(t *testing.T) {
 	endpoints(10)
 
 	a.Update(DeciderSpec{
-		TargetConcurrency: 1.0,
-		PanicThreshold:    2.0,
-		MaxScaleUpRate:    10.0,
-		StableWindow:      stableWindow,
-		ServiceName:       testService,
+		TargetValue:    1.0,
+		PanicThreshold: 2.0,
+		MaxScaleUpRate: 10.0,
+		StableWindow:   stableWindow,
+		ServiceName:    testService,
 	})
 	a.expectScale(t, time.Now(), 100, true)
 }
 
+func TestAutoscalerStableModeRPS(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 1.0, stableRPS: 500.0}
+	a := newTestAutoscalerWithScalingMetric(100.0, autoscaling.RPS, metrics)
+	endpoints(1)
+	a.expectScale(t, time.Now(), 5, true)
+
+	metrics.stableRPS = 1000.0
+	a.expectScale(t, time.Now(), 10, true)
+}
+
+func TestAutoscalerPanicModeRPS(t *testing.T) {
+	metrics := &testMetricClient{stableRPS: 500.0, panicRPS: 1000.0}
+	a := newTestAutoscalerWithScalingMetric(100.0, autoscaling.RPS, metrics)
+	endpoints(1)
+
+	// Panic RPS takes precedence.
+	a.expectScale(t, time.Now(), 10, true)
+}
+
 type mockReporter struct{}
 
 // ReportDesiredPodCount of a mockReporter does nothing and return nil for error.
@@ -232,18 +346,43 @@ func (r *mockReporter) ReportTargetRequestConcurrency(v float64) error {
 	return nil
 }
 
+// ReportStableRPS of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportStableRPS(v float64) error {
+	return nil
+}
+
+// ReportPanicRPS of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportPanicRPS(v float64) error {
+	return nil
+}
+
+// ReportTargetRPS of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportTargetRPS(v float64) error {
+	return nil
+}
+
+// ReportMaxScaleDownRate of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportMaxScaleDownRate(v float64) error {
+	return nil
+}
+
 // ReportPanic of a mockReporter does nothing and return nil for error.
 func (r *mockReporter) ReportPanic(v int64) error {
 	return nil
 }
 
 func newTestAutoscaler(containerConcurrency int, metrics MetricClient) *Autoscaler {
+	return newTestAutoscalerWithScalingMetric(float64(containerConcurrency), autoscaling.Concurrency, metrics)
+}
+
+func newTestAutoscalerWithScalingMetric(target float64, metric string, metrics MetricClient) *Autoscaler {
 	deciderSpec := DeciderSpec{
-		TargetConcurrency: float64(containerConcurrency),
-		PanicThreshold:    2 * float64(containerConcurrency),
-		MaxScaleUpRate:    10.0,
-		StableWindow:      stableWindow,
-		ServiceName:       testService,
+		ScalingMetric:  metric,
+		TargetValue:    target,
+		PanicThreshold: 2 * target,
+		MaxScaleUpRate: 10.0,
+		StableWindow:   stableWindow,
+		ServiceName:    testService,
 	}
 
 	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, deciderSpec.ServiceName)
@@ -265,6 +404,11 @@ func (a *Autoscaler) expectScale(t *testing.T, now time.Time, expectScale int32,
 type testMetricClient struct {
 	stableConcurrency float64
 	panicConcurrency  float64
+	stableRPS         float64
+	panicRPS          float64
+	latency           float64
+	latencyErr        error
+	queueLength       float64
 	err               error
 }
 
@@ -272,6 +416,25 @@ func (t *testMetricClient) StableAndPanicConcurrency(key string) (float64, float
 	return t.stableConcurrency, t.panicConcurrency, t.err
 }
 
+func (t *testMetricClient) StableAndPanicRPS(key string) (float64, float64, error) {
+	return t.stableRPS, t.panicRPS, t.err
+}
+
+func (t *testMetricClient) StableLatency(key string, percentile int) (float64, error) {
+	if t.latencyErr != nil {
+		return 0, t.latencyErr
+	}
+	return t.latency, t.err
+}
+
+func (t *testMetricClient) StableAndPanicQueueLength(key string) (float64, float64, error) {
+	return t.queueLength, t.queueLength, t.err
+}
+
+func (t *testMetricClient) StableAndPanicProxiedRPS(key string) (float64, float64, error) {
+	return 0, 0, t.err
+}
+
 func endpoints(count int) {
 	epAddresses := make([]corev1.EndpointAddress, count)
 	for i := 0; i < count; i++ {
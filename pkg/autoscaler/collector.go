@@ -19,15 +19,20 @@ package autoscaler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/knative/serving/pkg/autoscaler/aggregation"
 
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	kpa "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"go.uber.org/zap"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 )
 
 const (
@@ -56,6 +61,9 @@ type Metric struct {
 type MetricSpec struct {
 	StableWindow time.Duration
 	PanicWindow  time.Duration
+	// Aggregation selects how the metric is averaged over the windows,
+	// i.e. average, ewma or linear-decay.
+	Aggregation string
 
 	// ScrapeTarget is the K8s service that is publishes the metric
 	// endpoint.
@@ -88,6 +96,38 @@ type Stat struct {
 
 	// Part of RequestCount, for requests going through a proxy.
 	ProxiedRequestCount float64
+
+	// The 50th, 95th and 99th percentile of the latency in seconds of the
+	// requests finished since last Stat, or 0 if not reported.
+	RequestLatencyP50 float64
+	RequestLatencyP95 float64
+	RequestLatencyP99 float64
+
+	// Average and maximum number of requests waiting for capacity in the
+	// queue since last Stat.
+	AverageQueueLength float64
+	MaxQueueLength     float64
+
+	// Average time in seconds the requests that got capacity since last
+	// Stat spent in the queue.
+	AverageQueuedTime float64
+}
+
+// LatencyPercentiles are the percentiles of the request latency reported
+// in a Stat.
+var LatencyPercentiles = []int{50, 95, 99}
+
+// requestLatency returns the latency at the given percentile, which is one
+// of LatencyPercentiles.
+func (s *Stat) requestLatency(percentile int) float64 {
+	switch percentile {
+	case 50:
+		return s.RequestLatencyP50
+	case 95:
+		return s.RequestLatencyP95
+	default:
+		return s.RequestLatencyP99
+	}
 }
 
 // StatMessage wraps a Stat with identifying information so it can be routed
@@ -101,31 +141,105 @@ type StatMessage struct {
 type MetricClient interface {
 	// StableAndPanicConcurrency returns both the stable and the panic concurrency.
 	StableAndPanicConcurrency(key string) (float64, float64, error)
+
+	// StableAndPanicRPS returns both the stable and the panic requests per second.
+	StableAndPanicRPS(key string) (float64, float64, error)
+
+	// StableLatency returns the request latency in seconds at the given
+	// percentile, one of LatencyPercentiles, over the stable window.
+	StableLatency(key string, percentile int) (float64, error)
+
+	// StableAndPanicQueueLength returns both the stable and the panic number
+	// of requests waiting for capacity in the queues.
+	StableAndPanicQueueLength(key string) (float64, float64, error)
+
+	// StableAndPanicProxiedRPS returns both the stable and the panic rate of
+	// the requests proxied by the activator.
+	StableAndPanicProxiedRPS(key string) (float64, float64, error)
+}
+
+// StatRecorder receives a copy of every stat the MetricCollector records,
+// whether scraped or received, e.g. to replay them in the simulator.
+type StatRecorder interface {
+	Write(*StatMessage) error
 }
 
+// recordQueueSize is the number of stats buffered for the StatRecorder. Stats
+// are dropped while the buffer is full, so a slow recorder never stalls the
+// stats ingestion.
+const recordQueueSize = 1024
+
 // MetricCollector manages collection of metrics for many entities.
 type MetricCollector struct {
 	logger *zap.SugaredLogger
+	clock  clock.Clock
+
+	// recordCh, if set, buffers a copy of every recorded stat for the
+	// StatRecorder.
+	recordCh chan *StatMessage
 
 	statsScraperFactory StatsScraperFactory
+	scheduler           *ScrapeScheduler
 
 	collections      map[string]*collection
 	collectionsMutex sync.RWMutex
+
+	// restored holds the snapshots of collections that have been restored
+	// but not been created yet, restoredTime when they were restored.
+	// Guarded by the collectionsMutex.
+	restored     map[string]CollectionSnapshot
+	restoredTime time.Time
 }
 
 var _ MetricClient = &MetricCollector{}
 
 // NewMetricCollector creates a new metric collector.
 func NewMetricCollector(statsScraperFactory StatsScraperFactory, logger *zap.SugaredLogger) *MetricCollector {
+	return NewMetricCollectorWithClock(statsScraperFactory, clock.RealClock{}, logger)
+}
+
+// NewMetricCollectorWithClock creates a new metric collector which scrapes
+// and computes the stable and panic windows relative to the given clock.
+func NewMetricCollectorWithClock(statsScraperFactory StatsScraperFactory, clock clock.Clock, logger *zap.SugaredLogger) *MetricCollector {
+	scheduler := NewScrapeScheduler(DefaultScrapeSchedulerOptions(), clock, logger)
+	return NewMetricCollectorWithScheduler(statsScraperFactory, scheduler, clock, logger)
+}
+
+// NewMetricCollectorWithScheduler creates a new metric collector which runs
+// its scrapes on the given scheduler.
+func NewMetricCollectorWithScheduler(statsScraperFactory StatsScraperFactory, scheduler *ScrapeScheduler,
+	clock clock.Clock, logger *zap.SugaredLogger) *MetricCollector {
 	collector := &MetricCollector{
 		logger:              logger,
+		clock:               clock,
 		collections:         make(map[string]*collection),
+		restored:            make(map[string]CollectionSnapshot),
 		statsScraperFactory: statsScraperFactory,
+		scheduler:           scheduler,
 	}
 
 	return collector
 }
 
+// RecordTo makes the collector write every stat it records to r, until stopCh
+// is closed. The stats are written asynchronously and dropped if r can't keep
+// up. It must be called before any Metric is created.
+func (c *MetricCollector) RecordTo(r StatRecorder, stopCh <-chan struct{}) {
+	c.recordCh = make(chan *StatMessage, recordQueueSize)
+	go func() {
+		for {
+			select {
+			case sm := <-c.recordCh:
+				if err := r.Write(sm); err != nil {
+					c.logger.Errorw("Failed to record stat message", zap.Error(err))
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
 // Get gets a Metric's state from the collector.
 // Returns a copy of the Metric object. Mutations won't be seen by the collector.
 func (c *MetricCollector) Get(ctx context.Context, namespace, name string) (*Metric, error) {
@@ -141,6 +255,25 @@ func (c *MetricCollector) Get(ctx context.Context, namespace, name string) (*Met
 	return collector.metric.DeepCopy(), nil
 }
 
+// List returns copies of all Metrics the collector collects, sorted by
+// their key.
+func (c *MetricCollector) List() []*Metric {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	keys := make([]string, 0, len(c.collections))
+	for key := range c.collections {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	metrics := make([]*Metric, 0, len(keys))
+	for _, key := range keys {
+		metrics = append(metrics, c.collections[key].currentMetric().DeepCopy())
+	}
+	return metrics
+}
+
 // Create creates a new metric and thus starts collection for that entity.
 // Returns a copy of the Metric object. Mutations won't be seen by the collector.
 func (c *MetricCollector) Create(ctx context.Context, metric *Metric) (*Metric, error) {
@@ -156,8 +289,13 @@ func (c *MetricCollector) Create(ctx context.Context, metric *Metric) (*Metric,
 		if err != nil {
 			return nil, err
 		}
-		coll = newCollection(metric, scraper, c.logger)
+		coll = newCollection(metric, scraper, c.recordCh, c.logger)
+		if snapshot, ok := c.restored[key]; ok {
+			coll.restore(snapshot, c.clock.Now())
+			delete(c.restored, key)
+		}
 		c.collections[key] = coll
+		c.scheduler.add(key, coll.scrape)
 	}
 
 	return coll.metric.DeepCopy(), nil
@@ -177,6 +315,8 @@ func (c *MetricCollector) Update(ctx context.Context, metric *Metric) (*Metric,
 		}
 		collection.updateScraper(scraper)
 		collection.updateMetric(metric)
+		// The new target might have pods, don't wait for the backoff.
+		c.scheduler.reset(key)
 		return metric.DeepCopy(), nil
 	}
 	return nil, k8serrors.NewNotFound(kpa.Resource("Metrics"), key)
@@ -185,15 +325,19 @@ func (c *MetricCollector) Update(ctx context.Context, metric *Metric) (*Metric,
 // Delete deletes a Metric and halts collection.
 func (c *MetricCollector) Delete(ctx context.Context, namespace, name string) error {
 	c.collectionsMutex.Lock()
-	defer c.collectionsMutex.Unlock()
 
 	c.logger.Debugf("Stopping metric collection of %s/%s", namespace, name)
 
 	key := NewMetricKey(namespace, name)
-	if collection, ok := c.collections[key]; ok {
-		collection.close()
+	wait := func() {}
+	if _, ok := c.collections[key]; ok {
+		wait = c.scheduler.removeAsync(key)
 		delete(c.collections, key)
 	}
+	c.collectionsMutex.Unlock()
+
+	// Running scrapes record into the collector, wait for them unlocked.
+	wait()
 	return nil
 }
 
@@ -209,12 +353,69 @@ func (c *MetricCollector) Record(key string, stat Stat) {
 
 // StableAndPanicConcurrency returns both the stable and the panic concurrency.
 func (c *MetricCollector) StableAndPanicConcurrency(key string) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, 0, k8serrors.NewNotFound(kpa.Resource("Metrics"), key)
+	}
+
+	return collection.stableAndPanicConcurrency(c.clock.Now())
+}
+
+// StableAndPanicRPS returns both the stable and the panic requests per second.
+func (c *MetricCollector) StableAndPanicRPS(key string) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, 0, k8serrors.NewNotFound(kpa.Resource("Metrics"), key)
+	}
+
+	return collection.stableAndPanicRPS(c.clock.Now())
+}
+
+// StableAndPanicQueueLength returns both the stable and the panic queue length.
+func (c *MetricCollector) StableAndPanicQueueLength(key string) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, 0, k8serrors.NewNotFound(kpa.Resource("Metrics"), key)
+	}
+
+	return collection.stableAndPanicQueueLength(c.clock.Now())
+}
+
+// StableAndPanicProxiedRPS returns both the stable and the panic rate of the
+// proxied requests.
+func (c *MetricCollector) StableAndPanicProxiedRPS(key string) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
 	collection, exists := c.collections[key]
 	if !exists {
 		return 0, 0, k8serrors.NewNotFound(kpa.Resource("Metrics"), key)
 	}
 
-	return collection.stableAndPanicConcurrency(time.Now())
+	return collection.stableAndPanicProxiedRPS(c.clock.Now())
+}
+
+// StableLatency returns the request latency in seconds at the given
+// percentile over the stable window.
+func (c *MetricCollector) StableLatency(key string, percentile int) (float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, k8serrors.NewNotFound(kpa.Resource("Metrics"), key)
+	}
+
+	return collection.stableLatency(percentile, c.clock.Now())
 }
 
 // collection represents the collection of metrics for one specific entity.
@@ -222,12 +423,30 @@ type collection struct {
 	metricMutex sync.RWMutex
 	metric      *Metric
 
-	scraperMutex sync.RWMutex
-	scraper      StatsScraper
-	buckets      *aggregation.TimedFloat64Buckets
+	scraperMutex       sync.RWMutex
+	scraper            StatsScraper
+	concurrencyBuckets *aggregation.TimedFloat64Buckets
+	rpsBuckets         *aggregation.TimedFloat64Buckets
+	proxiedRPSBuckets  *aggregation.TimedFloat64Buckets
+	queueLengthBuckets *aggregation.TimedFloat64Buckets
+	// latencyBuckets hold the latency at each of the LatencyPercentiles
+	// times the number of requests, latencyWeightBuckets the number of
+	// requests of the stats reporting a latency.
+	latencyBuckets       map[int]*aggregation.TimedFloat64Buckets
+	latencyWeightBuckets *aggregation.TimedFloat64Buckets
+
+	recordCh chan<- *StatMessage
+	logger   *zap.SugaredLogger
+
+	// failingPods are the comma separated names of the pods whose last
+	// scrape failed. Only accessed by scrape, which never runs concurrently.
+	failingPods string
+}
 
-	grp    sync.WaitGroup
-	stopCh chan struct{}
+// podFailureScraper is implemented by scrapers that scrape the pods
+// directly and track the pods whose scrapes fail.
+type podFailureScraper interface {
+	Failures() map[string]PodScrapeFailure
 }
 
 func (c *collection) updateScraper(ss StatsScraper) {
@@ -243,38 +462,67 @@ func (c *collection) getScraper() StatsScraper {
 }
 
 // newCollection creates a new collection.
-func newCollection(metric *Metric, scraper StatsScraper, logger *zap.SugaredLogger) *collection {
+func newCollection(metric *Metric, scraper StatsScraper, recordCh chan<- *StatMessage, logger *zap.SugaredLogger) *collection {
 	c := &collection{
-		metric:  metric,
-		buckets: aggregation.NewTimedFloat64Buckets(bucketSize),
-		scraper: scraper,
-
-		stopCh: make(chan struct{}),
+		metric:               metric,
+		concurrencyBuckets:   aggregation.NewTimedFloat64Buckets(bucketSize),
+		rpsBuckets:           aggregation.NewTimedFloat64Buckets(bucketSize),
+		proxiedRPSBuckets:    aggregation.NewTimedFloat64Buckets(bucketSize),
+		queueLengthBuckets:   aggregation.NewTimedFloat64Buckets(bucketSize),
+		latencyBuckets:       make(map[int]*aggregation.TimedFloat64Buckets, len(LatencyPercentiles)),
+		latencyWeightBuckets: aggregation.NewTimedFloat64Buckets(bucketSize),
+		scraper:              scraper,
+		recordCh:             recordCh,
+		logger:               logger,
+	}
+	for _, p := range LatencyPercentiles {
+		c.latencyBuckets[p] = aggregation.NewTimedFloat64Buckets(bucketSize)
 	}
+	return c
+}
 
-	c.grp.Add(1)
-	go func() {
-		defer c.grp.Done()
+// scrape scrapes the current scraper once and records the result. Returns
+// whether the scraper found any pods.
+func (c *collection) scrape() bool {
+	scraper := c.getScraper()
+	message, err := scraper.Scrape()
+	if err != nil {
+		c.logger.Errorw("Failed to scrape metrics", zap.Error(err))
+	}
+	if s, ok := scraper.(podFailureScraper); ok {
+		c.logFailingPods(s.Failures())
+	}
+	if message != nil {
+		c.record(message.Stat)
+	}
+	// Errors are not backed off, the pods are there but failed to answer.
+	return message != nil || err != nil
+}
 
-		scrapeTicker := time.NewTicker(scrapeTickInterval)
-		for {
-			select {
-			case <-c.stopCh:
-				scrapeTicker.Stop()
-				return
-			case <-scrapeTicker.C:
-				message, err := c.getScraper().Scrape()
-				if err != nil {
-					logger.Errorw("Failed to scrape metrics", zap.Error(err))
-				}
-				if message != nil {
-					c.record(message.Stat)
-				}
-			}
-		}
-	}()
+// logFailingPods warns about the pods whose scrapes fail whenever they
+// change, so a broken pod is noticed even while the others still answer.
+func (c *collection) logFailingPods(failures map[string]PodScrapeFailure) {
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pods := strings.Join(names, ",")
+	if pods == c.failingPods {
+		return
+	}
+	c.failingPods = pods
 
-	return c
+	if len(names) == 0 {
+		c.logger.Info("Scrapes of all pods succeed again")
+		return
+	}
+	details := make([]string, len(names))
+	for i, name := range names {
+		f := failures[name]
+		details[i] = fmt.Sprintf("%s: %d failures since %s, last: %s", name, f.Count, f.Since.Format(time.RFC3339), f.LastError)
+	}
+	c.logger.Warnw("Failed to scrape pods", zap.Strings("pods", details))
 }
 
 // updateMetric safely updates the metric stored in the collection.
@@ -295,25 +543,58 @@ func (c *collection) currentMetric() *Metric {
 
 // record adds a stat to the current collection.
 func (c *collection) record(stat Stat) {
+	if c.recordCh != nil {
+		metric := c.currentMetric()
+		select {
+		case c.recordCh <- &StatMessage{Key: NewMetricKey(metric.Namespace, metric.Name), Stat: stat}:
+		default:
+			// The recorder is falling behind, drop the stat rather than block.
+		}
+	}
+
 	// Proxied requests have been counted at the activator. Subtract
-	// AverageProxiedConcurrentRequests to avoid double counting.
-	c.buckets.Record(*stat.Time, stat.PodName, stat.AverageConcurrentRequests-stat.AverageProxiedConcurrentRequests)
+	// AverageProxiedConcurrentRequests and ProxiedRequestCount to avoid
+	// double counting.
+	c.concurrencyBuckets.Record(*stat.Time, stat.PodName, stat.AverageConcurrentRequests-stat.AverageProxiedConcurrentRequests)
+	c.rpsBuckets.Record(*stat.Time, stat.PodName, stat.RequestCount-stat.ProxiedRequestCount)
+	c.proxiedRPSBuckets.Record(*stat.Time, stat.PodName, stat.ProxiedRequestCount)
+	c.queueLengthBuckets.Record(*stat.Time, stat.PodName, stat.AverageQueueLength)
+
+	// Only the queue-proxies report the latency, weigh it by the number of
+	// requests it was measured on.
+	if stat.RequestCount > 0 && stat.RequestLatencyP50 > 0 {
+		for p, buckets := range c.latencyBuckets {
+			buckets.Record(*stat.Time, stat.PodName, stat.requestLatency(p)*stat.RequestCount)
+		}
+		c.latencyWeightBuckets.Record(*stat.Time, stat.PodName, stat.RequestCount)
+	}
 }
 
 // stableAndPanicConcurrency calculates both stable and panic concurrency based on the
 // current stats.
 func (c *collection) stableAndPanicConcurrency(now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(c.concurrencyBuckets, now)
+}
+
+// stableAndPanicRPS calculates both stable and panic requests per second based on the
+// current stats.
+func (c *collection) stableAndPanicRPS(now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(c.rpsBuckets, now)
+}
+
+// stableAndPanic calculates both the stable and the panic average of the given buckets.
+func (c *collection) stableAndPanic(buckets *aggregation.TimedFloat64Buckets, now time.Time) (float64, float64, error) {
 	spec := c.currentMetric().Spec
 
-	c.buckets.RemoveOlderThan(now.Add(-spec.StableWindow))
+	buckets.RemoveOlderThan(now.Add(-spec.StableWindow))
 
-	if c.buckets.IsEmpty() {
+	if buckets.IsEmpty() {
 		return 0, 0, ErrNoData
 	}
 
-	panicAverage := aggregation.Average{}
-	stableAverage := aggregation.Average{}
-	c.buckets.ForEachBucket(
+	panicAverage := newAverager(spec.Aggregation, now, spec.PanicWindow)
+	stableAverage := newAverager(spec.Aggregation, now, spec.StableWindow)
+	buckets.ForEachBucket(
 		aggregation.YoungerThan(now.Add(-spec.PanicWindow), panicAverage.Accumulate),
 		stableAverage.Accumulate, // No need to add a YoungerThan condition as we already deleted all outdated stats above.
 	)
@@ -321,8 +602,64 @@ func (c *collection) stableAndPanicConcurrency(now time.Time) (float64, float64,
 	return stableAverage.Value(), panicAverage.Value(), nil
 }
 
-// close stops collecting metrics, stops the scraper.
-func (c *collection) close() {
-	close(c.stopCh)
-	c.grp.Wait()
+// stableAndPanicProxiedRPS calculates both the stable and the panic rate of
+// the proxied requests based on the current stats.
+func (c *collection) stableAndPanicProxiedRPS(now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(c.proxiedRPSBuckets, now)
+}
+
+// stableAndPanicQueueLength calculates both the stable and the panic queue
+// length based on the current stats.
+func (c *collection) stableAndPanicQueueLength(now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(c.queueLengthBuckets, now)
+}
+
+// stableLatency calculates the request weighted average of the latency at
+// the given percentile over the stable window.
+func (c *collection) stableLatency(percentile int, now time.Time) (float64, error) {
+	buckets, ok := c.latencyBuckets[percentile]
+	if !ok {
+		return 0, fmt.Errorf("unsupported latency percentile %d", percentile)
+	}
+
+	oldest := now.Add(-c.currentMetric().Spec.StableWindow)
+	buckets.RemoveOlderThan(oldest)
+	c.latencyWeightBuckets.RemoveOlderThan(oldest)
+
+	var latency, weight aggregation.Average
+	buckets.ForEachBucket(latency.Accumulate)
+	c.latencyWeightBuckets.ForEachBucket(weight.Accumulate)
+	if weight.Value() == 0 {
+		return 0, ErrNoData
+	}
+	return latency.Value() / weight.Value(), nil
+}
+
+// newAverager creates the Averager for the named aggregation over a window
+// ending at now.
+func newAverager(kind string, now time.Time, window time.Duration) aggregation.Averager {
+	switch kind {
+	case autoscaling.EWMAAggregation:
+		return aggregation.NewEWMA(now, window)
+	case autoscaling.LinearDecayAggregation:
+		return aggregation.NewLinearDecay(now, window)
+	default:
+		return &aggregation.Average{}
+	}
+}
+
+// snapshot returns a copy of the collected data.
+func (c *collection) snapshot() CollectionSnapshot {
+	return CollectionSnapshot{
+		Concurrency: c.concurrencyBuckets.Snapshot(),
+		RPS:         c.rpsBuckets.Snapshot(),
+	}
+}
+
+// restore merges the data of the snapshot that still falls into the stable
+// window into the collection.
+func (c *collection) restore(snapshot CollectionSnapshot, now time.Time) {
+	oldest := now.Add(-c.currentMetric().Spec.StableWindow)
+	c.concurrencyBuckets.Restore(snapshot.Concurrency, oldest)
+	c.rpsBuckets.Restore(snapshot.RPS, oldest)
 }
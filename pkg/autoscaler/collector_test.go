@@ -19,14 +19,19 @@ package autoscaler
 import (
 	"context"
 	"errors"
+	"math"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/autoscaler/aggregation"
 
 	. "github.com/knative/pkg/logging/testing"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -120,10 +125,16 @@ func TestMetricCollectorCRUD(t *testing.T) {
 		if got, want := newURL, "slightly-off"; got != want {
 			t.Errorf("Updated scraper URL = %s, want: %s, diff: %s", got, want, cmp.Diff(got, want))
 		}
+		if got := coll.List(); len(got) != 1 || !cmp.Equal(defaultMetric, got[0]) {
+			t.Errorf("List() = %v, want only %v", got, defaultMetric)
+		}
 
 		if err := coll.Delete(ctx, defaultNamespace, defaultName); err != nil {
 			t.Errorf("Delete() = %v, want no error", err)
 		}
+		if got := coll.List(); len(got) != 0 {
+			t.Errorf("List() = %v, want no metrics", got)
+		}
 	})
 }
 
@@ -171,6 +182,51 @@ func TestMetricCollectorScraper(t *testing.T) {
 	}
 }
 
+func TestMetricCollectorDeleteDuringScrape(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	var once sync.Once
+	var coll *MetricCollector
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			once.Do(func() {
+				close(started)
+				<-proceed
+				// Takes the lock of the collector while it is deleting.
+				coll.Get(ctx, defaultNamespace, defaultName)
+			})
+			return nil, nil
+		},
+	}
+
+	coll = NewMetricCollector(scraperFactory(scraper, nil), logger)
+	coll.Create(ctx, defaultMetric)
+	<-started
+
+	deleted := make(chan struct{})
+	go func() {
+		defer close(deleted)
+		coll.Delete(ctx, defaultNamespace, defaultName)
+	}()
+	// The collection is gone before the running scrape finished.
+	wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		_, err := coll.Get(ctx, defaultNamespace, defaultName)
+		return k8serrors.IsNotFound(err), nil
+	})
+	close(proceed)
+
+	select {
+	case <-deleted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Delete() didn't return after the scrape finished")
+	}
+}
+
 func TestMetricCollectorRecord(t *testing.T) {
 	defer ClearAll()
 
@@ -208,6 +264,371 @@ func TestMetricCollectorRecord(t *testing.T) {
 	}
 }
 
+func TestMetricCollectorRecordToSlowRecorder(t *testing.T) {
+	defer ClearAll()
+
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	coll := NewMetricCollector(scraperFactory(scraper, nil), TestLogger(t))
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	recorder := &blockingRecorder{unblock: make(chan struct{}), written: make(chan *StatMessage, 1)}
+	coll.RecordTo(recorder, stopCh)
+	coll.Create(context.Background(), defaultMetric)
+
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	now := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// More stats than the buffer holds, while the recorder is stuck.
+		for i := 0; i < 2*recordQueueSize; i++ {
+			coll.Record(metricKey, Stat{Time: &now, PodName: "testPod", AverageConcurrentRequests: 1})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Record() blocked on the slow recorder")
+	}
+	if _, _, err := coll.StableAndPanicConcurrency(metricKey); err != nil {
+		t.Errorf("StableAndPanicConcurrency() = %v, want the stats to be recorded", err)
+	}
+
+	close(recorder.unblock)
+	select {
+	case sm := <-recorder.written:
+		if sm.Key != metricKey {
+			t.Errorf("Recorded key = %s, want: %s", sm.Key, metricKey)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The buffered stats were never written")
+	}
+}
+
+// blockingRecorder blocks writes until unblock is closed.
+type blockingRecorder struct {
+	unblock chan struct{}
+	written chan *StatMessage
+}
+
+func (r *blockingRecorder) Write(sm *StatMessage) error {
+	<-r.unblock
+	select {
+	case r.written <- sm:
+	default:
+	}
+	return nil
+}
+
+func TestMetricCollectorRecordRPS(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	want := 10.0
+	stat := Stat{
+		Time:                &now,
+		PodName:             "testPod",
+		RequestCount:        want + 5,
+		ProxiedRequestCount: 5, // this should be subtracted from the above.
+	}
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	factory := scraperFactory(scraper, nil)
+
+	coll := NewMetricCollector(factory, logger)
+
+	// Freshly created collection does not contain any metrics and should return an error.
+	coll.Create(ctx, defaultMetric)
+	if _, _, err := coll.StableAndPanicRPS(metricKey); err == nil {
+		t.Error("StableAndPanicRPS() = nil, wanted an error")
+	}
+
+	// After adding a stat the requests per second are calculated correctly.
+	coll.Record(metricKey, stat)
+	if stable, panic, err := coll.StableAndPanicRPS(metricKey); stable != want || panic != want || err != nil {
+		t.Errorf("StableAndPanicRPS() = %v, %v, %v; want %v, %v, nil", stable, panic, err, want, want)
+	}
+
+	coll.Delete(ctx, defaultNamespace, defaultName)
+	if _, _, err := coll.StableAndPanicRPS(metricKey); !k8serrors.IsNotFound(err) {
+		t.Errorf("StableAndPanicRPS() = %v, want a not found error", err)
+	}
+}
+
+func TestMetricCollectorRecordProxiedRPS(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	factory := scraperFactory(scraper, nil)
+
+	coll := NewMetricCollector(factory, logger)
+
+	coll.Create(ctx, defaultMetric)
+	if _, _, err := coll.StableAndPanicProxiedRPS(metricKey); err == nil {
+		t.Error("StableAndPanicProxiedRPS() = nil, wanted an error")
+	}
+
+	coll.Record(metricKey, Stat{
+		Time:                &now,
+		PodName:             "testPod",
+		RequestCount:        15,
+		ProxiedRequestCount: 5,
+	})
+	if stable, panic, err := coll.StableAndPanicProxiedRPS(metricKey); stable != 5 || panic != 5 || err != nil {
+		t.Errorf("StableAndPanicProxiedRPS() = %v, %v, %v; want 5, 5, nil", stable, panic, err)
+	}
+
+	coll.Delete(ctx, defaultNamespace, defaultName)
+	if _, _, err := coll.StableAndPanicProxiedRPS(metricKey); !k8serrors.IsNotFound(err) {
+		t.Errorf("StableAndPanicProxiedRPS() = %v, want a not found error", err)
+	}
+}
+
+func TestMetricCollectorRecordQueueLength(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	factory := scraperFactory(scraper, nil)
+
+	coll := NewMetricCollector(factory, logger)
+
+	coll.Create(ctx, defaultMetric)
+	if _, _, err := coll.StableAndPanicQueueLength(metricKey); err == nil {
+		t.Error("StableAndPanicQueueLength() = nil, wanted an error")
+	}
+
+	coll.Record(metricKey, Stat{
+		Time:               &now,
+		PodName:            "testPod",
+		RequestCount:       10,
+		AverageQueueLength: 3,
+		MaxQueueLength:     5,
+	})
+	if stable, panic, err := coll.StableAndPanicQueueLength(metricKey); stable != 3 || panic != 3 || err != nil {
+		t.Errorf("StableAndPanicQueueLength() = %v, %v, %v; want 3, 3, nil", stable, panic, err)
+	}
+
+	coll.Delete(ctx, defaultNamespace, defaultName)
+	if _, _, err := coll.StableAndPanicQueueLength(metricKey); !k8serrors.IsNotFound(err) {
+		t.Errorf("StableAndPanicQueueLength() = %v, want a not found error", err)
+	}
+}
+
+func TestMetricCollectorRecordLatency(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	factory := scraperFactory(scraper, nil)
+
+	coll := NewMetricCollector(factory, logger)
+	coll.Create(ctx, defaultMetric)
+
+	// Stats without a latency, e.g. of the activator, don't count.
+	coll.Record(metricKey, Stat{
+		Time:         &now,
+		PodName:      "activator",
+		RequestCount: 100,
+	})
+	if _, err := coll.StableLatency(metricKey, 95); err != ErrNoData {
+		t.Errorf("StableLatency() = %v, want %v", err, ErrNoData)
+	}
+
+	// The latencies are weighed by the number of requests.
+	coll.Record(metricKey, Stat{
+		Time:              &now,
+		PodName:           "pod-1",
+		RequestCount:      30,
+		RequestLatencyP50: 0.1,
+		RequestLatencyP95: 0.2,
+		RequestLatencyP99: 0.3,
+	})
+	coll.Record(metricKey, Stat{
+		Time:              &now,
+		PodName:           "pod-2",
+		RequestCount:      10,
+		RequestLatencyP50: 0.5,
+		RequestLatencyP95: 0.6,
+		RequestLatencyP99: 0.7,
+	})
+	for percentile, want := range map[int]float64{50: 0.2, 95: 0.3, 99: 0.4} {
+		if got, err := coll.StableLatency(metricKey, percentile); err != nil || math.Abs(got-want) > 1e-9 {
+			t.Errorf("StableLatency(%d) = %v, %v; want %v, nil", percentile, got, err, want)
+		}
+	}
+
+	if _, err := coll.StableLatency(metricKey, 90); err == nil {
+		t.Error("StableLatency(90) = nil, wanted an error")
+	}
+
+	coll.Delete(ctx, defaultNamespace, defaultName)
+	if _, err := coll.StableLatency(metricKey, 95); !k8serrors.IsNotFound(err) {
+		t.Errorf("StableLatency() = %v, want a not found error", err)
+	}
+}
+
+func TestMetricCollectorClock(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	clk := clock.NewFakeClock(now)
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	coll := NewMetricCollectorWithClock(scraperFactory(scraper, nil), clk, logger)
+	coll.Create(ctx, defaultMetric)
+	defer coll.Delete(ctx, defaultNamespace, defaultName)
+
+	coll.Record(metricKey, Stat{Time: &now, PodName: "testPod", AverageConcurrentRequests: 10})
+	if stable, panic, err := coll.StableAndPanicConcurrency(metricKey); stable != 10 || panic != 10 || err != nil {
+		t.Errorf("StableAndPanicConcurrency() = %v, %v, %v; want %v, %v, nil", stable, panic, err, 10, 10)
+	}
+
+	// The stat falls out of the windows as the clock moves on.
+	clk.Step(defaultMetric.Spec.StableWindow + bucketSize)
+	if _, _, err := coll.StableAndPanicConcurrency(metricKey); err != ErrNoData {
+		t.Errorf("StableAndPanicConcurrency() = %v, want %v", err, ErrNoData)
+	}
+}
+
+func TestCollectionAggregation(t *testing.T) {
+	// Align to the bucket size so the bucket times are exact.
+	now := time.Now().Truncate(time.Minute)
+	old := now.Add(-30 * time.Second)
+
+	tests := []struct {
+		name        string
+		aggregation string
+		wantStable  float64
+	}{{
+		name:       "default",
+		wantStable: 15.0,
+	}, {
+		name:        "average",
+		aggregation: autoscaling.AverageAggregation,
+		wantStable:  15.0,
+	}, {
+		name:        "ewma",
+		aggregation: autoscaling.EWMAAggregation,
+		// 30s is one and a half time constants of a 60s window.
+		wantStable: (10.0 + 20.0*math.Exp(-1.5)) / (1 + math.Exp(-1.5)),
+	}, {
+		name:        "linear decay",
+		aggregation: autoscaling.LinearDecayAggregation,
+		// 30s is half of the 60s window.
+		wantStable: (10.0 + 20.0*0.5) / 1.5,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := defaultMetric.DeepCopy()
+			metric.Spec.Aggregation = test.aggregation
+			c := &collection{
+				metric:             metric,
+				concurrencyBuckets: aggregation.NewTimedFloat64Buckets(bucketSize),
+				rpsBuckets:         aggregation.NewTimedFloat64Buckets(bucketSize),
+				proxiedRPSBuckets:  aggregation.NewTimedFloat64Buckets(bucketSize),
+				queueLengthBuckets: aggregation.NewTimedFloat64Buckets(bucketSize),
+			}
+			c.record(Stat{Time: &old, PodName: "testPod", AverageConcurrentRequests: 20.0})
+			c.record(Stat{Time: &now, PodName: "testPod", AverageConcurrentRequests: 10.0})
+
+			stable, panic, err := c.stableAndPanicConcurrency(now)
+			if err != nil {
+				t.Fatalf("stableAndPanicConcurrency() = %v", err)
+			}
+			if math.Abs(stable-test.wantStable) > 1e-9 {
+				t.Errorf("stable = %v, want %v", stable, test.wantStable)
+			}
+			// Only the fresh bucket is within the panic window.
+			if panic != 10.0 {
+				t.Errorf("panic = %v, want %v", panic, 10.0)
+			}
+		})
+	}
+}
+
+func TestCollectionTracksFailingPods(t *testing.T) {
+	scraper := &failingPodsScraper{}
+	c := newCollection(defaultMetric, scraper, nil, TestLogger(t))
+
+	steps := []struct {
+		failures map[string]PodScrapeFailure
+		want     string
+	}{{
+		failures: map[string]PodScrapeFailure{"pod-b": {Count: 1}, "pod-a": {Count: 1}},
+		want:     "pod-a,pod-b",
+	}, {
+		failures: map[string]PodScrapeFailure{"pod-b": {Count: 2}},
+		want:     "pod-b",
+	}, {
+		want: "",
+	}}
+	for _, step := range steps {
+		scraper.failures = step.failures
+		c.scrape()
+		if c.failingPods != step.want {
+			t.Errorf("failingPods = %q, want %q", c.failingPods, step.want)
+		}
+	}
+}
+
+type failingPodsScraper struct {
+	testScraper
+	failures map[string]PodScrapeFailure
+}
+
+func (s *failingPodsScraper) Scrape() (*StatMessage, error) {
+	return nil, nil
+}
+
+func (s *failingPodsScraper) Failures() map[string]PodScrapeFailure {
+	return s.failures
+}
+
 func scraperFactory(scraper StatsScraper, err error) StatsScraperFactory {
 	return func(*Metric) (StatsScraper, error) {
 		return scraper, err
@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -35,21 +36,46 @@ const (
 type Config struct {
 	// Feature flags.
 	EnableScaleToZero bool
+	// AllowZeroInitialScale allows new revisions to start without pods.
+	AllowZeroInitialScale bool
 
 	// Target concurrency knobs for different container concurrency configurations.
 	ContainerConcurrencyTargetFraction float64
 	ContainerConcurrencyTargetDefault  float64
 
+	// Target requests per second knob, used when scaling on the rps metric.
+	RPSTargetDefault float64
+
+	// Bounds of the target concurrency tuned to meet a target latency.
+	LatencyTunedTargetMin float64
+	LatencyTunedTargetMax float64
+
 	// General autoscaler algorithm configuration.
 	MaxScaleUpRate           float64
+	MaxScaleDownRate         float64
 	StableWindow             time.Duration
 	PanicWindowPercentage    float64
 	PanicThresholdPercentage float64
+	// PanicQueueLengthThreshold is the number of requests queued per pod
+	// in the queue-proxy at which to enter panic mode, 0 disables it.
+	PanicQueueLengthThreshold float64
+	// TargetBurstCapacity is the spare capacity below which the activator
+	// stays in the request path, 0 disables it and -1 keeps it there always.
+	TargetBurstCapacity float64
+	// ActivatorCapacity is the number of requests one activator is expected
+	// to handle for a revision, to size the subset of activators assigned to it.
+	ActivatorCapacity float64
+	// Aggregation selects how the metric is averaged over a window,
+	// i.e. average, ewma or linear-decay.
+	Aggregation string
 	// Deprecated in favor of PanicWindowPercentage.
 	PanicWindow  time.Duration
 	TickInterval time.Duration
 
 	ScaleToZeroGracePeriod time.Duration
+
+	// InitialScale is the number of pods a new revision starts with.
+	InitialScale int32
 }
 
 // NewConfigFromMap creates a Config from the supplied map
@@ -65,6 +91,10 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		key:          "enable-scale-to-zero",
 		field:        &lc.EnableScaleToZero,
 		defaultValue: true,
+	}, {
+		key:          "allow-zero-initial-scale",
+		field:        &lc.AllowZeroInitialScale,
+		defaultValue: false,
 	}} {
 		if raw, ok := data[b.key]; !ok {
 			*b.field = b.defaultValue
@@ -83,6 +113,10 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		key:          "max-scale-up-rate",
 		field:        &lc.MaxScaleUpRate,
 		defaultValue: 10.0,
+	}, {
+		key:          "max-scale-down-rate",
+		field:        &lc.MaxScaleDownRate,
+		defaultValue: 2.0,
 	}, {
 		key:   "container-concurrency-target-percentage",
 		field: &lc.ContainerConcurrencyTargetFraction,
@@ -93,6 +127,18 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		key:          "container-concurrency-target-default",
 		field:        &lc.ContainerConcurrencyTargetDefault,
 		defaultValue: 100.0,
+	}, {
+		key:          "requests-per-second-target-default",
+		field:        &lc.RPSTargetDefault,
+		defaultValue: 200.0,
+	}, {
+		key:          "latency-tuned-target-min",
+		field:        &lc.LatencyTunedTargetMin,
+		defaultValue: 1.0,
+	}, {
+		key:          "latency-tuned-target-max",
+		field:        &lc.LatencyTunedTargetMax,
+		defaultValue: 100.0,
 	}, {
 		key:          "panic-window-percentage",
 		field:        &lc.PanicWindowPercentage,
@@ -101,6 +147,18 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		key:          "panic-threshold-percentage",
 		field:        &lc.PanicThresholdPercentage,
 		defaultValue: 200.0,
+	}, {
+		key:          "panic-queue-length-threshold",
+		field:        &lc.PanicQueueLengthThreshold,
+		defaultValue: 0.0,
+	}, {
+		key:          "target-burst-capacity",
+		field:        &lc.TargetBurstCapacity,
+		defaultValue: 0.0,
+	}, {
+		key:          "activator-capacity",
+		field:        &lc.ActivatorCapacity,
+		defaultValue: 100.0,
 	}} {
 		if raw, ok := data[f64.key]; !ok {
 			*f64.field = f64.defaultValue
@@ -115,6 +173,35 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		return nil, fmt.Errorf("container-concurrency-target-percentage = %f is outside of valid range of (0, 100]", lc.ContainerConcurrencyTargetFraction)
 	}
 
+	if lc.MaxScaleDownRate <= 1.0 {
+		return nil, fmt.Errorf("max-scale-down-rate = %f, must be greater than 1.0", lc.MaxScaleDownRate)
+	}
+
+	if lc.RPSTargetDefault <= 0 {
+		return nil, fmt.Errorf("requests-per-second-target-default must be greater than 0, got %f", lc.RPSTargetDefault)
+	}
+
+	if lc.PanicQueueLengthThreshold < 0 {
+		return nil, fmt.Errorf("panic-queue-length-threshold must be greater than or equal to 0, got %f", lc.PanicQueueLengthThreshold)
+	}
+
+	if lc.TargetBurstCapacity < 0 && lc.TargetBurstCapacity != -1 {
+		return nil, fmt.Errorf("target-burst-capacity must be -1 or greater than or equal to 0, got %f", lc.TargetBurstCapacity)
+	}
+
+	if lc.ActivatorCapacity < 1 {
+		return nil, fmt.Errorf("activator-capacity must be at least 1, got %f", lc.ActivatorCapacity)
+	}
+
+	if lc.LatencyTunedTargetMin <= 0 {
+		return nil, fmt.Errorf("latency-tuned-target-min must be greater than 0, got %f", lc.LatencyTunedTargetMin)
+	}
+
+	if lc.LatencyTunedTargetMax < lc.LatencyTunedTargetMin {
+		return nil, fmt.Errorf("latency-tuned-target-max = %f is less than latency-tuned-target-min = %f",
+			lc.LatencyTunedTargetMax, lc.LatencyTunedTargetMin)
+	}
+
 	// Adjust % ⇒ fractions: for legacy reasons we allow values
 	// (0, 1] interval, so minimal percentage must be greater than 1.0.
 	// Internally we want to have fractions, since otherwise we'll have
@@ -154,6 +241,29 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		}
 	}
 
+	lc.InitialScale = 1
+	if raw, ok := data["initial-scale"]; ok {
+		val, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		lc.InitialScale = int32(val)
+	}
+	if lc.InitialScale < 0 || lc.InitialScale == 0 && !lc.AllowZeroInitialScale {
+		return nil, fmt.Errorf("initial-scale = %d, must be greater than 0, or 0 with allow-zero-initial-scale", lc.InitialScale)
+	}
+
+	lc.Aggregation = autoscaling.AverageAggregation
+	if raw, ok := data["aggregation"]; ok {
+		switch raw {
+		case autoscaling.AverageAggregation, autoscaling.EWMAAggregation, autoscaling.LinearDecayAggregation:
+			lc.Aggregation = raw
+		default:
+			return nil, fmt.Errorf("aggregation = %q, must be one of %q, %q or %q", raw,
+				autoscaling.AverageAggregation, autoscaling.EWMAAggregation, autoscaling.LinearDecayAggregation)
+		}
+	}
+
 	if lc.ScaleToZeroGracePeriod < 30*time.Second {
 		return nil, fmt.Errorf("scale-to-zero-grace-period must be at least 30s, got %v", lc.ScaleToZeroGracePeriod)
 	}
@@ -161,6 +271,27 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 	return lc, nil
 }
 
+// InitialScaleFor returns the number of pods a new revision with the given
+// annotations starts with. It's within the minScale and maxScale annotations,
+// and only 0 when the config allows revisions to start without pods.
+func (c *Config) InitialScaleFor(annotations map[string]string) int32 {
+	initialScale := c.InitialScale
+	// no error check: relying on validation
+	if i, err := strconv.ParseInt(annotations[autoscaling.InitialScaleAnnotationKey], 10, 32); err == nil && i >= 0 {
+		initialScale = int32(i)
+	}
+	if initialScale == 0 && !(c.AllowZeroInitialScale && c.EnableScaleToZero) {
+		initialScale = 1
+	}
+	if min, err := strconv.ParseInt(annotations[autoscaling.MinScaleAnnotationKey], 10, 32); err == nil && int32(min) > initialScale {
+		initialScale = int32(min)
+	}
+	if max, err := strconv.ParseInt(annotations[autoscaling.MaxScaleAnnotationKey], 10, 32); err == nil && max > 0 && int32(max) < initialScale {
+		initialScale = int32(max)
+	}
+	return initialScale
+}
+
 // NewConfigFromConfigMap creates a Config from the supplied ConfigMap
 func NewConfigFromConfigMap(configMap *corev1.ConfigMap) (*Config, error) {
 	return NewConfigFromMap(configMap.Data)
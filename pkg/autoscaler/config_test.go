@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	corev1 "k8s.io/api/core/v1"
 
 	. "github.com/knative/pkg/configmap/testing"
@@ -48,13 +49,20 @@ func TestNewConfig(t *testing.T) {
 			EnableScaleToZero:                  true,
 			ContainerConcurrencyTargetFraction: 0.5,
 			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
 			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
 			StableWindow:                       5 * time.Minute,
 			PanicWindow:                        10 * time.Second,
 			ScaleToZeroGracePeriod:             30 * time.Second,
 			TickInterval:                       2 * time.Second,
 			PanicWindowPercentage:              10.0,
 			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
 		},
 	}, {
 		name: "concurrencty target percentage as percent",
@@ -73,13 +81,20 @@ func TestNewConfig(t *testing.T) {
 			EnableScaleToZero:                  true,
 			ContainerConcurrencyTargetFraction: 0.5,
 			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
 			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
 			StableWindow:                       5 * time.Minute,
 			PanicWindow:                        10 * time.Second,
 			ScaleToZeroGracePeriod:             30 * time.Second,
 			TickInterval:                       2 * time.Second,
 			PanicWindowPercentage:              10.0,
 			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
 		},
 	}, {
 		name: "with toggles on",
@@ -98,13 +113,20 @@ func TestNewConfig(t *testing.T) {
 			EnableScaleToZero:                  true,
 			ContainerConcurrencyTargetFraction: 0.5,
 			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
 			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
 			StableWindow:                       5 * time.Minute,
 			PanicWindow:                        10 * time.Second,
 			ScaleToZeroGracePeriod:             30 * time.Second,
 			TickInterval:                       2 * time.Second,
 			PanicWindowPercentage:              10.0,
 			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
 		},
 	}, {
 		name: "with toggles on strange casing",
@@ -123,13 +145,20 @@ func TestNewConfig(t *testing.T) {
 			EnableScaleToZero:                  true,
 			ContainerConcurrencyTargetFraction: 0.5,
 			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
 			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
 			StableWindow:                       5 * time.Minute,
 			PanicWindow:                        10 * time.Second,
 			ScaleToZeroGracePeriod:             30 * time.Second,
 			TickInterval:                       2 * time.Second,
 			PanicWindowPercentage:              10.0,
 			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
 		},
 	}, {
 		name: "with toggles explicitly off",
@@ -147,13 +176,20 @@ func TestNewConfig(t *testing.T) {
 		want: &Config{
 			ContainerConcurrencyTargetFraction: 0.5,
 			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
 			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
 			StableWindow:                       5 * time.Minute,
 			PanicWindow:                        10 * time.Second,
 			ScaleToZeroGracePeriod:             30 * time.Second,
 			TickInterval:                       2 * time.Second,
 			PanicWindowPercentage:              10.0,
 			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
 		},
 	}, {
 		name: "with explicit grace period",
@@ -172,14 +208,160 @@ func TestNewConfig(t *testing.T) {
 		want: &Config{
 			ContainerConcurrencyTargetFraction: 0.5,
 			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
 			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
 			StableWindow:                       5 * time.Minute,
 			PanicWindow:                        10 * time.Second,
 			ScaleToZeroGracePeriod:             30 * time.Second,
 			TickInterval:                       2 * time.Second,
 			PanicWindowPercentage:              10.0,
 			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
 		},
+	}, {
+		name: "with explicit rps target",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"requests-per-second-target-default":      "150",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"panic-window-percentage":                 "10",
+			"panic-threshold-percentage":              "200",
+		},
+		want: &Config{
+			EnableScaleToZero:                  true,
+			ContainerConcurrencyTargetFraction: 0.5,
+			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   150.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
+			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
+			StableWindow:                       5 * time.Minute,
+			PanicWindow:                        10 * time.Second,
+			ScaleToZeroGracePeriod:             30 * time.Second,
+			TickInterval:                       2 * time.Second,
+			PanicWindowPercentage:              10.0,
+			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
+		},
+	}, {
+		name: "with explicit aggregation",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"panic-window-percentage":                 "10",
+			"panic-threshold-percentage":              "200",
+			"aggregation":                             "linear-decay",
+		},
+		want: &Config{
+			EnableScaleToZero:                  true,
+			ContainerConcurrencyTargetFraction: 0.5,
+			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
+			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
+			StableWindow:                       5 * time.Minute,
+			PanicWindow:                        10 * time.Second,
+			ScaleToZeroGracePeriod:             30 * time.Second,
+			TickInterval:                       2 * time.Second,
+			PanicWindowPercentage:              10.0,
+			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.LinearDecayAggregation,
+			InitialScale:                       1,
+		},
+	}, {
+		name: "with explicit panic queue length threshold",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"panic-window-percentage":                 "10",
+			"panic-threshold-percentage":              "200",
+			"panic-queue-length-threshold":            "1.5",
+		},
+		want: &Config{
+			EnableScaleToZero:                  true,
+			ContainerConcurrencyTargetFraction: 0.5,
+			ContainerConcurrencyTargetDefault:  10.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
+			MaxScaleUpRate:                     1.0,
+			MaxScaleDownRate:                   2.0,
+			StableWindow:                       5 * time.Minute,
+			PanicWindow:                        10 * time.Second,
+			ScaleToZeroGracePeriod:             30 * time.Second,
+			TickInterval:                       2 * time.Second,
+			PanicWindowPercentage:              10.0,
+			PanicThresholdPercentage:           200.0,
+			PanicQueueLengthThreshold:          1.5,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
+		},
+	}, {
+		name: "invalid aggregation",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"panic-window-percentage":                 "10",
+			"panic-threshold-percentage":              "200",
+			"aggregation":                             "median",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid rps target",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"requests-per-second-target-default":      "0",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"panic-window-percentage":                 "10",
+			"panic-threshold-percentage":              "200",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid max scale down rate",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"max-scale-down-rate":                     "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"panic-window-percentage":                 "10",
+			"panic-threshold-percentage":              "200",
+		},
+		wantErr: true,
 	}, {
 		name: "malformed float",
 		input: map[string]string{
@@ -232,6 +414,100 @@ func TestNewConfig(t *testing.T) {
 			"panic-threshold-percentage":              "200",
 		},
 		wantErr: true,
+	}, {
+		name: "negative panic queue length threshold",
+		input: map[string]string{
+			"panic-queue-length-threshold": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "target burst capacity always in path",
+		input: map[string]string{
+			"target-burst-capacity": "-1",
+		},
+		want: &Config{
+			EnableScaleToZero:                  true,
+			ContainerConcurrencyTargetFraction: 1.0,
+			ContainerConcurrencyTargetDefault:  100.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
+			MaxScaleUpRate:                     10.0,
+			MaxScaleDownRate:                   2.0,
+			StableWindow:                       60 * time.Second,
+			PanicWindow:                        6 * time.Second,
+			ScaleToZeroGracePeriod:             30 * time.Second,
+			TickInterval:                       2 * time.Second,
+			PanicWindowPercentage:              10.0,
+			PanicThresholdPercentage:           200.0,
+			TargetBurstCapacity:                -1,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+			InitialScale:                       1,
+		},
+	}, {
+		name: "invalid target burst capacity",
+		input: map[string]string{
+			"target-burst-capacity": "-2",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid activator capacity",
+		input: map[string]string{
+			"activator-capacity": "0.5",
+		},
+		wantErr: true,
+	}, {
+		name: "with zero initial scale",
+		input: map[string]string{
+			"allow-zero-initial-scale": "true",
+			"initial-scale":            "0",
+		},
+		want: &Config{
+			EnableScaleToZero:                  true,
+			AllowZeroInitialScale:              true,
+			ContainerConcurrencyTargetFraction: 1.0,
+			ContainerConcurrencyTargetDefault:  100.0,
+			RPSTargetDefault:                   200.0,
+			LatencyTunedTargetMin:              1.0,
+			LatencyTunedTargetMax:              100.0,
+			MaxScaleUpRate:                     10.0,
+			MaxScaleDownRate:                   2.0,
+			StableWindow:                       60 * time.Second,
+			PanicWindow:                        6 * time.Second,
+			ScaleToZeroGracePeriod:             30 * time.Second,
+			TickInterval:                       2 * time.Second,
+			PanicWindowPercentage:              10.0,
+			PanicThresholdPercentage:           200.0,
+			ActivatorCapacity:                  100.0,
+			Aggregation:                        autoscaling.AverageAggregation,
+		},
+	}, {
+		name: "zero initial scale not allowed",
+		input: map[string]string{
+			"initial-scale": "0",
+		},
+		wantErr: true,
+	}, {
+		name: "negative initial scale",
+		input: map[string]string{
+			"allow-zero-initial-scale": "true",
+			"initial-scale":            "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "latency tuned target min is 0",
+		input: map[string]string{
+			"latency-tuned-target-min": "0",
+		},
+		wantErr: true,
+	}, {
+		name: "latency tuned target max less than min",
+		input: map[string]string{
+			"latency-tuned-target-min": "10",
+			"latency-tuned-target-max": "5",
+		},
+		wantErr: true,
 	}}
 
 	for _, test := range tests {
@@ -258,3 +534,73 @@ func TestOurConfig(t *testing.T) {
 		t.Errorf("NewConfigFromConfigMap(example) = %v", err)
 	}
 }
+
+func TestInitialScaleFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *Config
+		annotations map[string]string
+		want        int32
+	}{{
+		name:   "default",
+		config: &Config{InitialScale: 1},
+		want:   1,
+	}, {
+		name:   "configured",
+		config: &Config{InitialScale: 3},
+		want:   3,
+	}, {
+		name:   "annotation",
+		config: &Config{InitialScale: 3},
+		annotations: map[string]string{
+			autoscaling.InitialScaleAnnotationKey: "5",
+		},
+		want: 5,
+	}, {
+		name:   "below min scale",
+		config: &Config{InitialScale: 3},
+		annotations: map[string]string{
+			autoscaling.InitialScaleAnnotationKey: "2",
+			autoscaling.MinScaleAnnotationKey:     "4",
+		},
+		want: 4,
+	}, {
+		name:   "above max scale",
+		config: &Config{InitialScale: 3},
+		annotations: map[string]string{
+			autoscaling.InitialScaleAnnotationKey: "10",
+			autoscaling.MaxScaleAnnotationKey:     "6",
+		},
+		want: 6,
+	}, {
+		name: "zero allowed",
+		config: &Config{
+			EnableScaleToZero:     true,
+			AllowZeroInitialScale: true,
+			InitialScale:          1,
+		},
+		annotations: map[string]string{
+			autoscaling.InitialScaleAnnotationKey: "0",
+		},
+		want: 0,
+	}, {
+		name:   "zero not allowed",
+		config: &Config{EnableScaleToZero: true, InitialScale: 1},
+		annotations: map[string]string{
+			autoscaling.InitialScaleAnnotationKey: "0",
+		},
+		want: 1,
+	}, {
+		name:   "zero without scale to zero",
+		config: &Config{AllowZeroInitialScale: true},
+		want:   1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.config.InitialScaleFor(test.annotations); got != test.want {
+				t.Errorf("InitialScaleFor() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DebugPath is the path the DebugHandler is conventionally served under.
+const DebugPath = "/debug/deciders"
+
+// DeciderLister lists the current Deciders.
+type DeciderLister interface {
+	List() []*Decider
+}
+
+// deciderDebug is the JSON representation of a Decider's latest decision.
+type deciderDebug struct {
+	Namespace           string     `json:"namespace"`
+	Name                string     `json:"name"`
+	Algorithm           string     `json:"algorithm,omitempty"`
+	ScalingMetric       string     `json:"scalingMetric,omitempty"`
+	TargetValue         float64    `json:"targetValue"`
+	PanicThreshold      float64    `json:"panicThreshold"`
+	TargetLatency       string     `json:"targetLatency,omitempty"`
+	TunedTargetValue    float64    `json:"tunedTargetValue,omitempty"`
+	ReadyPods           int        `json:"readyPods"`
+	ObservedStableValue float64    `json:"observedStableValue"`
+	ObservedPanicValue  float64    `json:"observedPanicValue"`
+	Panicking           bool       `json:"panicking"`
+	DesiredScale        int32      `json:"desiredScale"`
+	LastDecisionTime    *time.Time `json:"lastDecisionTime,omitempty"`
+	LastTrafficTime     *time.Time `json:"lastTrafficTime,omitempty"`
+}
+
+// NewDebugHandler returns a handler serving the inputs and the outputs of
+// the latest decision of every Decider as a JSON list. The optional
+// namespace and name query parameters narrow the list down.
+func NewDebugHandler(lister DeciderLister, logger *zap.SugaredLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace, name := r.URL.Query().Get("namespace"), r.URL.Query().Get("name")
+
+		deciders := []deciderDebug{}
+		for _, d := range lister.List() {
+			if (namespace != "" && d.Namespace != namespace) || (name != "" && d.Name != name) {
+				continue
+			}
+			debug := deciderDebug{
+				Namespace:           d.Namespace,
+				Name:                d.Name,
+				Algorithm:           d.Spec.Algorithm,
+				ScalingMetric:       d.Spec.ScalingMetric,
+				TargetValue:         d.Spec.TargetValue,
+				PanicThreshold:      d.Spec.PanicThreshold,
+				ReadyPods:           d.Status.Explanation.ReadyPods,
+				ObservedStableValue: d.Status.Explanation.ObservedStableValue,
+				ObservedPanicValue:  d.Status.Explanation.ObservedPanicValue,
+				Panicking:           d.Status.Explanation.Panicking,
+				DesiredScale:        d.Status.DesiredScale,
+			}
+			if d.Spec.TargetLatency > 0 {
+				debug.TargetLatency = fmt.Sprintf("p%d<%v", d.Spec.LatencyPercentile, d.Spec.TargetLatency)
+				debug.TunedTargetValue = d.Status.Explanation.TargetValue
+			}
+			if !d.Status.LastDecisionTime.IsZero() {
+				debug.LastDecisionTime = &d.Status.LastDecisionTime.Time
+			}
+			if !d.Status.LastTrafficTime.IsZero() {
+				debug.LastTrafficTime = &d.Status.LastTrafficTime.Time
+			}
+			deciders = append(deciders, debug)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(deciders); err != nil {
+			logger.Errorw("Failed to write the deciders", zap.Error(err))
+		}
+	})
+}
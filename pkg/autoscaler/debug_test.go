@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/knative/pkg/logging/testing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeDeciderLister []*Decider
+
+func (l fakeDeciderLister) List() []*Decider {
+	return l
+}
+
+func TestDebugHandler(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	lister := fakeDeciderLister{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "decided"},
+		Spec: DeciderSpec{
+			Algorithm:      "stable-panic",
+			ScalingMetric:  "concurrency",
+			TargetValue:    10,
+			PanicThreshold: 20,
+		},
+		Status: DeciderStatus{
+			DesiredScale: 3,
+			Explanation: Explanation{
+				ReadyPods:           2,
+				ObservedStableValue: 25,
+				ObservedPanicValue:  45,
+				Panicking:           true,
+			},
+			LastDecisionTime: metav1.NewTime(now),
+			LastTrafficTime:  metav1.NewTime(now),
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "undecided"},
+		Spec:       DeciderSpec{TargetValue: 100},
+		Status:     DeciderStatus{DesiredScale: -1},
+	}}
+	decided := deciderDebug{
+		Namespace:           "ns1",
+		Name:                "decided",
+		Algorithm:           "stable-panic",
+		ScalingMetric:       "concurrency",
+		TargetValue:         10,
+		PanicThreshold:      20,
+		ReadyPods:           2,
+		ObservedStableValue: 25,
+		ObservedPanicValue:  45,
+		Panicking:           true,
+		DesiredScale:        3,
+		LastDecisionTime:    &now,
+		LastTrafficTime:     &now,
+	}
+	undecided := deciderDebug{
+		Namespace:    "ns2",
+		Name:         "undecided",
+		TargetValue:  100,
+		DesiredScale: -1,
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []deciderDebug
+	}{{
+		name: "all",
+		want: []deciderDebug{decided, undecided},
+	}, {
+		name:  "by namespace",
+		query: "?namespace=ns2",
+		want:  []deciderDebug{undecided},
+	}, {
+		name:  "by namespace and name",
+		query: "?namespace=ns1&name=decided",
+		want:  []deciderDebug{decided},
+	}, {
+		name:  "none",
+		query: "?name=missing",
+		want:  []deciderDebug{},
+	}}
+
+	handler := NewDebugHandler(lister, TestLogger(t))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugPath+test.query, nil))
+
+			if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+				t.Errorf("Content-Type = %q, want %q", got, want)
+			}
+			var got []deciderDebug
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() = %v", err)
+			}
+			if !cmp.Equal(test.want, got) {
+				t.Errorf("Deciders differ (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/knative/pkg/logging"
+	"github.com/knative/serving/pkg/autoscaler/aggregation"
+	"github.com/knative/serving/pkg/resources"
+)
+
+// EWMAScaler proposes a scale from an exponentially weighted moving average
+// of the metric observed over the panic window. Unlike the stable/panic
+// Autoscaler it has no panic mode: the average itself reacts to surges.
+type EWMAScaler struct {
+	namespace    string
+	revision     string
+	metricClient MetricClient
+	podCounter   resources.ReadyPodCounter
+	reporter     StatsReporter
+
+	// State carried over multiple Scale calls. Guarded by the stateMux.
+	stateMux    sync.Mutex
+	average     float64
+	lastTime    time.Time
+	explanation Explanation
+
+	// specMux guards the current DeciderSpec.
+	specMux     sync.RWMutex
+	deciderSpec DeciderSpec
+	// latencyTuner tunes the target of specs with a target latency.
+	latencyTuner latencyTuner
+}
+
+// NewEWMAScaler creates a new instance of the EWMA decider.
+func NewEWMAScaler(
+	namespace string,
+	revision string,
+	metricClient MetricClient,
+	podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec,
+	reporter StatsReporter) (*EWMAScaler, error) {
+	if podCounter == nil {
+		return nil, errors.New("'podCounter' must not be nil")
+	}
+	if reporter == nil {
+		return nil, errors.New("stats reporter must not be nil")
+	}
+
+	// This decider never panics.
+	reporter.ReportPanic(0)
+
+	return &EWMAScaler{
+		namespace:    namespace,
+		revision:     revision,
+		metricClient: metricClient,
+		podCounter:   podCounter,
+		deciderSpec:  deciderSpec,
+		reporter:     reporter,
+	}, nil
+}
+
+// Update reconfigures the UniScaler according to the DeciderSpec.
+func (e *EWMAScaler) Update(deciderSpec DeciderSpec) error {
+	e.specMux.Lock()
+	defer e.specMux.Unlock()
+
+	e.deciderSpec = deciderSpec
+	return nil
+}
+
+// Scale folds the latest panic window observation into the moving average
+// and proposes enough pods to serve the average at the target value.
+func (e *EWMAScaler) Scale(ctx context.Context, now time.Time) (int32, bool) {
+	logger := logging.FromContext(ctx)
+
+	metricKey := NewMetricKey(e.namespace, e.revision)
+	spec := e.latencyTuner.tune(logger, metricKey, e.currentSpec(), e.metricClient)
+	originalReadyPodsCount, observedStableValue, observedPanicValue, ok := observe(
+		logger, metricKey, spec, e.metricClient, e.podCounter)
+	if !ok {
+		return 0, false
+	}
+	readyPodsCount := math.Max(1, float64(originalReadyPodsCount))
+	reportObserved(logger, e.reporter, spec, observedStableValue, observedPanicValue)
+
+	e.stateMux.Lock()
+	defer e.stateMux.Unlock()
+	switch {
+	case observedStableValue == 0:
+		// The average only approaches zero asymptotically, so snap to it
+		// once the whole stable window saw no traffic to allow scale to zero.
+		e.average = 0
+	case e.lastTime.IsZero() || spec.StableWindow <= 0:
+		e.average = observedPanicValue
+	default:
+		tau := spec.StableWindow.Seconds() / aggregation.EWMATimeConstantsPerWindow
+		alpha := 1 - math.Exp(-now.Sub(e.lastTime).Seconds()/tau)
+		e.average += alpha * (observedPanicValue - e.average)
+	}
+	e.lastTime = now
+	e.explanation = Explanation{
+		ReadyPods:           originalReadyPodsCount,
+		ObservedStableValue: observedStableValue,
+		ObservedPanicValue:  observedPanicValue,
+		TargetValue:         spec.TargetValue,
+	}
+	logger.Debugf("EWMA: Average %0.3f, targeting %v.", e.average, spec.TargetValue)
+
+	maxScaleUp, maxScaleDown := scaleLimits(spec, readyPodsCount)
+	desiredPodCount := int32(math.Min(math.Max(math.Ceil(e.average/spec.TargetValue), maxScaleDown), maxScaleUp))
+
+	e.reporter.ReportDesiredPodCount(int64(desiredPodCount))
+	return desiredPodCount, true
+}
+
+// Explain returns the inputs of the latest proposal.
+func (e *EWMAScaler) Explain() Explanation {
+	e.stateMux.Lock()
+	defer e.stateMux.Unlock()
+	return e.explanation
+}
+
+func (e *EWMAScaler) currentSpec() DeciderSpec {
+	e.specMux.RLock()
+	defer e.specMux.RUnlock()
+	return e.deciderSpec
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/knative/pkg/logging/testing"
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/autoscaler/aggregation"
+	"github.com/knative/serving/pkg/resources"
+)
+
+func TestNewEWMAScalerErrors(t *testing.T) {
+	if _, err := NewEWMAScaler(testNamespace, testRevision, &testMetricClient{}, nil,
+		DeciderSpec{TargetValue: 10, ServiceName: testService}, &mockReporter{}); err == nil {
+		t.Error("Expected error when ReadyPodCounter interface is nil, but got none.")
+	}
+
+	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
+	if _, err := NewEWMAScaler(testNamespace, testRevision, &testMetricClient{}, podCounter,
+		DeciderSpec{TargetValue: 10, ServiceName: testService}, nil); err == nil {
+		t.Error("Expected error when stats reporter is nil, but got none.")
+	}
+}
+
+func TestEWMAScalerNoData(t *testing.T) {
+	metrics := &testMetricClient{err: errors.New("no metrics")}
+	e := newTestEWMAScaler(10.0, autoscaling.Concurrency, metrics)
+	expectUniScale(t, e, time.Now(), 0, false)
+}
+
+func TestEWMAScalerSmoothing(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 100.0, panicConcurrency: 100.0}
+	e := newTestEWMAScaler(10.0, autoscaling.Concurrency, metrics)
+	endpoints(1)
+
+	// The first observation seeds the average.
+	now := time.Now()
+	expectUniScale(t, e, now, 10, true)
+	endpoints(10)
+
+	// A drop is folded in with weight 1-e^-1 after one time constant
+	// (a third of the stable window): 100 * e^-1 = 36.8.
+	metrics.panicConcurrency = 0.0
+	now = now.Add(stableWindow / aggregation.EWMATimeConstantsPerWindow)
+	expectUniScale(t, e, now, 4, true)
+
+	// A spike is folded in the same way: 36.8 + (300 - 36.8) * (1-e^-1) = 203.2.
+	metrics.panicConcurrency = 300.0
+	now = now.Add(stableWindow / aggregation.EWMATimeConstantsPerWindow)
+	expectUniScale(t, e, now, 21, true)
+}
+
+func TestEWMAScalerScaleToZero(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 10.0, panicConcurrency: 10.0}
+	e := newTestEWMAScaler(10.0, autoscaling.Concurrency, metrics)
+	endpoints(1)
+
+	now := time.Now()
+	expectUniScale(t, e, now, 1, true)
+
+	// A quiet panic window alone is not enough to scale to zero.
+	metrics.panicConcurrency = 0.0
+	now = now.Add(time.Second)
+	expectUniScale(t, e, now, 1, true)
+
+	// But a quiet stable window is.
+	metrics.stableConcurrency = 0.0
+	now = now.Add(time.Second)
+	expectUniScale(t, e, now, 0, true)
+}
+
+func TestEWMAScalerRPS(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 1.0, stableRPS: 500.0, panicRPS: 500.0}
+	e := newTestEWMAScaler(100.0, autoscaling.RPS, metrics)
+	endpoints(1)
+	expectUniScale(t, e, time.Now(), 5, true)
+}
+
+func TestEWMAScalerRateLimited(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 1000.0, panicConcurrency: 1000.0}
+	e := newTestEWMAScaler(10.0, autoscaling.Concurrency, metrics)
+	endpoints(1)
+
+	// Need 100 pods but only scale x10
+	expectUniScale(t, e, time.Now(), 10, true)
+}
+
+func newTestEWMAScaler(target float64, metric string, metrics MetricClient) *EWMAScaler {
+	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
+	e, _ := NewEWMAScaler(testNamespace, testRevision, metrics, podCounter, DeciderSpec{
+		Algorithm:      autoscaling.EWMAAlgorithm,
+		ScalingMetric:  metric,
+		TargetValue:    target,
+		MaxScaleUpRate: 10.0,
+		StableWindow:   stableWindow,
+		ServiceName:    testService,
+	}, &mockReporter{})
+	return e
+}
+
+func expectUniScale(t *testing.T, u UniScaler, now time.Time, expectScale int32, expectOk bool) {
+	t.Helper()
+	scale, ok := u.Scale(TestContextWithLogger(t), now)
+	if ok != expectOk {
+		t.Errorf("Unexpected autoscale decision. Expected %v. Got %v.", expectOk, ok)
+	}
+	if scale != expectScale {
+		t.Errorf("Unexpected scale. Expected %v. Got %v.", expectScale, scale)
+	}
+}
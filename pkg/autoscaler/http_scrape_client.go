@@ -77,6 +77,20 @@ func extractData(body io.Reader) (*Stat, error) {
 		}
 		*pv = *pm.Gauge.Value
 	}
+	// The latency and the queue are optional, they're not reported by older
+	// queue-proxies.
+	for m, pv := range map[string]*float64{
+		"queue_request_latency_p50_seconds": &stat.RequestLatencyP50,
+		"queue_request_latency_p95_seconds": &stat.RequestLatencyP95,
+		"queue_request_latency_p99_seconds": &stat.RequestLatencyP99,
+		"queue_average_queue_length":        &stat.AverageQueueLength,
+		"queue_max_queue_length":            &stat.MaxQueueLength,
+		"queue_average_queued_seconds":      &stat.AverageQueuedTime,
+	} {
+		if pm := prometheusMetric(metricFamilies, m); pm != nil {
+			*pv = *pm.Gauge.Value
+		}
+	}
 	return &stat, nil
 }
 
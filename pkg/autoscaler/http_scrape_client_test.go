@@ -44,6 +44,26 @@ queue_average_proxied_concurrent_requests{destination_namespace="test-namespace"
 	testProxiedQPSContext = `# HELP queue_proxied_operations_per_second Number of proxied requests received since last Stat
 # TYPE queue_proxied_operations_per_second gauge
 queue_proxied_operations_per_second{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 4
+`
+	testLatencyContext = `# HELP queue_request_latency_p50_seconds The 50th percentile of the latency of the requests handled by this pod
+# TYPE queue_request_latency_p50_seconds gauge
+queue_request_latency_p50_seconds{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 0.1
+# HELP queue_request_latency_p95_seconds The 95th percentile of the latency of the requests handled by this pod
+# TYPE queue_request_latency_p95_seconds gauge
+queue_request_latency_p95_seconds{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 0.2
+# HELP queue_request_latency_p99_seconds The 99th percentile of the latency of the requests handled by this pod
+# TYPE queue_request_latency_p99_seconds gauge
+queue_request_latency_p99_seconds{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 0.4
+`
+	testQueueContext = `# HELP queue_average_queue_length Number of requests waiting for capacity in this pod
+# TYPE queue_average_queue_length gauge
+queue_average_queue_length{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 1.5
+# HELP queue_max_queue_length Peak number of requests waiting for capacity in this pod
+# TYPE queue_max_queue_length gauge
+queue_max_queue_length{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 3
+# HELP queue_average_queued_seconds Average time requests waited for capacity in this pod
+# TYPE queue_average_queued_seconds gauge
+queue_average_queued_seconds{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 0.2
 `
 	testFullContext = testAverageConcurrencyContext + testQPSContext + testAverageProxiedConcurrenyContext + testProxiedQPSContext
 )
@@ -98,6 +118,78 @@ func TestHTTPScrapeClient_Scrape_HappyCase(t *testing.T) {
 	}
 }
 
+func TestHTTPScrapeClient_Scrape_Latency(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		p50, p95, p99 float64
+	}{{
+		name: "without latency",
+		body: testFullContext,
+	}, {
+		name: "with latency",
+		body: testFullContext + testLatencyContext,
+		p50:  0.1,
+		p95:  0.2,
+		p99:  0.4,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hClient := newTestHTTPClient(getHTTPResponse(http.StatusOK, test.body), nil)
+			sClient, err := newHTTPScrapeClient(hClient)
+			if err != nil {
+				t.Fatalf("newHTTPScrapeClient = %v, want no error", err)
+			}
+
+			stat, err := sClient.Scrape(testURL)
+			if err != nil {
+				t.Fatalf("scrapeViaURL = %v, want no error", err)
+			}
+			if stat.RequestLatencyP50 != test.p50 || stat.RequestLatencyP95 != test.p95 || stat.RequestLatencyP99 != test.p99 {
+				t.Errorf("stat latency = %v, %v, %v, want %v, %v, %v", stat.RequestLatencyP50, stat.RequestLatencyP95,
+					stat.RequestLatencyP99, test.p50, test.p95, test.p99)
+			}
+		})
+	}
+}
+
+func TestHTTPScrapeClient_Scrape_Queue(t *testing.T) {
+	tests := []struct {
+		name                 string
+		body                 string
+		average, max, queued float64
+	}{{
+		name: "without queue",
+		body: testFullContext,
+	}, {
+		name:    "with queue",
+		body:    testFullContext + testQueueContext,
+		average: 1.5,
+		max:     3,
+		queued:  0.2,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hClient := newTestHTTPClient(getHTTPResponse(http.StatusOK, test.body), nil)
+			sClient, err := newHTTPScrapeClient(hClient)
+			if err != nil {
+				t.Fatalf("newHTTPScrapeClient = %v, want no error", err)
+			}
+
+			stat, err := sClient.Scrape(testURL)
+			if err != nil {
+				t.Fatalf("scrapeViaURL = %v, want no error", err)
+			}
+			if stat.AverageQueueLength != test.average || stat.MaxQueueLength != test.max || stat.AverageQueuedTime != test.queued {
+				t.Errorf("stat queue = %v, %v, %v, want %v, %v, %v", stat.AverageQueueLength, stat.MaxQueueLength,
+					stat.AverageQueuedTime, test.average, test.max, test.queued)
+			}
+		})
+	}
+}
+
 func TestHTTPScrapeClient_Scrape_ErrorCases(t *testing.T) {
 	testCases := []struct {
 		name            string
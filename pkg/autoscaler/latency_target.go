@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"math"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxLatencyTuningStep bounds the factor by which a single tuning step
+	// changes the target, to ride out outliers in the observed latency.
+	maxLatencyTuningStep = 2.0
+)
+
+// latencyTuner tunes the target value of a DeciderSpec with a TargetLatency
+// to keep the latency at the LatencyPercentile at the TargetLatency. The
+// zero value is ready to use.
+type latencyTuner struct {
+	mux sync.Mutex
+	// target is the tuned target value, 0 until the first tuning.
+	target float64
+}
+
+// tune returns the spec with the tuned target value and the panic threshold
+// scaled along with it. Specs without a TargetLatency are returned as is.
+//
+// The latency is assumed to grow linearly with the load of a pod, so the
+// target that meets the goal is the current target scaled by the ratio of
+// the target latency to the observed latency. As the observed latency lags
+// a stable window behind, the target moves towards that value by the share
+// of the stable window that passes in a tick.
+func (t *latencyTuner) tune(logger *zap.SugaredLogger, metricKey string, spec DeciderSpec, metricClient MetricClient) DeciderSpec {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if spec.TargetLatency <= 0 {
+		t.target = 0
+		return spec
+	}
+	if t.target == 0 {
+		t.target = spec.TargetValue
+	}
+
+	latency, err := metricClient.StableLatency(metricKey, spec.LatencyPercentile)
+	switch {
+	case err == ErrNoData:
+		logger.Debug("No latency to tune the target on yet")
+	case err != nil:
+		logger.Errorw("Failed to obtain the latency", zap.Error(err))
+	case latency > 0:
+		ratio := spec.TargetLatency.Seconds() / latency
+		ratio = math.Max(1/maxLatencyTuningStep, math.Min(maxLatencyTuningStep, ratio))
+		gain := 1.0
+		if spec.StableWindow > spec.TickInterval {
+			gain = spec.TickInterval.Seconds() / spec.StableWindow.Seconds()
+		}
+		t.target *= 1 + gain*(ratio-1)
+		logger.Debugf("LATENCY: Observed p%d of %0.3fs, targeting %v, tuned the target to %0.3f.",
+			spec.LatencyPercentile, latency, spec.TargetLatency, t.target)
+	}
+
+	if spec.MinTargetValue > 0 {
+		t.target = math.Max(spec.MinTargetValue, t.target)
+	}
+	if spec.MaxTargetValue > 0 {
+		t.target = math.Min(spec.MaxTargetValue, t.target)
+	}
+
+	if spec.TargetValue > 0 {
+		spec.PanicThreshold *= t.target / spec.TargetValue
+	}
+	spec.TargetValue = t.target
+	return spec
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/knative/pkg/logging/testing"
+)
+
+func TestLatencyTunerTune(t *testing.T) {
+	latencySpec := DeciderSpec{
+		TickInterval:      2 * time.Second,
+		StableWindow:      60 * time.Second,
+		TargetValue:       10,
+		PanicThreshold:    20,
+		TargetLatency:     300 * time.Millisecond,
+		LatencyPercentile: 95,
+		MinTargetValue:    1,
+		MaxTargetValue:    20,
+	}
+
+	tests := []struct {
+		name       string
+		spec       DeciderSpec
+		start      float64
+		latency    float64
+		latencyErr error
+		wantTarget float64
+	}{{
+		name:       "no target latency",
+		spec:       DeciderSpec{TargetValue: 10, PanicThreshold: 20},
+		start:      5,
+		latency:    1,
+		wantTarget: 10,
+	}, {
+		name:       "first tuning starts from the target",
+		spec:       latencySpec,
+		latency:    0.3,
+		wantTarget: 10,
+	}, {
+		name:       "latency too high",
+		spec:       latencySpec,
+		start:      10,
+		latency:    0.6,
+		wantTarget: 10 * (1 - 0.5/30),
+	}, {
+		name:       "latency too low",
+		spec:       latencySpec,
+		start:      10,
+		latency:    0.2,
+		wantTarget: 10 * (1 + 0.5/30),
+	}, {
+		name:       "step is bounded",
+		spec:       latencySpec,
+		start:      10,
+		latency:    0.001,
+		wantTarget: 10 * (1 + 1.0/30),
+	}, {
+		name:       "clamped to max",
+		spec:       latencySpec,
+		start:      20,
+		latency:    0.1,
+		wantTarget: 20,
+	}, {
+		name:       "clamped to min",
+		spec:       latencySpec,
+		start:      1,
+		latency:    10,
+		wantTarget: 1,
+	}, {
+		name:       "no data keeps the target",
+		spec:       latencySpec,
+		start:      7,
+		latencyErr: ErrNoData,
+		wantTarget: 7,
+	}, {
+		name:       "error keeps the target",
+		spec:       latencySpec,
+		start:      7,
+		latencyErr: errors.New("boom"),
+		wantTarget: 7,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer ClearAll()
+			tuner := &latencyTuner{target: test.start}
+			metrics := &testMetricClient{latency: test.latency, latencyErr: test.latencyErr}
+			got := tuner.tune(TestLogger(t), "ns/rev", test.spec, metrics)
+			if math.Abs(got.TargetValue-test.wantTarget) > 1e-9 {
+				t.Errorf("TargetValue = %v, want: %v", got.TargetValue, test.wantTarget)
+			}
+			if want := test.spec.PanicThreshold * test.wantTarget / test.spec.TargetValue; math.Abs(got.PanicThreshold-want) > 1e-9 {
+				t.Errorf("PanicThreshold = %v, want: %v", got.PanicThreshold, want)
+			}
+		})
+	}
+}
+
+func TestLatencyTunerReset(t *testing.T) {
+	defer ClearAll()
+	logger := TestLogger(t)
+	spec := DeciderSpec{
+		TickInterval:      time.Second,
+		StableWindow:      time.Second,
+		TargetValue:       10,
+		TargetLatency:     time.Second,
+		LatencyPercentile: 99,
+	}
+	metrics := &testMetricClient{latency: 2}
+	tuner := &latencyTuner{}
+
+	if got := tuner.tune(logger, "ns/rev", spec, metrics).TargetValue; got != 5 {
+		t.Errorf("TargetValue = %v, want: 5", got)
+	}
+	if got := tuner.tune(logger, "ns/rev", spec, metrics).TargetValue; got != 2.5 {
+		t.Errorf("TargetValue = %v, want: 2.5", got)
+	}
+
+	// Dropping the target latency starts over.
+	spec.TargetLatency = 0
+	if got := tuner.tune(logger, "ns/rev", spec, metrics).TargetValue; got != 10 {
+		t.Errorf("TargetValue = %v, want: 10", got)
+	}
+	spec.TargetLatency = time.Second
+	if got := tuner.tune(logger, "ns/rev", spec, metrics).TargetValue; got != 5 {
+		t.Errorf("TargetValue = %v, want: 5", got)
+	}
+}
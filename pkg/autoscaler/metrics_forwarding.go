@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Knative Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	cmetrics "k8s.io/metrics/pkg/apis/custom_metrics"
+)
+
+// MetricValuePath is the path autoscaler replicas serve the custom metrics
+// of the revisions they own under, for the other replicas to forward
+// lookups to.
+const MetricValuePath = "/metric-value"
+
+// metricForwardTimeout is the time the owning replica has to answer a
+// forwarded lookup.
+const metricForwardTimeout = 2 * time.Second
+
+// MetricOwners tells which of the autoscaler replicas owns a revision.
+type MetricOwners interface {
+	// Self returns this replica.
+	Self() string
+	// Members returns all replicas.
+	Members() []string
+	// Owner returns the replica owning the key.
+	Owner(key string) string
+	// IsOwner returns whether this replica owns the key.
+	IsOwner(key string) bool
+}
+
+// ForwardTo makes the provider forward the lookups of revisions other
+// replicas own to the MetricValueHandler they serve on the given port. Only
+// the owner of a revision collects all of its data, so every replica can
+// answer the lookups of the custom-metrics API. It must be called before
+// the provider is used.
+func (p *MetricProvider) ForwardTo(owners MetricOwners, port int) {
+	p.owners = owners
+	p.remote = &remoteMetrics{
+		port:   port,
+		client: &http.Client{Timeout: metricForwardTimeout},
+	}
+}
+
+// MetricValueHandler serves the lookups forwarded by other replicas. They
+// are always answered from the data of this replica and never forwarded
+// again.
+func (p *MetricProvider) MetricValueHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		info := revisionMetricInfo(query.Get("metric"))
+		if !p.supports(info) {
+			http.Error(w, errMetricNotSupported.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var (
+			result interface{}
+			err    error
+		)
+		if name := query.Get("name"); name != "" {
+			result, err = p.localMetricByName(types.NamespacedName{Namespace: query.Get("namespace"), Name: name}, info)
+		} else {
+			selector, perr := labels.Parse(query.Get("selector"))
+			if perr != nil {
+				http.Error(w, perr.Error(), http.StatusBadRequest)
+				return
+			}
+			result, err = p.localMetricsBySelector(query.Get("namespace"), selector, info)
+		}
+
+		switch {
+		case err == ErrNoData:
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		case apierrors.IsNotFound(err):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(result)
+		}
+	})
+}
+
+// remoteMetrics looks up metrics on other replicas.
+type remoteMetrics struct {
+	port   int
+	client *http.Client
+}
+
+func (r *remoteMetrics) metricByName(member string, name types.NamespacedName, metric string) (*cmetrics.MetricValue, error) {
+	value := &cmetrics.MetricValue{}
+	err := r.get(member, url.Values{
+		"namespace": {name.Namespace},
+		"name":      {name.Name},
+		"metric":    {metric},
+	}, value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (r *remoteMetrics) metricsBySelector(member, namespace string, selector labels.Selector, metric string) (*cmetrics.MetricValueList, error) {
+	list := &cmetrics.MetricValueList{}
+	err := r.get(member, url.Values{
+		"namespace": {namespace},
+		"selector":  {selector.String()},
+		"metric":    {metric},
+	}, list)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// get sends the lookup to the replica and decodes its answer into result,
+// mapping the errors back to those of a local lookup.
+func (r *remoteMetrics) get(member string, query url.Values, result interface{}) error {
+	u := url.URL{
+		Scheme:   "http",
+		Host:     net.JoinHostPort(member, strconv.Itoa(r.port)),
+		Path:     MetricValuePath,
+		RawQuery: query.Encode(),
+	}
+	resp, err := r.client.Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(result)
+	case http.StatusServiceUnavailable:
+		return ErrNoData
+	case http.StatusNotFound:
+		return apierrors.NewNotFound(v1alpha1.Resource("revisions"), query.Get("name"))
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("autoscaler replica %s failed the lookup with %d: %s", member, resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestMetricProviderForwarding(t *testing.T) {
+	// The other replica owns blue and serves its lookups.
+	owner := NewMetricProvider(staticConcurrency(10.0), staticMetrics{
+		metric(existingNamespace, "blue", map[string]string{"app": "web"}),
+	}, staticDeciders{"blue": 2})
+	server := httptest.NewServer(owner.MetricValueHandler())
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() = %v", err)
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatalf("SplitHostPort() = %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("Atoi() = %v", err)
+	}
+
+	// We collect blue too, but don't own it.
+	p := NewMetricProvider(staticConcurrency(1.0), staticMetrics{
+		metric(existingNamespace, "blue", map[string]string{"app": "web"}),
+		metric(existingNamespace, "green", map[string]string{"app": "web"}),
+	}, staticDeciders{"green": 4})
+	p.ForwardTo(&staticOwners{
+		self:   "self",
+		remote: host,
+		owned:  map[string]bool{existingNamespace + "/blue": true, existingNamespace + "/gone": true},
+	}, port)
+
+	got, err := p.GetMetricByName(types.NamespacedName{Namespace: existingNamespace, Name: "blue"}, concurrencyMetricInfo)
+	if err != nil {
+		t.Fatalf("GetMetricByName() = %v", err)
+	}
+	if got.Value.Value() != 10 || got.DescribedObject.Name != "blue" {
+		t.Errorf("GetMetricByName() = %v of %s, want 10 of blue", got.Value.String(), got.DescribedObject.Name)
+	}
+
+	if _, err := p.GetMetricByName(types.NamespacedName{Namespace: existingNamespace, Name: "gone"}, desiredScaleMetricInfo); !k8serrors.IsNotFound(err) {
+		t.Errorf("GetMetricByName() = %v, want a not found error", err)
+	}
+
+	list, err := p.GetMetricBySelector(existingNamespace, labels.SelectorFromSet(labels.Set{"app": "web"}), desiredScaleMetricInfo)
+	if err != nil {
+		t.Fatalf("GetMetricBySelector() = %v", err)
+	}
+	// Every revision is reported once, by its owner.
+	var names []string
+	var scales []int64
+	for _, item := range list.Items {
+		names = append(names, item.DescribedObject.Name)
+		scales = append(scales, item.Value.Value())
+	}
+	if len(names) != 2 || names[0] != "green" || scales[0] != 4 || names[1] != "blue" || scales[1] != 2 {
+		t.Errorf("GetMetricBySelector() = %v with %v, want [green blue] with [4 2]", names, scales)
+	}
+}
+
+// staticOwners makes the remote replica own the owned keys and this
+// replica all others.
+type staticOwners struct {
+	self, remote string
+	owned        map[string]bool
+}
+
+func (o *staticOwners) Self() string {
+	return o.self
+}
+
+func (o *staticOwners) Members() []string {
+	return []string{o.self, o.remote}
+}
+
+func (o *staticOwners) Owner(key string) string {
+	if o.owned[key] {
+		return o.remote
+	}
+	return o.self
+}
+
+func (o *staticOwners) IsOwner(key string) bool {
+	return o.Owner(key) == o.self
+}
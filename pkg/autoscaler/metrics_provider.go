@@ -17,6 +17,7 @@ limitations under the License.
 package autoscaler
 
 import (
+	"context"
 	"errors"
 	"math"
 	"time"
@@ -25,6 +26,7 @@ import (
 	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/kubernetes-incubator/custom-metrics-apiserver/pkg/provider"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -33,57 +35,198 @@ import (
 )
 
 var (
-	concurrencyMetricInfo = provider.CustomMetricInfo{
+	concurrencyMetricInfo = revisionMetricInfo(autoscaling.Concurrency)
+	// panicConcurrencyMetricInfo is the concurrency averaged over the panic window.
+	panicConcurrencyMetricInfo = revisionMetricInfo("panic-concurrency")
+	rpsMetricInfo              = revisionMetricInfo(autoscaling.RPS)
+	// proxiedRPSMetricInfo is the rate of the requests proxied by the activator.
+	proxiedRPSMetricInfo = revisionMetricInfo("proxied-rps")
+	// desiredScaleMetricInfo is the latest scale recommendation of the KPA.
+	desiredScaleMetricInfo = revisionMetricInfo("desired-scale")
+
+	errMetricNotSupported = errors.New("metric not supported")
+)
+
+func revisionMetricInfo(metric string) provider.CustomMetricInfo {
+	return provider.CustomMetricInfo{
 		GroupResource: v1alpha1.Resource("revisions"),
 		Namespaced:    true,
-		Metric:        autoscaling.Concurrency,
+		Metric:        metric,
 	}
+}
 
-	errMetricNotSupported = errors.New("metric not supported")
-	errNotImplemented     = errors.New("not implemented")
-)
+// MetricLister lists the Metrics collected for the revisions.
+type MetricLister interface {
+	List() []*Metric
+}
+
+// DeciderGetter returns the current Decider of a revision.
+type DeciderGetter interface {
+	Get(ctx context.Context, namespace, name string) (*Decider, error)
+}
 
 // MetricProvider is a provider to back a custom-metrics API implementation.
 type MetricProvider struct {
 	metricClient MetricClient
+	metricLister MetricLister
+	deciders     DeciderGetter
+
+	// owners and remote are only set if the revisions are sharded across
+	// several autoscaler replicas, see ForwardTo.
+	owners MetricOwners
+	remote *remoteMetrics
 }
 
 var _ provider.CustomMetricsProvider = (*MetricProvider)(nil)
 
 // NewMetricProvider creates a new MetricProvider.
-func NewMetricProvider(metricClient MetricClient) *MetricProvider {
+func NewMetricProvider(metricClient MetricClient, metricLister MetricLister, deciders DeciderGetter) *MetricProvider {
 	return &MetricProvider{
 		metricClient: metricClient,
+		metricLister: metricLister,
+		deciders:     deciders,
 	}
 }
 
 // GetMetricByName implements the interface.
 func (p *MetricProvider) GetMetricByName(name types.NamespacedName, info provider.CustomMetricInfo) (*cmetrics.MetricValue, error) {
-	if !cmp.Equal(info, concurrencyMetricInfo) {
+	if !p.supports(info) {
 		return nil, errMetricNotSupported
 	}
+	if key := name.String(); p.owners != nil && !p.owners.IsOwner(key) {
+		return p.remote.metricByName(p.owners.Owner(key), name, info.Metric)
+	}
+	return p.localMetricByName(name, info)
+}
 
-	concurrency, _, err := p.metricClient.StableAndPanicConcurrency(name.String())
+// localMetricByName looks up the metric in the data of this replica.
+func (p *MetricProvider) localMetricByName(name types.NamespacedName, info provider.CustomMetricInfo) (*cmetrics.MetricValue, error) {
+	value, err := p.value(name, info.Metric)
 	if err != nil {
 		return nil, err
 	}
-	value := *resource.NewQuantity(int64(math.Ceil(concurrency)), resource.DecimalSI)
-
-	return &cmetrics.MetricValue{
-		Metric: cmetrics.MetricIdentifier{
-			Name: info.Metric,
-		},
-		Timestamp: metav1.Time{Time: time.Now()},
-		Value:     value,
-	}, nil
+	return metricValue(name, info.Metric, value, nil), nil
 }
 
 // GetMetricBySelector implements the interface.
 func (p *MetricProvider) GetMetricBySelector(namespace string, selector labels.Selector, info provider.CustomMetricInfo) (*cmetrics.MetricValueList, error) {
-	return nil, errNotImplemented
+	if !p.supports(info) {
+		return nil, errMetricNotSupported
+	}
+
+	list, err := p.localMetricsBySelector(namespace, selector, info)
+	if err != nil || p.owners == nil {
+		return list, err
+	}
+	for _, member := range p.owners.Members() {
+		if member == p.owners.Self() {
+			continue
+		}
+		remote, err := p.remote.metricsBySelector(member, namespace, selector, info.Metric)
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, remote.Items...)
+	}
+	return list, nil
+}
+
+// localMetricsBySelector looks up the metrics of the matching revisions this
+// replica owns.
+func (p *MetricProvider) localMetricsBySelector(namespace string, selector labels.Selector, info provider.CustomMetricInfo) (*cmetrics.MetricValueList, error) {
+	var metricSelector *metav1.LabelSelector
+	if ls, err := metav1.ParseToLabelSelector(selector.String()); err == nil {
+		metricSelector = ls
+	}
+
+	list := &cmetrics.MetricValueList{}
+	for _, m := range p.metricLister.List() {
+		if m.Namespace != namespace || !selector.Matches(labels.Set(m.Labels)) {
+			continue
+		}
+		name := types.NamespacedName{Namespace: m.Namespace, Name: m.Name}
+		if p.owners != nil && !p.owners.IsOwner(name.String()) {
+			// The owner reports it.
+			continue
+		}
+		value, err := p.value(name, info.Metric)
+		if err != nil {
+			// Revisions without data yet or without a decider are skipped
+			// rather than failing the whole list.
+			if err == ErrNoData || apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		list.Items = append(list.Items, *metricValue(name, info.Metric, value, metricSelector))
+	}
+	return list, nil
 }
 
 // ListAllMetrics implements the interface.
 func (p *MetricProvider) ListAllMetrics() []provider.CustomMetricInfo {
-	return []provider.CustomMetricInfo{concurrencyMetricInfo}
+	return []provider.CustomMetricInfo{
+		concurrencyMetricInfo,
+		panicConcurrencyMetricInfo,
+		rpsMetricInfo,
+		proxiedRPSMetricInfo,
+		desiredScaleMetricInfo,
+	}
+}
+
+func (p *MetricProvider) supports(info provider.CustomMetricInfo) bool {
+	for _, i := range p.ListAllMetrics() {
+		if cmp.Equal(info, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the current value of the given metric of the named revision.
+func (p *MetricProvider) value(name types.NamespacedName, metric string) (resource.Quantity, error) {
+	key := name.String()
+	switch metric {
+	case panicConcurrencyMetricInfo.Metric:
+		_, panicConcurrency, err := p.metricClient.StableAndPanicConcurrency(key)
+		return milliQuantity(panicConcurrency), err
+	case rpsMetricInfo.Metric:
+		rps, _, err := p.metricClient.StableAndPanicRPS(key)
+		return milliQuantity(rps), err
+	case proxiedRPSMetricInfo.Metric:
+		proxiedRPS, _, err := p.metricClient.StableAndPanicProxiedRPS(key)
+		return milliQuantity(proxiedRPS), err
+	case desiredScaleMetricInfo.Metric:
+		decider, err := p.deciders.Get(context.Background(), name.Namespace, name.Name)
+		if err != nil {
+			return resource.Quantity{}, err
+		}
+		return *resource.NewQuantity(int64(decider.Status.DesiredScale), resource.DecimalSI), nil
+	default:
+		// The concurrency is rounded up to whole requests for
+		// compatibility with existing HPA targets.
+		concurrency, _, err := p.metricClient.StableAndPanicConcurrency(key)
+		return *resource.NewQuantity(int64(math.Ceil(concurrency)), resource.DecimalSI), err
+	}
+}
+
+func milliQuantity(v float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Round(v*1000)), resource.DecimalSI)
+}
+
+func metricValue(name types.NamespacedName, metric string, value resource.Quantity, selector *metav1.LabelSelector) *cmetrics.MetricValue {
+	return &cmetrics.MetricValue{
+		DescribedObject: cmetrics.ObjectReference{
+			Kind:       "Revision",
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Namespace:  name.Namespace,
+			Name:       name.Name,
+		},
+		Metric: cmetrics.MetricIdentifier{
+			Name:     metric,
+			Selector: selector,
+		},
+		Timestamp: metav1.Time{Time: time.Now()},
+		Value:     value,
+	}
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package autoscaler
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -26,6 +27,8 @@ import (
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/kubernetes-incubator/custom-metrics-apiserver/pkg/provider"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -41,17 +44,52 @@ func TestGetMetricByName(t *testing.T) {
 		info provider.CustomMetricInfo
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    int64
-		wantErr bool
+		name      string
+		args      args
+		wantMilli int64
+		wantErr   bool
 	}{{
 		name: "all good",
 		args: args{
 			name: types.NamespacedName{Namespace: existingNamespace, Name: "test"},
 			info: concurrencyMetricInfo,
 		},
-		want: 11,
+		wantMilli: 11000,
+	}, {
+		name: "panic concurrency",
+		args: args{
+			name: types.NamespacedName{Namespace: existingNamespace, Name: "test"},
+			info: panicConcurrencyMetricInfo,
+		},
+		wantMilli: 20600,
+	}, {
+		name: "rps",
+		args: args{
+			name: types.NamespacedName{Namespace: existingNamespace, Name: "test"},
+			info: rpsMetricInfo,
+		},
+		wantMilli: 30900,
+	}, {
+		name: "proxied rps",
+		args: args{
+			name: types.NamespacedName{Namespace: existingNamespace, Name: "test"},
+			info: proxiedRPSMetricInfo,
+		},
+		wantMilli: 5150,
+	}, {
+		name: "desired scale",
+		args: args{
+			name: types.NamespacedName{Namespace: existingNamespace, Name: "test"},
+			info: desiredScaleMetricInfo,
+		},
+		wantMilli: 3000,
+	}, {
+		name: "desired scale without decider",
+		args: args{
+			name: types.NamespacedName{Namespace: existingNamespace, Name: "hpa"},
+			info: desiredScaleMetricInfo,
+		},
+		wantErr: true,
 	}, {
 		name: "requesting unsupported metric",
 		args: args{
@@ -73,7 +111,7 @@ func TestGetMetricByName(t *testing.T) {
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := NewMetricProvider(staticConcurrency(10.3))
+			p := NewMetricProvider(staticConcurrency(10.3), staticMetrics{}, staticDeciders{"test": 3})
 			got, err := p.GetMetricByName(tt.args.name, tt.args.info)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetMetricByName() error = %v, wantErr %v", err, tt.wantErr)
@@ -82,38 +120,164 @@ func TestGetMetricByName(t *testing.T) {
 				return
 			}
 
-			gotValue, _ := got.Value.AsInt64()
-			if gotValue != tt.want {
-				t.Errorf("GetMetricByName() = %v, want %v", got, tt.want)
+			if gotValue := got.Value.MilliValue(); gotValue != tt.wantMilli {
+				t.Errorf("GetMetricByName() = %vm, want %vm", gotValue, tt.wantMilli)
+			}
+			if got.Metric.Name != tt.args.info.Metric {
+				t.Errorf("GetMetricByName().Metric.Name = %q, want %q", got.Metric.Name, tt.args.info.Metric)
+			}
+			if got.DescribedObject.Name != tt.args.name.Name || got.DescribedObject.Kind != "Revision" {
+				t.Errorf("GetMetricByName().DescribedObject = %+v, want Revision %s", got.DescribedObject, tt.args.name.Name)
 			}
 		})
 	}
 }
 
 func TestGetMetricBySelector(t *testing.T) {
-	provider := NewMetricProvider(staticConcurrency(10.0))
-	_, got := provider.GetMetricBySelector("foo", labels.NewSelector(), concurrencyMetricInfo)
-	if got != errNotImplemented {
-		t.Errorf("GetMetricBySelector() = %v, want %v", got, errNotImplemented)
+	metrics := staticMetrics{
+		metric(existingNamespace, "blue", map[string]string{"app": "web", "color": "blue"}),
+		metric(existingNamespace, "green", map[string]string{"app": "web", "color": "green"}),
+		metric(existingNamespace, "hpa", map[string]string{"app": "web", "color": "red"}),
+		metric(existingNamespace, "other", map[string]string{"app": "batch"}),
+		metric("elsewhere", "blue", map[string]string{"app": "web"}),
+	}
+	p := NewMetricProvider(staticConcurrency(10.0), metrics, staticDeciders{"blue": 2, "green": 4})
+
+	tests := []struct {
+		name     string
+		selector string
+		info     provider.CustomMetricInfo
+		want     []string
+		wantErr  bool
+	}{{
+		name:     "by app",
+		selector: "app=web",
+		info:     concurrencyMetricInfo,
+		want:     []string{"blue", "green", "hpa"},
+	}, {
+		name:     "by color",
+		selector: "color in (blue,green)",
+		info:     rpsMetricInfo,
+		want:     []string{"blue", "green"},
+	}, {
+		name:     "everything",
+		selector: "",
+		info:     proxiedRPSMetricInfo,
+		want:     []string{"blue", "green", "hpa", "other"},
+	}, {
+		name:     "revisions without a decider are skipped",
+		selector: "app=web",
+		info:     desiredScaleMetricInfo,
+		want:     []string{"blue", "green"},
+	}, {
+		name:     "nothing matches",
+		selector: "app=none",
+		info:     concurrencyMetricInfo,
+	}, {
+		name:     "unsupported metric",
+		selector: "app=web",
+		info:     revisionMetricInfo("cpu"),
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := labels.Parse(tt.selector)
+			if err != nil {
+				t.Fatalf("labels.Parse() = %v", err)
+			}
+			got, err := p.GetMetricBySelector(existingNamespace, selector, tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetMetricBySelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var names []string
+			for _, item := range got.Items {
+				names = append(names, item.DescribedObject.Name)
+				if item.Metric.Name != tt.info.Metric {
+					t.Errorf("Metric.Name = %q, want %q", item.Metric.Name, tt.info.Metric)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("GetMetricBySelector() revisions = %v, want %v", names, tt.want)
+			}
+		})
 	}
 }
 
 func TestListAllMetrics(t *testing.T) {
-	provider := NewMetricProvider(staticConcurrency(10.0))
-	got := provider.ListAllMetrics()[0]
+	p := NewMetricProvider(staticConcurrency(10.0), staticMetrics{}, staticDeciders{})
+	got := p.ListAllMetrics()
+	want := []provider.CustomMetricInfo{
+		concurrencyMetricInfo,
+		panicConcurrencyMetricInfo,
+		rpsMetricInfo,
+		proxiedRPSMetricInfo,
+		desiredScaleMetricInfo,
+	}
 
-	if equal, err := kmp.SafeEqual(got, concurrencyMetricInfo); err != nil {
+	if equal, err := kmp.SafeEqual(got, want); err != nil {
 		t.Errorf("Got error comparing output, err = %v", err)
 	} else if !equal {
-		t.Errorf("ListAllMetrics() = %v, want %v", got, concurrencyMetricInfo)
+		t.Errorf("ListAllMetrics() = %v, want %v", got, want)
+	}
+}
+
+func metric(namespace, name string, labels map[string]string) *Metric {
+	return &Metric{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
 	}
 }
 
+type staticMetrics []*Metric
+
+func (s staticMetrics) List() []*Metric {
+	return s
+}
+
+type staticDeciders map[string]int32
+
+func (s staticDeciders) Get(ctx context.Context, namespace, name string) (*Decider, error) {
+	scale, ok := s[name]
+	if !ok {
+		return nil, k8serrors.NewNotFound(v1alpha1.Resource("Deciders"), name)
+	}
+	return &Decider{Status: DeciderStatus{DesiredScale: scale}}, nil
+}
+
 type staticConcurrency float64
 
 func (s staticConcurrency) StableAndPanicConcurrency(key string) (float64, float64, error) {
 	if strings.HasPrefix(key, existingNamespace) {
-		return (float64)(s), 0.0, nil
+		return (float64)(s), 2 * (float64)(s), nil
 	}
 	return 0.0, 0.0, errors.New("doesn't exist")
 }
+
+func (s staticConcurrency) StableAndPanicRPS(key string) (float64, float64, error) {
+	if strings.HasPrefix(key, existingNamespace) {
+		return 3 * (float64)(s), 0.0, nil
+	}
+	return 0.0, 0.0, errors.New("doesn't exist")
+}
+
+func (s staticConcurrency) StableAndPanicProxiedRPS(key string) (float64, float64, error) {
+	if strings.HasPrefix(key, existingNamespace) {
+		return (float64)(s) / 2, 0.0, nil
+	}
+	return 0.0, 0.0, errors.New("doesn't exist")
+}
+
+func (s staticConcurrency) StableLatency(key string, percentile int) (float64, error) {
+	return 0.0, errors.New("not implemented")
+}
+
+func (s staticConcurrency) StableAndPanicQueueLength(key string) (float64, float64, error) {
+	return 0.0, 0.0, errors.New("not implemented")
+}
@@ -19,6 +19,7 @@ package autoscaler
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -40,10 +41,30 @@ type Decider struct {
 
 // DeciderSpec is the parameters in which the Revision should scaled.
 type DeciderSpec struct {
-	TickInterval      time.Duration
-	MaxScaleUpRate    float64
-	TargetConcurrency float64
-	PanicThreshold    float64
+	TickInterval     time.Duration
+	MaxScaleUpRate   float64
+	MaxScaleDownRate float64
+	// The name of the decider algorithm used to compute the desired scale,
+	// i.e. stable-panic, ewma or pid.
+	Algorithm string
+	// The metric used for scaling, i.e. concurrency or rps.
+	ScalingMetric string
+	// The value of the scaling metric each pod should maintain.
+	TargetValue    float64
+	PanicThreshold float64
+	// PanicQueueLength, if set, is the number of requests queued per pod
+	// at which to enter panic mode.
+	PanicQueueLength float64
+	// TargetBurstCapacity is the spare capacity below which the activator
+	// stays in the request path, 0 disables it and -1 keeps it there always.
+	TargetBurstCapacity float64
+	// TargetLatency, if set, is the request latency at the LatencyPercentile
+	// the decider tunes the TargetValue to maintain, within MinTargetValue
+	// and MaxTargetValue.
+	TargetLatency     time.Duration
+	LatencyPercentile int
+	MinTargetValue    float64
+	MaxTargetValue    float64
 	// StableWindow is needed to determine when to exit panicmode.
 	StableWindow time.Duration
 	// The name of the k8s service for pod information.
@@ -53,6 +74,33 @@ type DeciderSpec struct {
 // DeciderStatus is the current scale recommendation.
 type DeciderStatus struct {
 	DesiredScale int32
+	// Explanation holds the inputs of the latest recommendation, if the
+	// UniScaler is an Explainer.
+	Explanation Explanation
+	// ExcessBurstCapacity is the spare capacity of the ready pods beyond the
+	// TargetBurstCapacity of the DeciderSpec. The activator stays in the
+	// request path while it's negative.
+	ExcessBurstCapacity float64
+	// LastDecisionTime is the time of the latest recommendation.
+	LastDecisionTime metav1.Time
+	// LastTrafficTime is the last time the decider observed traffic over
+	// the panic window. Without any data yet, it's the time the decider
+	// started, as the traffic before is unknown.
+	LastTrafficTime metav1.Time
+}
+
+// Explanation is what a UniScaler based its latest proposal on.
+type Explanation struct {
+	ReadyPods           int
+	ObservedStableValue float64
+	ObservedPanicValue  float64
+	// TargetValue is the target the proposal was made for, which differs
+	// from the TargetValue of the DeciderSpec if it is tuned to a latency.
+	TargetValue float64
+	// ObservedQueueLength is the number of requests queued over the panic
+	// window, if the decider takes queue pressure into account.
+	ObservedQueueLength float64
+	Panicking           bool
 }
 
 // UniScaler records statistics for a particular Decider and proposes the scale for the Decider's target based on those statistics.
@@ -65,6 +113,12 @@ type UniScaler interface {
 	Update(DeciderSpec) error
 }
 
+// Explainer is implemented by UniScalers which can explain their proposals.
+type Explainer interface {
+	// Explain returns the inputs of the latest proposal.
+	Explain() Explanation
+}
+
 // UniScalerFactory creates a UniScaler for a given PA using the given dynamic configuration.
 type UniScalerFactory func(*Decider) (UniScaler, error)
 
@@ -85,14 +139,38 @@ func (sr *scalerRunner) getLatestScale() int32 {
 	return sr.decider.Status.DesiredScale
 }
 
-func (sr *scalerRunner) updateLatestScale(new int32) bool {
+// updateLatestScale records the latest decision and returns whether the
+// desired scale, the panic state or the need for the activator changed.
+func (sr *scalerRunner) updateLatestScale(new int32, explanation Explanation, now time.Time) bool {
 	sr.mux.Lock()
 	defer sr.mux.Unlock()
-	if sr.decider.Status.DesiredScale != new {
-		sr.decider.Status.DesiredScale = new
-		return true
+	excess := excessBurstCapacity(sr.decider.Spec, explanation)
+	changed := sr.decider.Status.DesiredScale != new ||
+		sr.decider.Status.Explanation.Panicking != explanation.Panicking ||
+		(sr.decider.Status.ExcessBurstCapacity < 0) != (excess < 0)
+	sr.decider.Status.DesiredScale = new
+	sr.decider.Status.Explanation = explanation
+	sr.decider.Status.ExcessBurstCapacity = excess
+	sr.decider.Status.LastDecisionTime = metav1.NewTime(now)
+	// The panic window is the shortest the decider looks back, so it's the
+	// first to tell that the traffic stopped.
+	if explanation.ObservedPanicValue > 0 {
+		sr.decider.Status.LastTrafficTime = metav1.NewTime(now)
 	}
-	return false
+	return changed
+}
+
+// excessBurstCapacity returns the number of requests the ready pods can take
+// on top of the observed load, minus the target burst capacity of the spec.
+func excessBurstCapacity(spec DeciderSpec, explanation Explanation) float64 {
+	switch {
+	case spec.TargetBurstCapacity == 0:
+		return 0
+	case spec.TargetBurstCapacity < 0:
+		return -1
+	}
+	return float64(explanation.ReadyPods)*explanation.TargetValue -
+		explanation.ObservedPanicValue - spec.TargetBurstCapacity
 }
 
 // NewMetricKey identifies a UniScaler in the multiscaler. Stats send in
@@ -143,6 +221,26 @@ func (m *MultiScaler) Get(ctx context.Context, namespace, name string) (*Decider
 	return (&scaler.decider).DeepCopy(), nil
 }
 
+// List returns all current Deciders, sorted by their key.
+func (m *MultiScaler) List() []*Decider {
+	m.scalersMutex.RLock()
+	defer m.scalersMutex.RUnlock()
+	keys := make([]string, 0, len(m.scalers))
+	for key := range m.scalers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	deciders := make([]*Decider, 0, len(keys))
+	for _, key := range keys {
+		scaler := m.scalers[key]
+		scaler.mux.RLock()
+		deciders = append(deciders, (&scaler.decider).DeepCopy())
+		scaler.mux.RUnlock()
+	}
+	return deciders
+}
+
 // Create instantiates the desired Decider.
 func (m *MultiScaler) Create(ctx context.Context, decider *Decider) (*Decider, error) {
 	m.scalersMutex.Lock()
@@ -171,6 +269,19 @@ func (m *MultiScaler) Update(ctx context.Context, decider *Decider) (*Decider, e
 		scaler.mux.Lock()
 		defer scaler.mux.Unlock()
 		oldDeciderSpec := scaler.decider.Spec
+		if oldDeciderSpec.Algorithm != decider.Spec.Algorithm {
+			// A different algorithm needs a fresh UniScaler, so swap it in
+			// while the ticker is stopped.
+			uniScaler, err := m.uniScalerFactory(decider)
+			if err != nil {
+				return nil, err
+			}
+			scaler.stopCh <- struct{}{}
+			scaler.decider = *decider
+			scaler.scaler = uniScaler
+			m.runScalerTicker(ctx, scaler)
+			return decider, nil
+		}
 		scaler.decider = *decider
 		scaler.scaler.Update(decider.Spec)
 		if oldDeciderSpec.TickInterval != decider.Spec.TickInterval {
@@ -256,6 +367,9 @@ func (m *MultiScaler) createScaler(ctx context.Context, decider *Decider) (*scal
 		pokeCh:  make(chan struct{}),
 	}
 	runner.decider.Status.DesiredScale = -1
+	runner.decider.Status.LastTrafficTime = metav1.Now()
+	// Until there is data, there is no spare capacity.
+	runner.decider.Status.ExcessBurstCapacity = excessBurstCapacity(decider.Spec, Explanation{})
 
 	m.runScalerTicker(ctx, runner)
 	return runner, nil
@@ -263,7 +377,8 @@ func (m *MultiScaler) createScaler(ctx context.Context, decider *Decider) (*scal
 
 func (m *MultiScaler) tickScaler(ctx context.Context, scaler UniScaler, runner *scalerRunner, metricKey string) {
 	logger := logging.FromContext(ctx)
-	desiredScale, scaled := scaler.Scale(ctx, time.Now())
+	now := time.Now()
+	desiredScale, scaled := scaler.Scale(ctx, now)
 
 	if !scaled {
 		return
@@ -275,7 +390,11 @@ func (m *MultiScaler) tickScaler(ctx context.Context, scaler UniScaler, runner *
 		return
 	}
 
-	if runner.updateLatestScale(desiredScale) {
+	var explanation Explanation
+	if explainer, ok := scaler.(Explainer); ok {
+		explanation = explainer.Explain()
+	}
+	if runner.updateLatestScale(desiredScale, explanation, now) {
 		m.Inform(metricKey)
 	}
 }
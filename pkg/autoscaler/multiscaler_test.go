@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -231,7 +232,7 @@ func TestMultiScalerScaleFromZero(t *testing.T) {
 	metricKey := NewMetricKey(decider.Namespace, decider.Name)
 	if scaler, exists := ms.scalers[metricKey]; !exists {
 		t.Errorf("Failed to get scaler for metric %s", metricKey)
-	} else if !scaler.updateLatestScale(0) {
+	} else if !scaler.updateLatestScale(0, Explanation{}, time.Now()) {
 		t.Error("Failed to set scale for metric to 0")
 	}
 
@@ -301,7 +302,7 @@ func TestMultiScalerUpdate(t *testing.T) {
 	defer close(statCh)
 
 	decider := newDecider()
-	decider.Spec.TargetConcurrency = 1.0
+	decider.Spec.TargetValue = 1.0
 	uniScaler.setScaleResult(0, true)
 
 	// Create the decider and verify the Spec
@@ -313,12 +314,12 @@ func TestMultiScalerUpdate(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get() = %v", err)
 	}
-	if got, want := m.Spec.TargetConcurrency, 1.0; got != want {
+	if got, want := m.Spec.TargetValue, 1.0; got != want {
 		t.Errorf("Got target concurrency %v. Wanted %v", got, want)
 	}
 
 	// Update the target and verify the Spec
-	decider.Spec.TargetConcurrency = 10.0
+	decider.Spec.TargetValue = 10.0
 	if _, err = ms.Update(ctx, decider); err != nil {
 		t.Errorf("Update() = %v", err)
 	}
@@ -326,11 +327,197 @@ func TestMultiScalerUpdate(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get() = %v", err)
 	}
-	if got, want := m.Spec.TargetConcurrency, 10.0; got != want {
+	if got, want := m.Spec.TargetValue, 10.0; got != want {
 		t.Errorf("Got target concurrency %v. Wanted %v", got, want)
 	}
 }
 
+func TestMultiScalerUpdateAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	ms, stopCh, statCh, uniScaler := createMultiScaler(t)
+	defer close(stopCh)
+	defer close(statCh)
+
+	var created []string
+	ms.uniScalerFactory = func(d *Decider) (UniScaler, error) {
+		created = append(created, d.Spec.Algorithm)
+		return uniScaler, nil
+	}
+
+	decider := newDecider()
+	uniScaler.setScaleResult(1, true)
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+
+	// Updating anything but the algorithm keeps the UniScaler.
+	decider.Spec.TargetValue = 10.0
+	if _, err := ms.Update(ctx, decider); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	if got, want := len(created), 1; got != want {
+		t.Fatalf("UniScalers created = %d, want %d", got, want)
+	}
+
+	// Changing the algorithm creates a new UniScaler.
+	decider.Spec.Algorithm = "ewma"
+	if _, err := ms.Update(ctx, decider); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	if got, want := created, []string{"", "ewma"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UniScalers created for algorithms %v, want %v", got, want)
+	}
+
+	// The new UniScaler keeps ticking.
+	errCh := make(chan error)
+	defer close(errCh)
+	ms.Watch(watchFunc(ctx, ms, decider, 2, errCh))
+	uniScaler.setScaleResult(2, true)
+	if err := verifyTick(errCh); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failure to create the new UniScaler is surfaced.
+	ms.uniScalerFactory = func(*Decider) (UniScaler, error) {
+		return nil, errors.New("boom")
+	}
+	decider.Spec.Algorithm = "pid"
+	if _, err := ms.Update(ctx, decider); err == nil {
+		t.Error("Update() = nil, wanted error")
+	}
+
+	if err := ms.Delete(ctx, decider.Namespace, decider.Name); err != nil {
+		t.Errorf("Delete() = %v", err)
+	}
+}
+
+func TestMultiScalerExplanation(t *testing.T) {
+	ctx := context.Background()
+	ms, stopCh, statCh, uniScaler := createMultiScaler(t)
+	defer close(stopCh)
+	defer close(statCh)
+
+	decider := newDecider()
+	uniScaler.setScaleResult(1, true)
+	uniScaler.setExplanation(Explanation{ReadyPods: 1, ObservedStableValue: 1, ObservedPanicValue: 1})
+
+	errCh := make(chan error)
+	defer close(errCh)
+	ms.Watch(watchFunc(ctx, ms, decider, 1, errCh))
+
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	defer ms.Delete(ctx, decider.Namespace, decider.Name)
+	if err := verifyTick(errCh); err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting to panic informs the watcher even though the scale stays.
+	uniScaler.setExplanation(Explanation{ReadyPods: 1, ObservedStableValue: 1, ObservedPanicValue: 3, Panicking: true})
+	if err := verifyTick(errCh); err != nil {
+		t.Fatal(err)
+	}
+
+	deciders := ms.List()
+	if len(deciders) != 1 {
+		t.Fatalf("List() = %v, want one decider", deciders)
+	}
+	status := deciders[0].Status
+	if want := (Explanation{ReadyPods: 1, ObservedStableValue: 1, ObservedPanicValue: 3, Panicking: true}); status.Explanation != want {
+		t.Errorf("Explanation = %+v, want %+v", status.Explanation, want)
+	}
+	if status.LastDecisionTime.IsZero() {
+		t.Error("LastDecisionTime is not set")
+	}
+}
+
+func TestMultiScalerLastTrafficTime(t *testing.T) {
+	start := time.Now()
+	runner := &scalerRunner{}
+	runner.decider.Status.LastTrafficTime = metav1.NewTime(start)
+
+	// Traffic in the panic window moves the time along.
+	runner.updateLatestScale(1, Explanation{ObservedStableValue: 1, ObservedPanicValue: 1}, start.Add(time.Minute))
+	if got, want := runner.decider.Status.LastTrafficTime.Time, start.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("LastTrafficTime = %v, want %v", got, want)
+	}
+
+	// Once it's gone there, the time stays, even with traffic left in the
+	// stable window.
+	runner.updateLatestScale(1, Explanation{ObservedStableValue: 1}, start.Add(2*time.Minute))
+	if got, want := runner.decider.Status.LastTrafficTime.Time, start.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("LastTrafficTime = %v, want %v", got, want)
+	}
+}
+
+func TestExcessBurstCapacity(t *testing.T) {
+	explanation := Explanation{ReadyPods: 3, TargetValue: 10, ObservedPanicValue: 12}
+	tests := []struct {
+		name        string
+		tbc         float64
+		explanation Explanation
+		want        float64
+	}{{
+		name:        "disabled",
+		explanation: explanation,
+		want:        0,
+	}, {
+		name:        "always in path",
+		tbc:         -1,
+		explanation: explanation,
+		want:        -1,
+	}, {
+		name:        "enough spare capacity",
+		tbc:         10,
+		explanation: explanation,
+		want:        8,
+	}, {
+		name:        "not enough spare capacity",
+		tbc:         20,
+		explanation: explanation,
+		want:        -2,
+	}, {
+		name: "no data",
+		tbc:  20,
+		want: -20,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := DeciderSpec{TargetBurstCapacity: test.tbc}
+			if got := excessBurstCapacity(spec, test.explanation); got != test.want {
+				t.Errorf("excessBurstCapacity() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMultiScalerList(t *testing.T) {
+	ctx := context.Background()
+	ms, stopCh, statCh, _ := createMultiScaler(t)
+	defer close(stopCh)
+	defer close(statCh)
+
+	for _, name := range []string{"c", "a", "b"} {
+		decider := newDecider()
+		decider.Name = name
+		decider.Spec.TickInterval = time.Minute
+		if _, err := ms.Create(ctx, decider); err != nil {
+			t.Fatalf("Create() = %v", err)
+		}
+		defer ms.Delete(ctx, decider.Namespace, decider.Name)
+	}
+
+	var got []string
+	for _, decider := range ms.List() {
+		got = append(got, decider.Name)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
+
 func createMultiScaler(t *testing.T) (*MultiScaler, chan<- struct{}, chan *StatMessage, *fakeUniScaler) {
 	logger := TestLogger(t)
 	uniscaler := &fakeUniScaler{}
@@ -343,11 +530,12 @@ func createMultiScaler(t *testing.T) (*MultiScaler, chan<- struct{}, chan *StatM
 }
 
 type fakeUniScaler struct {
-	mutex      sync.RWMutex
-	replicas   int32
-	scaled     bool
-	lastStat   Stat
-	scaleCount int
+	mutex       sync.RWMutex
+	replicas    int32
+	scaled      bool
+	lastStat    Stat
+	scaleCount  int
+	explanation Explanation
 }
 
 func (u *fakeUniScaler) fakeUniScalerFactory(*Decider) (UniScaler, error) {
@@ -361,6 +549,18 @@ func (u *fakeUniScaler) Scale(context.Context, time.Time) (int32, bool) {
 	return u.replicas, u.scaled
 }
 
+func (u *fakeUniScaler) Explain() Explanation {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return u.explanation
+}
+
+func (u *fakeUniScaler) setExplanation(explanation Explanation) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.explanation = explanation
+}
+
 func (u *fakeUniScaler) getScaleCount() int {
 	u.mutex.RLock()
 	defer u.mutex.RUnlock()
@@ -393,8 +593,8 @@ func newDecider() *Decider {
 			Name:      testRevision,
 		},
 		Spec: DeciderSpec{
-			TickInterval:      tickInterval,
-			TargetConcurrency: 1,
+			TickInterval: tickInterval,
+			TargetValue:  1,
 		},
 		Status: DeciderStatus{},
 	}
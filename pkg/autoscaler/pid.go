@@ -0,0 +1,166 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/knative/pkg/logging"
+	"github.com/knative/serving/pkg/resources"
+)
+
+const (
+	// pidProportionalGain of 1 alone makes the controller propose exactly
+	// observed/target pods, like the stable/panic algorithm does.
+	pidProportionalGain = 1.0
+	// pidIntegralGain is applied to the error accumulated over seconds.
+	pidIntegralGain = 0.1
+	// pidDerivativeGain is applied to the change of the error per second.
+	pidDerivativeGain = 0.05
+	// pidIntegralLimit caps the integral term so it can never contribute
+	// more than the current number of ready pods, which prevents windup
+	// while new pods are starting.
+	pidIntegralLimit = 1.0
+)
+
+// PIDScaler proposes a scale with a proportional-integral-derivative
+// controller. The controlled error is the relative difference between the
+// load observed over the panic window and the load the ready pods are
+// supposed to carry at the target value.
+type PIDScaler struct {
+	namespace    string
+	revision     string
+	metricClient MetricClient
+	podCounter   resources.ReadyPodCounter
+	reporter     StatsReporter
+
+	// Controller state carried over multiple Scale calls. Guarded by
+	// the stateMux.
+	stateMux    sync.Mutex
+	integral    float64
+	lastError   float64
+	lastTime    time.Time
+	explanation Explanation
+
+	// specMux guards the current DeciderSpec.
+	specMux     sync.RWMutex
+	deciderSpec DeciderSpec
+	// latencyTuner tunes the target of specs with a target latency.
+	latencyTuner latencyTuner
+}
+
+// NewPIDScaler creates a new instance of the PID decider.
+func NewPIDScaler(
+	namespace string,
+	revision string,
+	metricClient MetricClient,
+	podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec,
+	reporter StatsReporter) (*PIDScaler, error) {
+	if podCounter == nil {
+		return nil, errors.New("'podCounter' must not be nil")
+	}
+	if reporter == nil {
+		return nil, errors.New("stats reporter must not be nil")
+	}
+
+	// This decider never panics.
+	reporter.ReportPanic(0)
+
+	return &PIDScaler{
+		namespace:    namespace,
+		revision:     revision,
+		metricClient: metricClient,
+		podCounter:   podCounter,
+		deciderSpec:  deciderSpec,
+		reporter:     reporter,
+	}, nil
+}
+
+// Update reconfigures the UniScaler according to the DeciderSpec.
+func (p *PIDScaler) Update(deciderSpec DeciderSpec) error {
+	p.specMux.Lock()
+	defer p.specMux.Unlock()
+
+	p.deciderSpec = deciderSpec
+	return nil
+}
+
+// Scale runs one step of the controller and proposes the ready pod count
+// corrected by the controller output.
+func (p *PIDScaler) Scale(ctx context.Context, now time.Time) (int32, bool) {
+	logger := logging.FromContext(ctx)
+
+	metricKey := NewMetricKey(p.namespace, p.revision)
+	spec := p.latencyTuner.tune(logger, metricKey, p.currentSpec(), p.metricClient)
+	originalReadyPodsCount, observedStableValue, observedPanicValue, ok := observe(
+		logger, metricKey, spec, p.metricClient, p.podCounter)
+	if !ok {
+		return 0, false
+	}
+	readyPodsCount := math.Max(1, float64(originalReadyPodsCount))
+	reportObserved(logger, p.reporter, spec, observedStableValue, observedPanicValue)
+
+	// A positive error means the pods carry more than the target.
+	e := observedPanicValue/(readyPodsCount*spec.TargetValue) - 1
+
+	p.stateMux.Lock()
+	defer p.stateMux.Unlock()
+	var derivative float64
+	if !p.lastTime.IsZero() {
+		if dt := now.Sub(p.lastTime).Seconds(); dt > 0 {
+			limit := pidIntegralLimit / pidIntegralGain
+			p.integral = math.Max(-limit, math.Min(limit, p.integral+e*dt))
+			derivative = (e - p.lastError) / dt
+		}
+	}
+	p.lastError = e
+	p.lastTime = now
+	p.explanation = Explanation{
+		ReadyPods:           originalReadyPodsCount,
+		ObservedStableValue: observedStableValue,
+		ObservedPanicValue:  observedPanicValue,
+		TargetValue:         spec.TargetValue,
+	}
+
+	output := pidProportionalGain*e + pidIntegralGain*p.integral + pidDerivativeGain*derivative
+	logger.Debugf("PID: Error %0.3f, integral %0.3f, derivative %0.3f, output %0.3f.", e, p.integral, derivative, output)
+
+	maxScaleUp, maxScaleDown := scaleLimits(spec, readyPodsCount)
+	desired := math.Max(0, math.Ceil(readyPodsCount*(1+output)))
+	desiredPodCount := int32(math.Min(math.Max(desired, maxScaleDown), maxScaleUp))
+
+	p.reporter.ReportDesiredPodCount(int64(desiredPodCount))
+	return desiredPodCount, true
+}
+
+// Explain returns the inputs of the latest proposal.
+func (p *PIDScaler) Explain() Explanation {
+	p.stateMux.Lock()
+	defer p.stateMux.Unlock()
+	return p.explanation
+}
+
+func (p *PIDScaler) currentSpec() DeciderSpec {
+	p.specMux.RLock()
+	defer p.specMux.RUnlock()
+	return p.deciderSpec
+}
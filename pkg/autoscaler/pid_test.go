@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/resources"
+)
+
+func TestNewPIDScalerErrors(t *testing.T) {
+	if _, err := NewPIDScaler(testNamespace, testRevision, &testMetricClient{}, nil,
+		DeciderSpec{TargetValue: 10, ServiceName: testService}, &mockReporter{}); err == nil {
+		t.Error("Expected error when ReadyPodCounter interface is nil, but got none.")
+	}
+
+	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
+	if _, err := NewPIDScaler(testNamespace, testRevision, &testMetricClient{}, podCounter,
+		DeciderSpec{TargetValue: 10, ServiceName: testService}, nil); err == nil {
+		t.Error("Expected error when stats reporter is nil, but got none.")
+	}
+}
+
+func TestPIDScalerNoData(t *testing.T) {
+	metrics := &testMetricClient{err: errors.New("no metrics")}
+	p := newTestPIDScaler(10.0, metrics)
+	expectUniScale(t, p, time.Now(), 0, false)
+}
+
+func TestPIDScalerProportional(t *testing.T) {
+	metrics := &testMetricClient{panicConcurrency: 100.0}
+	p := newTestPIDScaler(10.0, metrics)
+	endpoints(1)
+
+	// Without history only the proportional term acts: observed/target pods.
+	now := time.Now()
+	expectUniScale(t, p, now, 10, true)
+	endpoints(10)
+
+	// On target, but the derivative damps the sudden drop of the error
+	// by 0.05 * (0 - 9) / 2s = -0.225.
+	now = now.Add(2 * time.Second)
+	expectUniScale(t, p, now, 8, true)
+
+	// Steady on target.
+	now = now.Add(2 * time.Second)
+	expectUniScale(t, p, now, 10, true)
+}
+
+func TestPIDScalerIntegral(t *testing.T) {
+	metrics := &testMetricClient{panicConcurrency: 110.0}
+	p := newTestPIDScaler(10.0, metrics)
+	endpoints(10)
+
+	// 10% over target.
+	now := time.Now()
+	expectUniScale(t, p, now, 11, true)
+
+	// The persisting error accumulates: 0.1 + 0.1 * (0.1 * 2s) = 0.12.
+	now = now.Add(2 * time.Second)
+	expectUniScale(t, p, now, 12, true)
+}
+
+func TestPIDScalerAntiWindup(t *testing.T) {
+	metrics := &testMetricClient{panicConcurrency: 1000.0}
+	p := newTestPIDScaler(10.0, metrics)
+	endpoints(10)
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		now = now.Add(10 * time.Second)
+		expectUniScale(t, p, now, 100, true)
+	}
+	if got, want := p.integral, pidIntegralLimit/pidIntegralGain; got != want {
+		t.Errorf("integral = %v, want %v", got, want)
+	}
+}
+
+func TestPIDScalerScaleToZero(t *testing.T) {
+	metrics := &testMetricClient{panicConcurrency: 0.0}
+	p := newTestPIDScaler(10.0, metrics)
+	endpoints(1)
+	expectUniScale(t, p, time.Now(), 0, true)
+}
+
+func newTestPIDScaler(target float64, metrics MetricClient) *PIDScaler {
+	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
+	p, _ := NewPIDScaler(testNamespace, testRevision, metrics, podCounter, DeciderSpec{
+		Algorithm:      autoscaling.PIDAlgorithm,
+		ScalingMetric:  autoscaling.Concurrency,
+		TargetValue:    target,
+		MaxScaleUpRate: 10.0,
+		StableWindow:   stableWindow,
+		ServiceName:    testService,
+	}, &mockReporter{})
+	return p
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/networking"
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// podScrapeTimeout is the time every single pod has to respond to a scrape.
+const podScrapeTimeout = time.Second
+
+// podScrapeClient is the http client used to scrape pods. It is shared by
+// every pod scraper.
+var podScrapeClient = &http.Client{
+	Transport: &http.Transport{
+		// Do not use the cached connection
+		DisableKeepAlives: true,
+	},
+	Timeout: podScrapeTimeout,
+}
+
+// PodScrapeFailure records the failed scrapes of a pod since the last
+// successful one.
+type PodScrapeFailure struct {
+	// Count is the number of consecutive failed scrapes.
+	Count int
+	// Since is the time of the first of these failed scrapes.
+	Since time.Time
+	// LastError is the error of the last failed scrape.
+	LastError string
+}
+
+// PodScraper scrapes Revision metrics directly from a sample of its ready
+// pods, which it looks up in the Endpoints of the scrape target. Unlike the
+// ServiceScraper, it never scrapes a pod twice in a single Scrape and always
+// picks the same pods as long as they are ready.
+type PodScraper struct {
+	sClient         scrapeClient
+	endpointsLister corev1listers.EndpointsLister
+	metricKey       string
+
+	targetMu  sync.RWMutex
+	namespace string
+	target    string
+
+	failuresMu sync.Mutex
+	failures   map[string]PodScrapeFailure
+}
+
+// NewPodScraper creates a new StatsScraper for the Revision which the given
+// Metric is responsible for.
+func NewPodScraper(metric *Metric, endpointsLister corev1listers.EndpointsLister) (*PodScraper, error) {
+	sClient, err := newHTTPScrapeClient(podScrapeClient)
+	if err != nil {
+		return nil, err
+	}
+	return newPodScraperWithClient(metric, endpointsLister, sClient)
+}
+
+func newPodScraperWithClient(
+	metric *Metric,
+	endpointsLister corev1listers.EndpointsLister,
+	sClient scrapeClient) (*PodScraper, error) {
+	if metric == nil {
+		return nil, errors.New("metric must not be nil")
+	}
+	if endpointsLister == nil {
+		return nil, errors.New("endpoints lister must not be nil")
+	}
+	if sClient == nil {
+		return nil, errors.New("scrape client must not be nil")
+	}
+	revName := metric.Labels[serving.RevisionLabelKey]
+	if revName == "" {
+		return nil, fmt.Errorf("no Revision label found for Metric %s", metric.Name)
+	}
+
+	return &PodScraper{
+		sClient:         sClient,
+		endpointsLister: endpointsLister,
+		metricKey:       NewMetricKey(metric.Namespace, metric.Name),
+		namespace:       metric.Namespace,
+		target:          metric.Spec.ScrapeTarget,
+		failures:        make(map[string]PodScrapeFailure),
+	}, nil
+}
+
+// scrapePod is a ready pod of the scrape target.
+type scrapePod struct {
+	name string
+	url  string
+}
+
+// UpdateTarget implements StatsScraper interface.
+func (s *PodScraper) UpdateTarget(target, ns string) {
+	s.targetMu.Lock()
+	defer s.targetMu.Unlock()
+	s.target = target
+	s.namespace = ns
+}
+
+func (s *PodScraper) currentTarget() (string, string) {
+	s.targetMu.RLock()
+	defer s.targetMu.RUnlock()
+	return s.target, s.namespace
+}
+
+// Failures returns the pods whose last scrape failed, by pod name.
+func (s *PodScraper) Failures() map[string]PodScrapeFailure {
+	s.failuresMu.Lock()
+	defer s.failuresMu.Unlock()
+	ret := make(map[string]PodScrapeFailure, len(s.failures))
+	for name, f := range s.failures {
+		ret[name] = f
+	}
+	return ret
+}
+
+// Scrape implements StatsScraper interface.
+func (s *PodScraper) Scrape() (*StatMessage, error) {
+	target, ns := s.currentTarget()
+	endpoints, err := s.endpointsLister.Endpoints(ns).Get(target)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get endpoints")
+	}
+	pods := readyPods(endpoints)
+	s.forgetGonePods(pods)
+	if len(pods) == 0 {
+		return nil, nil
+	}
+
+	sample := s.sample(pods, populationMeanSampleSize(len(pods)))
+	type result struct {
+		pod  scrapePod
+		stat *Stat
+		err  error
+	}
+	resultCh := make(chan result, len(sample))
+	var wg sync.WaitGroup
+	for _, pod := range sample {
+		wg.Add(1)
+		go func(pod scrapePod) {
+			defer wg.Done()
+			stat, err := s.sClient.Scrape(pod.url)
+			resultCh <- result{pod: pod, stat: stat, err: err}
+		}(pod)
+	}
+	wg.Wait()
+	close(resultCh)
+
+	now := time.Now()
+	stats := make([]*Stat, 0, len(sample))
+	var lastErr error
+	for r := range resultCh {
+		if r.err != nil {
+			s.recordFailure(r.pod.name, r.err, now)
+			lastErr = r.err
+			continue
+		}
+		s.recordSuccess(r.pod.name)
+		stats = append(stats, r.stat)
+	}
+
+	// Return the inner error if all of the scrape calls failed.
+	if len(stats) == 0 {
+		return nil, errors.Wrapf(lastErr, "fail to get a successful scrape for %d pods", len(sample))
+	}
+	return extrapolate(s.metricKey, stats, len(pods)), nil
+}
+
+// sample deterministically picks size distinct pods. Every revision orders
+// the pods differently, so the same pods aren't scraped for all revisions
+// sharing them.
+func (s *PodScraper) sample(pods []scrapePod, size int) []scrapePod {
+	rank := make(map[string]uint64, len(pods))
+	for _, pod := range pods {
+		h := fnv.New64a()
+		h.Write([]byte(s.metricKey + "/" + pod.name))
+		rank[pod.name] = h.Sum64()
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return rank[pods[i].name] < rank[pods[j].name]
+	})
+	if size > len(pods) {
+		size = len(pods)
+	}
+	return pods[:size]
+}
+
+func (s *PodScraper) recordFailure(name string, err error, now time.Time) {
+	s.failuresMu.Lock()
+	defer s.failuresMu.Unlock()
+	f, ok := s.failures[name]
+	if !ok {
+		f.Since = now
+	}
+	f.Count++
+	f.LastError = err.Error()
+	s.failures[name] = f
+}
+
+func (s *PodScraper) recordSuccess(name string) {
+	s.failuresMu.Lock()
+	defer s.failuresMu.Unlock()
+	delete(s.failures, name)
+}
+
+// forgetGonePods drops the failures of pods that are no longer ready.
+func (s *PodScraper) forgetGonePods(pods []scrapePod) {
+	ready := make(map[string]struct{}, len(pods))
+	for _, pod := range pods {
+		ready[pod.name] = struct{}{}
+	}
+
+	s.failuresMu.Lock()
+	defer s.failuresMu.Unlock()
+	for name := range s.failures {
+		if _, ok := ready[name]; !ok {
+			delete(s.failures, name)
+		}
+	}
+}
+
+// readyPods returns the ready pods of the given Endpoints. Pods are named
+// after the object the address points to, or the address if there is none.
+func readyPods(endpoints *corev1.Endpoints) []scrapePod {
+	var pods []scrapePod
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			name := address.IP
+			if address.TargetRef != nil && address.TargetRef.Name != "" {
+				name = address.TargetRef.Name
+			}
+			pods = append(pods, scrapePod{
+				name: name,
+				url:  urlFromPodIP(address.IP),
+			})
+		}
+	}
+	return pods
+}
+
+func urlFromPodIP(ip string) string {
+	return fmt.Sprintf("http://%s/metrics",
+		net.JoinHostPort(ip, strconv.Itoa(networking.AutoscalingQueueMetricsPort)))
+}
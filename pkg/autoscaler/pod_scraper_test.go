@@ -0,0 +1,244 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestNewPodScraperWithClient_ErrorCases(t *testing.T) {
+	metric := testMetric()
+	invalidMetric := testMetric()
+	invalidMetric.Labels = map[string]string{}
+	lister := kubeInformer.Core().V1().Endpoints().Lister()
+	client := newTestScrapeClient(testStats, []error{nil})
+
+	tests := []struct {
+		name   string
+		metric *Metric
+		client scrapeClient
+		want   string
+	}{{
+		name:   "Empty Metric",
+		client: client,
+		want:   "metric must not be nil",
+	}, {
+		name:   "Missing revision label in Metric",
+		metric: invalidMetric,
+		client: client,
+		want:   "no Revision label found for Metric test-revision",
+	}, {
+		name:   "Empty scrape client",
+		metric: metric,
+		want:   "scrape client must not be nil",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := newPodScraperWithClient(test.metric, lister, test.client); err == nil || err.Error() != test.want {
+				t.Errorf("newPodScraperWithClient() = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestPodScraperScrapesDistinctPods(t *testing.T) {
+	client := &urlScrapeClient{stats: map[string]*Stat{}, errs: map[string]error{}}
+	scraper := podScraperForTest(t, client)
+
+	// Make an Endpoints with 10 pods, 7 of which are sampled.
+	endpoints(10)
+
+	got, err := scraper.Scrape()
+	if err != nil {
+		t.Fatalf("Scrape() = %v", err)
+	}
+	first := client.scraped()
+	if want := populationMeanSampleSize(10); first.Len() != want || len(client.urls) != want {
+		t.Errorf("Scraped %d distinct pods in %d scrapes, want %d", first.Len(), len(client.urls), want)
+	}
+	// Every pod reports a concurrency of 1.
+	if got.Stat.AverageConcurrentRequests != 10 {
+		t.Errorf("AverageConcurrentRequests = %v, want %v", got.Stat.AverageConcurrentRequests, 10)
+	}
+	if got.Key != testKPAKey || got.Stat.PodName != scraperPodName {
+		t.Errorf("Scrape() = %v, want key %s and pod %s", got, testKPAKey, scraperPodName)
+	}
+
+	// The next scrape picks the same pods.
+	client.reset()
+	if _, err := scraper.Scrape(); err != nil {
+		t.Fatalf("Scrape() = %v", err)
+	}
+	if second := client.scraped(); !first.Equal(second) {
+		t.Errorf("Scraped pods changed from %v to %v", first.List(), second.List())
+	}
+}
+
+func TestPodScraperRecordsFailures(t *testing.T) {
+	errTest := errors.New("test")
+	client := &urlScrapeClient{
+		stats: map[string]*Stat{},
+		errs:  map[string]error{urlFromPodIP("127.0.0.2"): errTest},
+	}
+	scraper := podScraperForTest(t, client)
+
+	// Make an Endpoints with 3 pods, which are all sampled.
+	endpoints(3)
+
+	for i := 0; i < 2; i++ {
+		got, err := scraper.Scrape()
+		if err != nil {
+			t.Fatalf("Scrape() = %v", err)
+		}
+		// The failed pod is left out of the average.
+		if got.Stat.AverageConcurrentRequests != 3 {
+			t.Errorf("AverageConcurrentRequests = %v, want %v", got.Stat.AverageConcurrentRequests, 3)
+		}
+	}
+	failures := scraper.Failures()
+	if len(failures) != 1 || failures["127.0.0.2"].Count != 2 || failures["127.0.0.2"].LastError != "test" {
+		t.Errorf("Failures() = %v, want 2 failures of 127.0.0.2", failures)
+	}
+
+	// A successful scrape clears the failures.
+	delete(client.errs, urlFromPodIP("127.0.0.2"))
+	if _, err := scraper.Scrape(); err != nil {
+		t.Fatalf("Scrape() = %v", err)
+	}
+	if failures := scraper.Failures(); len(failures) != 0 {
+		t.Errorf("Failures() = %v, want none", failures)
+	}
+
+	// Failures of pods that are gone are forgotten.
+	client.errs[urlFromPodIP("127.0.0.3")] = errTest
+	if _, err := scraper.Scrape(); err != nil {
+		t.Fatalf("Scrape() = %v", err)
+	}
+	endpoints(2)
+	if _, err := scraper.Scrape(); err != nil {
+		t.Fatalf("Scrape() = %v", err)
+	}
+	if failures := scraper.Failures(); len(failures) != 0 {
+		t.Errorf("Failures() = %v, want none", failures)
+	}
+}
+
+func TestPodScraperReportErrorIfAllFail(t *testing.T) {
+	errTest := errors.New("test")
+	client := &urlScrapeClient{
+		stats: map[string]*Stat{},
+		errs: map[string]error{
+			urlFromPodIP("127.0.0.1"): errTest,
+			urlFromPodIP("127.0.0.2"): errTest,
+		},
+	}
+	scraper := podScraperForTest(t, client)
+
+	// Make an Endpoints with 2 pods.
+	endpoints(2)
+
+	if _, err := scraper.Scrape(); errors.Cause(err) != errTest {
+		t.Errorf("Scrape() = %v, want %v", err, errTest)
+	}
+}
+
+func TestPodScraperNoPods(t *testing.T) {
+	scraper := podScraperForTest(t, newTestScrapeClient(testStats, []error{nil}))
+
+	// Make an Endpoints with 0 pods.
+	endpoints(0)
+
+	if got, err := scraper.Scrape(); got != nil || err != nil {
+		t.Errorf("Scrape() = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestPodScraperUpdateTarget(t *testing.T) {
+	scraper := podScraperForTest(t, newTestScrapeClient(testStats, []error{nil}))
+	scraper.UpdateTarget("last-words", "said-again")
+	if target, ns := scraper.currentTarget(); target != "last-words" || ns != "said-again" {
+		t.Errorf("currentTarget() = %s, %s; want last-words, said-again", target, ns)
+	}
+}
+
+func TestURLFromPodIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{{
+		ip:   "10.0.0.1",
+		want: "http://10.0.0.1:9090/metrics",
+	}, {
+		ip:   "fd00::1",
+		want: "http://[fd00::1]:9090/metrics",
+	}}
+	for _, test := range tests {
+		if got := urlFromPodIP(test.ip); got != test.want {
+			t.Errorf("urlFromPodIP(%q) = %s, want: %s, diff: %s", test.ip, got, test.want, cmp.Diff(test.want, got))
+		}
+	}
+}
+
+func podScraperForTest(t *testing.T, sClient scrapeClient) *PodScraper {
+	metric := testMetric()
+	metric.Spec.ScrapeTarget = testService
+	scraper, err := newPodScraperWithClient(metric, kubeInformer.Core().V1().Endpoints().Lister(), sClient)
+	if err != nil {
+		t.Fatalf("newPodScraperWithClient() = %v", err)
+	}
+	return scraper
+}
+
+// urlScrapeClient returns the stats and errors by URL and records the URLs
+// scraped. Unknown URLs report a concurrency of 1.
+type urlScrapeClient struct {
+	mutex sync.Mutex
+	stats map[string]*Stat
+	errs  map[string]error
+	urls  []string
+}
+
+func (c *urlScrapeClient) Scrape(url string) (*Stat, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.urls = append(c.urls, url)
+	if err := c.errs[url]; err != nil {
+		return nil, err
+	}
+	if stat, ok := c.stats[url]; ok {
+		return stat, nil
+	}
+	return &Stat{AverageConcurrentRequests: 1}, nil
+}
+
+func (c *urlScrapeClient) scraped() sets.String {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return sets.NewString(c.urls...)
+}
+
+func (c *urlScrapeClient) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.urls = nil
+}
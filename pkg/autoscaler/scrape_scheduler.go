@@ -0,0 +1,358 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"container/heap"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const (
+	// defaultScrapeWorkers is the default number of scrapes that run in
+	// parallel.
+	defaultScrapeWorkers = 100
+	// defaultScrapeJitter is the default fraction of the scrape interval
+	// each scrape is randomly moved by.
+	defaultScrapeJitter = 0.1
+	// defaultScrapeMaxBackoff is the default longest interval between two
+	// scrapes of a revision that has no pods.
+	defaultScrapeMaxBackoff = 10 * time.Second
+)
+
+// ScrapeSchedulerOptions configures a ScrapeScheduler.
+type ScrapeSchedulerOptions struct {
+	// Workers is the maximum number of scrapes running in parallel.
+	Workers int
+	// Interval is the time between two scrapes of the same revision.
+	Interval time.Duration
+	// Jitter is the fraction of the Interval each scrape is randomly
+	// moved by, e.g. 0.1 moves scrapes of a 1s interval by up to 100ms.
+	Jitter float64
+	// MaxBackoff is the longest time between two scrapes of a revision
+	// whose scrapes return no pods. The time doubles with every such
+	// scrape, starting at the Interval.
+	MaxBackoff time.Duration
+}
+
+// DefaultScrapeSchedulerOptions returns the options used by NewMetricCollector.
+func DefaultScrapeSchedulerOptions() ScrapeSchedulerOptions {
+	return ScrapeSchedulerOptions{
+		Workers:    defaultScrapeWorkers,
+		Interval:   scrapeTickInterval,
+		Jitter:     defaultScrapeJitter,
+		MaxBackoff: defaultScrapeMaxBackoff,
+	}
+}
+
+// scrapeFunc scrapes one revision and reports whether it found any pods.
+type scrapeFunc func() bool
+
+// ScrapeScheduler runs the scrapes of all revisions on a bounded pool of
+// workers. The first scrape of every revision is offset by a hash of its key
+// so the scrapes spread evenly over the interval, rather than all firing at
+// the start of every tick. The goroutines only run while there are
+// revisions to scrape.
+type ScrapeScheduler struct {
+	opts   ScrapeSchedulerOptions
+	clock  clock.Clock
+	logger *zap.SugaredLogger
+
+	// mux guards all of the fields below.
+	mux    sync.Mutex
+	jobs   map[string]*scrapeJob
+	queue  scrapeQueue
+	random *rand.Rand
+	wakeCh chan struct{}
+	stopCh chan struct{}
+	grp    *sync.WaitGroup
+}
+
+// scrapeJob is a revision scheduled for scraping.
+type scrapeJob struct {
+	key    string
+	scrape scrapeFunc
+	due    time.Time
+	// idle is the number of consecutive scrapes that found no pods.
+	idle int
+	// index is the position in the scrapeQueue, or -1 while the job
+	// is being scraped or after it has been removed.
+	index   int
+	removed bool
+}
+
+// NewScrapeScheduler creates a new ScrapeScheduler. Options that are not set
+// take their default value.
+func NewScrapeScheduler(opts ScrapeSchedulerOptions, clock clock.Clock, logger *zap.SugaredLogger) *ScrapeScheduler {
+	defaults := DefaultScrapeSchedulerOptions()
+	if opts.Workers <= 0 {
+		opts.Workers = defaults.Workers
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaults.Interval
+	}
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	}
+	if opts.Jitter >= 1 {
+		opts.Jitter = defaults.Jitter
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaults.MaxBackoff
+	}
+	if opts.MaxBackoff < opts.Interval {
+		opts.MaxBackoff = opts.Interval
+	}
+	return &ScrapeScheduler{
+		opts:   opts,
+		clock:  clock,
+		logger: logger,
+		jobs:   make(map[string]*scrapeJob),
+		random: rand.New(rand.NewSource(clock.Now().UnixNano())),
+		wakeCh: make(chan struct{}, 1),
+	}
+}
+
+// add schedules the given scrape of the revision with the given key every
+// interval. Adding a key twice replaces the previous scrape.
+func (s *ScrapeScheduler) add(key string, scrape scrapeFunc) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.removeLocked(key)
+	job := &scrapeJob{
+		key:    key,
+		scrape: scrape,
+		due:    s.clock.Now().Add(s.offset(key)),
+	}
+	s.jobs[key] = job
+	heap.Push(&s.queue, job)
+	s.wake()
+
+	if s.stopCh == nil {
+		s.start()
+	}
+}
+
+// remove stops the scrapes of the revision with the given key. The last
+// removal stops all of the goroutines and waits for them to finish.
+func (s *ScrapeScheduler) remove(key string) {
+	s.removeAsync(key)()
+}
+
+// removeAsync is like remove, but returns the wait for the goroutines to
+// finish rather than waiting, so callers can first release locks the
+// running scrapes might need.
+func (s *ScrapeScheduler) removeAsync(key string) (wait func()) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.removeLocked(key)
+	if len(s.jobs) > 0 || s.stopCh == nil {
+		return func() {}
+	}
+	close(s.stopCh)
+	s.stopCh = nil
+	return s.grp.Wait
+}
+
+// reset drops the backoff of the revision with the given key, so it is
+// scraped again within the interval.
+func (s *ScrapeScheduler) reset(key string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	job, ok := s.jobs[key]
+	if !ok || job.idle == 0 {
+		return
+	}
+	job.idle = 0
+	if due := s.clock.Now().Add(s.offset(key)); job.index >= 0 && job.due.After(due) {
+		job.due = due
+		heap.Fix(&s.queue, job.index)
+		s.wake()
+	}
+}
+
+func (s *ScrapeScheduler) removeLocked(key string) {
+	job, ok := s.jobs[key]
+	if !ok {
+		return
+	}
+	job.removed = true
+	if job.index >= 0 {
+		heap.Remove(&s.queue, job.index)
+	}
+	delete(s.jobs, key)
+}
+
+// start starts the dispatcher and the workers. Must be called with the mux
+// held.
+func (s *ScrapeScheduler) start() {
+	// Every start gets its own channels and WaitGroup, so a restart does
+	// not race with the goroutines of the previous run shutting down.
+	s.stopCh = make(chan struct{})
+	s.grp = &sync.WaitGroup{}
+	workCh := make(chan *scrapeJob)
+
+	grp := s.grp
+	grp.Add(1 + s.opts.Workers)
+	go func(stopCh <-chan struct{}) {
+		defer grp.Done()
+		s.dispatch(workCh, stopCh)
+	}(s.stopCh)
+	for i := 0; i < s.opts.Workers; i++ {
+		go func(stopCh <-chan struct{}) {
+			defer grp.Done()
+			for {
+				select {
+				case <-stopCh:
+					return
+				case job := <-workCh:
+					s.done(job, job.scrape())
+				}
+			}
+		}(s.stopCh)
+	}
+}
+
+// dispatch hands the jobs to the workers as they become due. If all workers
+// are busy, dispatching waits for them rather than queueing more work.
+func (s *ScrapeScheduler) dispatch(workCh chan<- *scrapeJob, stopCh <-chan struct{}) {
+	for {
+		s.mux.Lock()
+		now := s.clock.Now()
+		var due []*scrapeJob
+		for s.queue.Len() > 0 && !s.queue[0].due.After(now) {
+			due = append(due, heap.Pop(&s.queue).(*scrapeJob))
+		}
+		var timer clock.Timer
+		var timerCh <-chan time.Time
+		if len(due) == 0 && s.queue.Len() > 0 {
+			timer = s.clock.NewTimer(s.queue[0].due.Sub(now))
+			timerCh = timer.C()
+		}
+		s.mux.Unlock()
+
+		for _, job := range due {
+			select {
+			case <-stopCh:
+				return
+			case workCh <- job:
+			}
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		select {
+		case <-stopCh:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-s.wakeCh:
+		case <-timerCh:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// done schedules the next scrape of the job. Revisions without pods are
+// scraped with an exponential backoff.
+func (s *ScrapeScheduler) done(job *scrapeJob, hasPods bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if job.removed {
+		return
+	}
+	if hasPods {
+		job.idle = 0
+	} else {
+		job.idle++
+	}
+
+	delay := s.opts.Interval
+	for i := 1; i < job.idle && delay < s.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.opts.MaxBackoff {
+		delay = s.opts.MaxBackoff
+	}
+	if s.opts.Jitter > 0 {
+		delay += time.Duration((2*s.random.Float64() - 1) * s.opts.Jitter * float64(s.opts.Interval))
+	}
+
+	// Don't try to catch up on scrapes missed while the workers were busy.
+	job.due = job.due.Add(delay)
+	if now := s.clock.Now(); job.due.Before(now) {
+		job.due = now
+	}
+	heap.Push(&s.queue, job)
+	s.wake()
+}
+
+// offset returns a stable offset within the interval for the given key.
+func (s *ScrapeScheduler) offset(key string) time.Duration {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return time.Duration(h.Sum64() % uint64(s.opts.Interval))
+}
+
+// wake makes the dispatcher reconsider the earliest job.
+func (s *ScrapeScheduler) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// scrapeQueue is a min-heap of jobs ordered by their due time.
+type scrapeQueue []*scrapeJob
+
+func (q scrapeQueue) Len() int           { return len(q) }
+func (q scrapeQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q scrapeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scrapeQueue) Push(x interface{}) {
+	job := x.(*scrapeJob)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+func (q *scrapeQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	job.index = -1
+	*q = old[:n-1]
+	return job
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/knative/pkg/logging/testing"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestScrapeSchedulerOptionsDefaults(t *testing.T) {
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{Jitter: -1}, clock.RealClock{}, TestLogger(t))
+	want := DefaultScrapeSchedulerOptions()
+	want.Jitter = 0
+	if s.opts != want {
+		t.Errorf("opts = %+v, want %+v", s.opts, want)
+	}
+
+	s = NewScrapeScheduler(ScrapeSchedulerOptions{Jitter: 1}, clock.RealClock{}, TestLogger(t))
+	if want := DefaultScrapeSchedulerOptions(); s.opts != want {
+		t.Errorf("opts = %+v, want %+v", s.opts, want)
+	}
+}
+
+func TestScrapeSchedulerSpreadsScrapes(t *testing.T) {
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{Interval: time.Second}, clock.RealClock{}, TestLogger(t))
+
+	// Count the offsets per tenth of the interval.
+	const keys = 5000
+	var slots [10]int
+	for i := 0; i < keys; i++ {
+		offset := s.offset(fmt.Sprintf("ns/revision-%d", i))
+		if offset < 0 || offset >= time.Second {
+			t.Fatalf("offset = %v, want within [0, 1s)", offset)
+		}
+		slots[offset/(100*time.Millisecond)]++
+	}
+	for i, n := range slots {
+		if n < keys/10*8/10 || n > keys/10*12/10 {
+			t.Errorf("slot %d has %d scrapes, want about %d", i, n, keys/10)
+		}
+	}
+
+	if s.offset("ns/revision") != s.offset("ns/revision") {
+		t.Error("offset is not stable")
+	}
+}
+
+func TestScrapeSchedulerBackoff(t *testing.T) {
+	now := time.Now()
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{
+		Interval:   time.Second,
+		MaxBackoff: 5 * time.Second,
+	}, clock.NewFakeClock(now), TestLogger(t))
+	job := &scrapeJob{key: "ns/revision", due: now}
+	s.jobs[job.key] = job
+
+	tests := []struct {
+		name    string
+		hasPods bool
+		want    time.Duration
+	}{{
+		name:    "pods",
+		hasPods: true,
+		want:    time.Second,
+	}, {
+		name: "first scrape without pods",
+		want: time.Second,
+	}, {
+		name: "second scrape without pods",
+		want: 2 * time.Second,
+	}, {
+		name: "third scrape without pods",
+		want: 4 * time.Second,
+	}, {
+		name: "capped",
+		want: 5 * time.Second,
+	}, {
+		name:    "pods again",
+		hasPods: true,
+		want:    time.Second,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			queueLen := s.queue.Len()
+			due := job.due
+			s.done(job, test.hasPods)
+			if got := job.due.Sub(due); got != test.want {
+				t.Errorf("next scrape in %v, want %v", got, test.want)
+			}
+			if s.queue.Len() != queueLen+1 {
+				t.Errorf("queue length = %d, want %d", s.queue.Len(), queueLen+1)
+			}
+			s.queue = nil
+		})
+	}
+}
+
+func TestScrapeSchedulerJitter(t *testing.T) {
+	now := time.Now()
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{
+		Interval: time.Second,
+		Jitter:   0.1,
+	}, clock.NewFakeClock(now), TestLogger(t))
+	job := &scrapeJob{key: "ns/revision", due: now}
+	s.jobs[job.key] = job
+
+	var jittered bool
+	for i := 0; i < 100; i++ {
+		due := job.due
+		s.done(job, true)
+		got := job.due.Sub(due)
+		if got < 900*time.Millisecond || got > 1100*time.Millisecond {
+			t.Fatalf("next scrape in %v, want within 1s±100ms", got)
+		}
+		jittered = jittered || got != time.Second
+	}
+	if !jittered {
+		t.Error("scrapes were not jittered")
+	}
+}
+
+func TestScrapeSchedulerDoesNotCatchUp(t *testing.T) {
+	now := time.Now()
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{Interval: time.Second}, clock.NewFakeClock(now), TestLogger(t))
+	job := &scrapeJob{key: "ns/revision", due: now.Add(-time.Minute)}
+	s.jobs[job.key] = job
+
+	s.done(job, true)
+	if !job.due.Equal(now) {
+		t.Errorf("due = %v, want %v", job.due, now)
+	}
+}
+
+func TestScrapeSchedulerScrapes(t *testing.T) {
+	clk := clock.NewFakeClock(time.Now())
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{Interval: time.Second}, clk, TestLogger(t))
+
+	var first, second int32
+	s.add("ns/first", func() bool {
+		atomic.AddInt32(&first, 1)
+		return true
+	})
+	s.add("ns/second", func() bool {
+		atomic.AddInt32(&second, 1)
+		return true
+	})
+
+	for i := int32(1); i <= 3; i++ {
+		clk.Step(time.Second)
+		if err := wait.PollImmediate(time.Millisecond, 2*time.Second, func() (bool, error) {
+			return atomic.LoadInt32(&first) == i && atomic.LoadInt32(&second) == i, nil
+		}); err != nil {
+			t.Fatalf("Got %d and %d scrapes, want %d", atomic.LoadInt32(&first), atomic.LoadInt32(&second), i)
+		}
+	}
+
+	// Removed revisions are no longer scraped.
+	s.remove("ns/first")
+	clk.Step(time.Second)
+	if err := wait.PollImmediate(time.Millisecond, 2*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&second) == 4, nil
+	}); err != nil {
+		t.Fatalf("Got %d scrapes, want 4", atomic.LoadInt32(&second))
+	}
+	if got := atomic.LoadInt32(&first); got != 3 {
+		t.Errorf("Got %d scrapes of a removed revision, want 3", got)
+	}
+
+	// Removing the last revision stops the goroutines, adding one
+	// restarts them.
+	s.remove("ns/second")
+	if s.stopCh != nil {
+		t.Error("stopCh is set after all revisions have been removed")
+	}
+	s.add("ns/first", func() bool {
+		atomic.AddInt32(&first, 1)
+		return true
+	})
+	clk.Step(time.Second)
+	if err := wait.PollImmediate(time.Millisecond, 2*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&first) == 4, nil
+	}); err != nil {
+		t.Fatalf("Got %d scrapes, want 4", atomic.LoadInt32(&first))
+	}
+	s.remove("ns/first")
+}
+
+func TestScrapeSchedulerBoundsWorkers(t *testing.T) {
+	clk := clock.NewFakeClock(time.Now())
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{Workers: 2, Interval: time.Second}, clk, TestLogger(t))
+
+	var (
+		mux              sync.Mutex
+		running, maxSeen int
+		done             int32
+	)
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		s.add(fmt.Sprintf("ns/revision-%d", i), func() bool {
+			mux.Lock()
+			running++
+			if running > maxSeen {
+				maxSeen = running
+			}
+			mux.Unlock()
+
+			<-release
+
+			mux.Lock()
+			running--
+			mux.Unlock()
+			atomic.AddInt32(&done, 1)
+			return true
+		})
+	}
+
+	clk.Step(time.Second)
+	// Wait for the workers to pick up as many scrapes as they can.
+	wait.PollImmediate(time.Millisecond, 100*time.Millisecond, func() (bool, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		return running > 2, nil
+	})
+	close(release)
+	if err := wait.PollImmediate(time.Millisecond, 2*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&done) >= 10, nil
+	}); err != nil {
+		t.Fatalf("Got %d scrapes, want 10", atomic.LoadInt32(&done))
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	if maxSeen != 2 {
+		t.Errorf("Got %d scrapes in parallel, want 2", maxSeen)
+	}
+	for i := 0; i < 10; i++ {
+		s.remove(fmt.Sprintf("ns/revision-%d", i))
+	}
+}
+
+func TestScrapeSchedulerReset(t *testing.T) {
+	now := time.Now()
+	s := NewScrapeScheduler(ScrapeSchedulerOptions{Interval: time.Second}, clock.NewFakeClock(now), TestLogger(t))
+	job := &scrapeJob{key: "ns/revision", due: now, idle: 5}
+	s.jobs[job.key] = job
+	s.done(job, false)
+
+	s.reset(job.key)
+	if job.idle != 0 {
+		t.Errorf("idle = %d, want 0", job.idle)
+	}
+	if want := now.Add(s.offset(job.key)); !job.due.Equal(want) {
+		t.Errorf("due = %v, want %v", job.due, want)
+	}
+}
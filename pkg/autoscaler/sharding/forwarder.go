@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/statproto"
+	"github.com/knative/serving/pkg/autoscaler/statserver"
+	"go.uber.org/zap"
+)
+
+// statSink is the part of a statproto.Sender the Forwarder uses.
+type statSink interface {
+	Send(...*autoscaler.StatMessage) error
+	Shutdown() error
+}
+
+// Forwarder sends stats to the statserver of the replica owning them.
+type Forwarder struct {
+	port    int
+	logger  *zap.SugaredLogger
+	newSink func(target string) statSink
+
+	mux   sync.Mutex
+	sinks map[string]statSink
+}
+
+// NewForwarder creates a Forwarder sending to the statservers listening on
+// the given port of the replicas.
+func NewForwarder(port int, logger *zap.SugaredLogger) *Forwarder {
+	return &Forwarder{
+		port:   port,
+		logger: logger,
+		newSink: func(target string) statSink {
+			return statproto.NewSender(target, logger)
+		},
+		sinks: make(map[string]statSink),
+	}
+}
+
+// Forward sends the stat message to the given replica. A connection to the
+// replica is established on first use, messages sent before it is up are
+// dropped.
+func (f *Forwarder) Forward(member string, sm *autoscaler.StatMessage) error {
+	f.mux.Lock()
+	sink, ok := f.sinks[member]
+	if !ok {
+		target := fmt.Sprintf("ws://%s%s", net.JoinHostPort(member, strconv.Itoa(f.port)), statserver.ForwardedPath)
+		f.logger.Infof("Connecting to autoscaler replica at %s", target)
+		sink = f.newSink(target)
+		f.sinks[member] = sink
+	}
+	f.mux.Unlock()
+
+	return sink.Send(sm)
+}
+
+// Retain closes the connections to all replicas but the given ones.
+func (f *Forwarder) Retain(members []string) {
+	keep := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		keep[m] = struct{}{}
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for member, sink := range f.sinks {
+		if _, ok := keep[member]; !ok {
+			f.shutdown(member, sink)
+		}
+	}
+}
+
+// Shutdown closes all connections.
+func (f *Forwarder) Shutdown() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for member, sink := range f.sinks {
+		f.shutdown(member, sink)
+	}
+}
+
+func (f *Forwarder) shutdown(member string, sink statSink) {
+	if err := sink.Shutdown(); err != nil {
+		f.logger.Errorw("Failed to close connection to autoscaler replica "+member, zap.Error(err))
+	}
+	delete(f.sinks, member)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knative/serving/pkg/autoscaler"
+	"go.uber.org/zap"
+)
+
+func TestForwarder(t *testing.T) {
+	sinks := make(map[string]*testSink)
+	f := NewForwarder(8080, zap.NewNop().Sugar())
+	f.newSink = func(target string) statSink {
+		s := &testSink{}
+		sinks[target] = s
+		return s
+	}
+
+	sm1 := &autoscaler.StatMessage{Key: "ns/rev1"}
+	sm2 := &autoscaler.StatMessage{Key: "ns/rev2"}
+	f.Forward("10.0.0.1", sm1)
+	f.Forward("10.0.0.1", sm2)
+	f.Forward("fd00::2", sm2)
+
+	want := map[string][]*autoscaler.StatMessage{
+		"ws://10.0.0.1:8080/forwarded":  {sm1, sm2},
+		"ws://[fd00::2]:8080/forwarded": {sm2},
+	}
+	got := make(map[string][]*autoscaler.StatMessage, len(sinks))
+	for target, s := range sinks {
+		got[target] = s.sent
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Sent messages differed (-want, +got): %s", diff)
+	}
+
+	f.Retain([]string{"10.0.0.1"})
+	if !sinks["ws://[fd00::2]:8080/forwarded"].shutdown {
+		t.Error("Connection to removed replica was not closed")
+	}
+	if sinks["ws://10.0.0.1:8080/forwarded"].shutdown {
+		t.Error("Connection to retained replica was closed")
+	}
+
+	f.Shutdown()
+	if !sinks["ws://10.0.0.1:8080/forwarded"].shutdown {
+		t.Error("Connection was not closed on shutdown")
+	}
+}
+
+type testSink struct {
+	sent     []*autoscaler.StatMessage
+	shutdown bool
+}
+
+func (s *testSink) Send(sms ...*autoscaler.StatMessage) error {
+	s.sent = append(s.sent, sms...)
+	return nil
+}
+
+func (s *testSink) Shutdown() error {
+	s.shutdown = true
+	return nil
+}
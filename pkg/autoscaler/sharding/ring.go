@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the number of points every member occupies on the ring.
+// More points spread the keys more evenly across the members.
+const virtualNodes = 100
+
+// Ring assigns keys to members by consistent hashing, so adding or removing
+// a member only moves the keys of that member.
+type Ring struct {
+	hashes []uint64
+	owners map[uint64]string
+}
+
+// NewRing creates a Ring of the given members.
+func NewRing(members []string) *Ring {
+	r := &Ring{
+		hashes: make([]uint64, 0, len(members)*virtualNodes),
+		owners: make(map[uint64]string, len(members)*virtualNodes),
+	}
+	for _, m := range members {
+		for i := 0; i < virtualNodes; i++ {
+			h := hash(m + "#" + strconv.Itoa(i))
+			if _, ok := r.owners[h]; ok {
+				// Hash collision, the point already belongs to another member.
+				continue
+			}
+			r.owners[h] = m
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// Owner returns the member owning the key, or an empty string if the ring
+// has no members.
+func (r *Ring) Owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// Owners returns the n members following the key on the ring, the first of
+// them being its Owner. Like owners, the members assigned to a key only change
+// when one of them leaves or a new member takes the place of one of them.
+// Owners returns all the members if the ring has n or fewer of them.
+func (r *Ring) Owners(key string, n int) []string {
+	if len(r.hashes) == 0 || n <= 0 {
+		return nil
+	}
+	h := hash(key)
+	start := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	owners := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	for i := 0; i < len(r.hashes) && len(owners) < n; i++ {
+		o := r.owners[r.hashes[(start+i)%len(r.hashes)]]
+		if _, ok := seen[o]; ok {
+			continue
+		}
+		seen[o] = struct{}{}
+		owners = append(owners, o)
+	}
+	return owners
+}
+
+// hash returns a 64 bit hash of s. FNV alone maps similar strings, like
+// revision names differing in a suffix, to close hashes, so the result is
+// passed through the MurmurHash3 finalizer to spread them over the ring.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestRingOwner(t *testing.T) {
+	keys := make([]string, 3000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("namespace/revision-%d", i)
+	}
+
+	tests := []struct {
+		name    string
+		members []string
+	}{{
+		name:    "one member",
+		members: []string{"10.0.0.1"},
+	}, {
+		name:    "three members",
+		members: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+	}, {
+		name:    "five members",
+		members: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ring := NewRing(test.members)
+			counts := make(map[string]int, len(test.members))
+			for _, key := range keys {
+				counts[ring.Owner(key)]++
+			}
+
+			// Every member gets its fair share of the keys, give or take a third.
+			fair := float64(len(keys)) / float64(len(test.members))
+			for _, m := range test.members {
+				if got := float64(counts[m]); math.Abs(got-fair) > fair/3 {
+					t.Errorf("Member %s owns %v keys, want about %v", m, got, fair)
+				}
+			}
+			if len(counts) != len(test.members) {
+				t.Errorf("Keys are owned by %v, want only members %v", counts, test.members)
+			}
+		})
+	}
+}
+
+func TestRingStability(t *testing.T) {
+	before := NewRing([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	after := NewRing([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"})
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("namespace/revision-%d", i)
+		// Adding a member only moves keys to that member.
+		if b, a := before.Owner(key), after.Owner(key); b != a && a != "10.0.0.4" {
+			t.Errorf("Owner(%q) moved from %s to %s", key, b, a)
+		}
+	}
+}
+
+func TestRingOwners(t *testing.T) {
+	members := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}
+	ring := NewRing(members)
+
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{{
+		name: "none",
+		n:    0,
+		want: 0,
+	}, {
+		name: "one",
+		n:    1,
+		want: 1,
+	}, {
+		name: "some",
+		n:    3,
+		want: 3,
+	}, {
+		name: "more than the members",
+		n:    10,
+		want: len(members),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("namespace/revision-%d", i)
+				got := ring.Owners(key, test.n)
+				if len(got) != test.want {
+					t.Fatalf("Owners(%q, %d) = %v, want %d members", key, test.n, got, test.want)
+				}
+				seen := make(map[string]bool, len(got))
+				for _, o := range got {
+					if seen[o] {
+						t.Fatalf("Owners(%q, %d) = %v, want distinct members", key, test.n, got)
+					}
+					seen[o] = true
+				}
+				if len(got) > 0 && got[0] != ring.Owner(key) {
+					t.Errorf("Owners(%q, %d)[0] = %s, want Owner() = %s", key, test.n, got[0], ring.Owner(key))
+				}
+			}
+		})
+	}
+}
+
+func TestRingOwnersStability(t *testing.T) {
+	before := NewRing([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"})
+	after := NewRing([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"})
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("namespace/revision-%d", i)
+		// Adding a member only replaces one of the owners with that member.
+		b, a := before.Owners(key, 2), after.Owners(key, 2)
+		kept := 0
+		for _, o := range a {
+			for _, p := range b {
+				if o == p {
+					kept++
+				}
+			}
+		}
+		if kept < 1 || kept == 1 && a[0] != "10.0.0.5" && a[1] != "10.0.0.5" {
+			t.Errorf("Owners(%q) moved from %v to %v", key, b, a)
+		}
+	}
+}
+
+func TestRingEmpty(t *testing.T) {
+	if got := NewRing(nil).Owner("namespace/revision"); got != "" {
+		t.Errorf("Owner() = %q, want empty", got)
+	}
+	if got := NewRing(nil).Owners("namespace/revision", 2); len(got) != 0 {
+		t.Errorf("Owners() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Shards tracks the autoscaler replicas and decides which of them owns a
+// metric key. A nil *Shards belongs to a single replica owning every key.
+type Shards struct {
+	self string
+
+	mux     sync.RWMutex
+	members []string
+	ring    *Ring
+
+	watchersMux sync.RWMutex
+	watchers    []func()
+}
+
+// New creates Shards for the replica identified by self. Until the first
+// Update, the replica owns every key.
+func New(self string) *Shards {
+	return &Shards{
+		self: self,
+		ring: NewRing(nil),
+	}
+}
+
+// Self returns the identity of this replica.
+func (s *Shards) Self() string {
+	if s == nil {
+		return ""
+	}
+	return s.self
+}
+
+// Members returns the replicas currently sharing the keys.
+func (s *Shards) Members() []string {
+	if s == nil {
+		return nil
+	}
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return append([]string(nil), s.members...)
+}
+
+// Owner returns the replica owning the key.
+func (s *Shards) Owner(key string) string {
+	if s == nil {
+		return ""
+	}
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	if owner := s.ring.Owner(key); owner != "" {
+		return owner
+	}
+	// Without any known replica, we're on our own.
+	return s.self
+}
+
+// IsOwner returns whether this replica owns the key.
+func (s *Shards) IsOwner(key string) bool {
+	return s.Owner(key) == s.Self()
+}
+
+// Update replaces the replicas sharing the keys and notifies the watchers
+// if they changed.
+func (s *Shards) Update(members []string) {
+	members = normalize(members)
+
+	s.mux.Lock()
+	if equal(s.members, members) {
+		s.mux.Unlock()
+		return
+	}
+	s.members = members
+	s.ring = NewRing(members)
+	s.mux.Unlock()
+
+	s.watchersMux.RLock()
+	defer s.watchersMux.RUnlock()
+	for _, w := range s.watchers {
+		w()
+	}
+}
+
+// Watch registers a function to be called whenever the ownership of keys
+// may have changed.
+func (s *Shards) Watch(f func()) {
+	if s == nil {
+		// Ownership never changes.
+		return
+	}
+	s.watchersMux.Lock()
+	defer s.watchersMux.Unlock()
+	s.watchers = append(s.watchers, f)
+}
+
+// MembersFromEndpoints returns the addresses of the ready replicas behind
+// the given Endpoints.
+func MembersFromEndpoints(endpoints *corev1.Endpoints) []string {
+	var members []string
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			members = append(members, address.IP)
+		}
+	}
+	return normalize(members)
+}
+
+func normalize(members []string) []string {
+	seen := make(map[string]struct{}, len(members))
+	ret := make([]string, 0, len(members))
+	for _, m := range members {
+		if _, ok := seen[m]; ok || m == "" {
+			continue
+		}
+		seen[m] = struct{}{}
+		ret = append(ret, m)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type shardsKey struct{}
+
+// WithShards attaches the Shards to the context.
+func WithShards(ctx context.Context, s *Shards) context.Context {
+	return context.WithValue(ctx, shardsKey{}, s)
+}
+
+// FromContext returns the Shards attached to the context, or nil if there
+// are none, meaning this replica owns every key.
+func FromContext(ctx context.Context) *Shards {
+	if s, ok := ctx.Value(shardsKey{}).(*Shards); ok {
+		return s
+	}
+	return nil
+}
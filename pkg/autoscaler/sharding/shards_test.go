@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const testKey = "namespace/revision"
+
+func TestShardsOwner(t *testing.T) {
+	tests := []struct {
+		name      string
+		shards    *Shards
+		members   []string
+		wantOwner bool
+	}{{
+		name:      "nil shards own everything",
+		wantOwner: true,
+	}, {
+		name:      "no members known yet",
+		shards:    New("10.0.0.1"),
+		wantOwner: true,
+	}, {
+		name:      "only member",
+		shards:    New("10.0.0.1"),
+		members:   []string{"10.0.0.1"},
+		wantOwner: true,
+	}, {
+		name:    "not a member",
+		shards:  New("10.0.0.1"),
+		members: []string{"10.0.0.2"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.members != nil {
+				test.shards.Update(test.members)
+			}
+			if got := test.shards.IsOwner(testKey); got != test.wantOwner {
+				t.Errorf("IsOwner() = %v, want %v", got, test.wantOwner)
+			}
+		})
+	}
+}
+
+func TestShardsWatch(t *testing.T) {
+	shards := New("10.0.0.1")
+	calls := 0
+	shards.Watch(func() { calls++ })
+
+	shards.Update([]string{"10.0.0.2", "10.0.0.1"})
+	if calls != 1 {
+		t.Errorf("Watcher called %d times, want 1", calls)
+	}
+
+	// The same members in another order are no change.
+	shards.Update([]string{"10.0.0.1", "10.0.0.2", "10.0.0.2"})
+	if calls != 1 {
+		t.Errorf("Watcher called %d times, want 1", calls)
+	}
+	if diff := cmp.Diff([]string{"10.0.0.1", "10.0.0.2"}, shards.Members()); diff != "" {
+		t.Errorf("Members() differed (-want, +got): %s", diff)
+	}
+
+	shards.Update([]string{"10.0.0.1"})
+	if calls != 2 {
+		t.Errorf("Watcher called %d times, want 2", calls)
+	}
+}
+
+func TestMembersFromEndpoints(t *testing.T) {
+	endpoints := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}},
+			// Replicas that are not ready don't get any keys.
+			NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.3"}},
+		}, {
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+		}},
+	}
+	want := []string{"10.0.0.1", "10.0.0.2"}
+	if diff := cmp.Diff(want, MembersFromEndpoints(endpoints)); diff != "" {
+		t.Errorf("MembersFromEndpoints() differed (-want, +got): %s", diff)
+	}
+}
+
+func TestShardsContext(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); got != nil {
+		t.Errorf("FromContext() = %v, want nil", got)
+	}
+	shards := New("10.0.0.1")
+	if got := FromContext(WithShards(ctx, shards)); got != shards {
+		t.Errorf("FromContext() = %v, want %v", got, shards)
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulator replays recorded autoscaler statistics against the
+// MetricCollector and a decider with a virtual clock, to evaluate autoscaler
+// settings offline.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/knative/pkg/logging"
+	"github.com/knative/serving/pkg/autoscaler"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Options tune the simulated cluster.
+type Options struct {
+	// StartupDelay is the time a new pod takes to become ready.
+	StartupDelay time.Duration
+	// MinScale and MaxScale bound the applied scale like the
+	// minScale and maxScale annotations do. Zero means unbounded.
+	MinScale int32
+	MaxScale int32
+}
+
+// Point is the state of the simulated revision whenever it changes.
+type Point struct {
+	Time         time.Time
+	DesiredScale int32
+	ReadyPods    int
+	TotalPods    int
+}
+
+// Window is a time range. End is zero if the window was still open when the
+// recording ended.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Result summarizes a simulation.
+type Result struct {
+	Start        time.Time
+	End          time.Time
+	Timeline     []Point
+	PanicWindows []Window
+	// PodSeconds is the total time all pods, ready or starting, existed.
+	PodSeconds float64
+}
+
+// Run replays the StatMessages recorded for the Decider's revision. Every
+// TickInterval of the DeciderSpec the recorded stats up to the virtual time
+// are fed to a MetricCollector configured with the given Metric and the
+// decider algorithm proposes a scale, which is applied to a simulated set
+// of pods.
+func Run(ctx context.Context, messages []autoscaler.StatMessage, decider *autoscaler.Decider,
+	metric *autoscaler.Metric, opts Options) (*Result, error) {
+	logger := logging.FromContext(ctx)
+	tick := decider.Spec.TickInterval
+	if tick <= 0 {
+		return nil, fmt.Errorf("tick interval must be positive, got %v", tick)
+	}
+
+	key := autoscaler.NewMetricKey(decider.Namespace, decider.Name)
+	var stats []autoscaler.Stat
+	for _, sm := range messages {
+		if sm.Key != key {
+			continue
+		}
+		if sm.Stat.Time == nil {
+			return nil, errors.New("recorded stat without a time")
+		}
+		stats = append(stats, sm.Stat)
+	}
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("no stats recorded for %s", key)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Time.Before(*stats[j].Time)
+	})
+
+	start, end := *stats[0].Time, *stats[len(stats)-1].Time
+	clk := clock.NewFakeClock(start)
+	collector := autoscaler.NewMetricCollectorWithClock(func(*autoscaler.Metric) (autoscaler.StatsScraper, error) {
+		// All stats come from the recording.
+		return noopScraper{}, nil
+	}, clk, logger)
+	if _, err := collector.Create(ctx, metric); err != nil {
+		return nil, err
+	}
+	defer collector.Delete(ctx, metric.Namespace, metric.Name)
+
+	pods := &cluster{}
+	reporter := &panicReporter{}
+	scaler, err := autoscaler.NewUniScaler(decider.Namespace, decider.Name, collector, pods, decider.Spec, reporter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Start: start, End: end}
+	desired := int32(-1)
+	panicking := reporter.panicking
+	for now := start; !now.After(end); now = now.Add(tick) {
+		for len(stats) > 0 && !stats[0].Time.After(now) {
+			collector.Record(key, stats[0])
+			stats = stats[1:]
+		}
+		clk.SetTime(now)
+		pods.advance(now)
+
+		if scale, ok := scaler.Scale(ctx, now); ok && scale >= 0 {
+			desired = applyBounds(opts.MinScale, opts.MaxScale, scale)
+			pods.scale(int(desired), now.Add(opts.StartupDelay))
+			pods.advance(now)
+		}
+
+		if reporter.panicking != panicking {
+			panicking = reporter.panicking
+			if panicking {
+				result.PanicWindows = append(result.PanicWindows, Window{Start: now})
+			} else {
+				result.PanicWindows[len(result.PanicWindows)-1].End = now
+			}
+		}
+
+		p := Point{Time: now, DesiredScale: desired, ReadyPods: pods.ready, TotalPods: pods.total()}
+		if n := len(result.Timeline); n == 0 || !samePoint(result.Timeline[n-1], p) {
+			result.Timeline = append(result.Timeline, p)
+		}
+		result.PodSeconds += float64(pods.total()) * tick.Seconds()
+	}
+	return result, nil
+}
+
+// Print writes a human readable report of the Result to w. Times are
+// relative to the start of the recording.
+func Print(w io.Writer, r *Result) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tDESIRED\tREADY\tPODS")
+	for _, p := range r.Timeline {
+		fmt.Fprintf(tw, "+%v\t%d\t%d\t%d\n", p.Time.Sub(r.Start), p.DesiredScale, p.ReadyPods, p.TotalPods)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nPanic windows: %d\n", len(r.PanicWindows))
+	for _, win := range r.PanicWindows {
+		if win.End.IsZero() {
+			fmt.Fprintf(w, "  +%v until the end\n", win.Start.Sub(r.Start))
+		} else {
+			fmt.Fprintf(w, "  +%v to +%v (%v)\n", win.Start.Sub(r.Start), win.End.Sub(r.Start), win.End.Sub(win.Start))
+		}
+	}
+	_, err := fmt.Fprintf(w, "\nDuration: %v\nPod-seconds: %.1f\n", r.End.Sub(r.Start), r.PodSeconds)
+	return err
+}
+
+func samePoint(a, b Point) bool {
+	return a.DesiredScale == b.DesiredScale && a.ReadyPods == b.ReadyPods && a.TotalPods == b.TotalPods
+}
+
+func applyBounds(min, max, x int32) int32 {
+	if x < min {
+		return min
+	}
+	if max != 0 && x > max {
+		return max
+	}
+	return x
+}
+
+// cluster simulates the pods of a revision. It implements
+// resources.ReadyPodCounter.
+type cluster struct {
+	ready int
+	// pending holds the times the starting pods become ready, in order.
+	pending []time.Time
+}
+
+func (c *cluster) ReadyCount() (int, error) {
+	return c.ready, nil
+}
+
+func (c *cluster) total() int {
+	return c.ready + len(c.pending)
+}
+
+// advance makes all pods ready that finished starting by now.
+func (c *cluster) advance(now time.Time) {
+	for len(c.pending) > 0 && !c.pending[0].After(now) {
+		c.ready++
+		c.pending = c.pending[1:]
+	}
+}
+
+// scale starts or removes pods to reach the desired number. Starting pods
+// are removed before ready ones.
+func (c *cluster) scale(desired int, readyAt time.Time) {
+	for c.total() < desired {
+		c.pending = append(c.pending, readyAt)
+	}
+	for c.total() > desired && len(c.pending) > 0 {
+		c.pending = c.pending[:len(c.pending)-1]
+	}
+	if c.total() > desired {
+		c.ready = desired
+	}
+}
+
+type noopScraper struct{}
+
+func (noopScraper) Scrape() (*autoscaler.StatMessage, error) {
+	return nil, nil
+}
+
+func (noopScraper) UpdateTarget(target, ns string) {}
+
+// panicReporter tracks whether the decider is panicking and drops all other
+// statistics.
+type panicReporter struct {
+	panicking bool
+}
+
+func (r *panicReporter) ReportPanic(v int64) error {
+	r.panicking = v == 1
+	return nil
+}
+
+func (r *panicReporter) ReportDesiredPodCount(v int64) error            { return nil }
+func (r *panicReporter) ReportRequestedPodCount(v int64) error          { return nil }
+func (r *panicReporter) ReportActualPodCount(v int64) error             { return nil }
+func (r *panicReporter) ReportStableRequestConcurrency(v float64) error { return nil }
+func (r *panicReporter) ReportPanicRequestConcurrency(v float64) error  { return nil }
+func (r *panicReporter) ReportTargetRequestConcurrency(v float64) error { return nil }
+func (r *panicReporter) ReportStableRPS(v float64) error                { return nil }
+func (r *panicReporter) ReportPanicRPS(v float64) error                 { return nil }
+func (r *panicReporter) ReportTargetRPS(v float64) error                { return nil }
+func (r *panicReporter) ReportMaxScaleDownRate(v float64) error         { return nil }
@@ -0,0 +1,240 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/knative/pkg/logging/testing"
+	"github.com/knative/serving/pkg/autoscaler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testNamespace = "test-namespace"
+	testRevision  = "test-revision"
+)
+
+var t0 = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		panicThreshold float64
+		opts           Options
+		want           *Result
+	}{{
+		name:           "steady",
+		panicThreshold: 1000,
+		want: &Result{
+			Start: t0,
+			End:   t0.Add(20 * time.Second),
+			Timeline: []Point{
+				{Time: t0, DesiredScale: 3, ReadyPods: 3, TotalPods: 3},
+			},
+			// 11 ticks of 2s with 3 pods.
+			PodSeconds: 66,
+		},
+	}, {
+		name:           "startup delay",
+		panicThreshold: 1000,
+		opts:           Options{StartupDelay: 5 * time.Second},
+		want: &Result{
+			Start: t0,
+			End:   t0.Add(20 * time.Second),
+			Timeline: []Point{
+				{Time: t0, DesiredScale: 3, ReadyPods: 0, TotalPods: 3},
+				{Time: t0.Add(6 * time.Second), DesiredScale: 3, ReadyPods: 3, TotalPods: 3},
+			},
+			PodSeconds: 66,
+		},
+	}, {
+		name:           "bounded",
+		panicThreshold: 1000,
+		opts:           Options{MaxScale: 2},
+		want: &Result{
+			Start: t0,
+			End:   t0.Add(20 * time.Second),
+			Timeline: []Point{
+				{Time: t0, DesiredScale: 2, ReadyPods: 2, TotalPods: 2},
+			},
+			PodSeconds: 44,
+		},
+	}, {
+		name: "panic",
+		// 30 concurrent requests on the single pod observed at the
+		// first tick exceed the threshold.
+		panicThreshold: 20,
+		want: &Result{
+			Start: t0,
+			End:   t0.Add(20 * time.Second),
+			Timeline: []Point{
+				{Time: t0, DesiredScale: 3, ReadyPods: 3, TotalPods: 3},
+			},
+			// Panic mode ends once a stable window passed without
+			// exceeding the threshold.
+			PanicWindows: []Window{{Start: t0, End: t0.Add(12 * time.Second)}},
+			PodSeconds:   66,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Run(TestContextWithLogger(t), steadyTrace(30, 20*time.Second),
+				decider(test.panicThreshold), metric(), test.opts)
+			if err != nil {
+				t.Fatalf("Run() = %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Run() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	zeroTick := decider(1000)
+	zeroTick.Spec.TickInterval = 0
+	noTime := []autoscaler.StatMessage{{
+		Key:  autoscaler.NewMetricKey(testNamespace, testRevision),
+		Stat: autoscaler.Stat{PodName: "pod"},
+	}}
+	otherKey := []autoscaler.StatMessage{{
+		Key:  autoscaler.NewMetricKey(testNamespace, "other"),
+		Stat: autoscaler.Stat{Time: &t0, PodName: "pod"},
+	}}
+
+	tests := []struct {
+		name     string
+		messages []autoscaler.StatMessage
+		decider  *autoscaler.Decider
+		want     string
+	}{{
+		name:     "zero tick interval",
+		messages: steadyTrace(30, 20*time.Second),
+		decider:  zeroTick,
+		want:     "tick interval must be positive",
+	}, {
+		name:     "stat without time",
+		messages: noTime,
+		decider:  decider(1000),
+		want:     "recorded stat without a time",
+	}, {
+		name:     "no stats for the revision",
+		messages: otherKey,
+		decider:  decider(1000),
+		want:     "no stats recorded for test-namespace/test-revision",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Run(TestContextWithLogger(t), test.messages, test.decider, metric(), Options{})
+			if err == nil {
+				t.Fatal("Run() = nil, wanted an error")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Run() = %v, want to contain %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	r := &Result{
+		Start: t0,
+		End:   t0.Add(time.Minute),
+		Timeline: []Point{
+			{Time: t0, DesiredScale: 3, ReadyPods: 0, TotalPods: 3},
+			{Time: t0.Add(6 * time.Second), DesiredScale: 3, ReadyPods: 3, TotalPods: 3},
+		},
+		PanicWindows: []Window{
+			{Start: t0, End: t0.Add(12 * time.Second)},
+			{Start: t0.Add(30 * time.Second)},
+		},
+		PodSeconds: 180,
+	}
+
+	var buf bytes.Buffer
+	if err := Print(&buf, r); err != nil {
+		t.Fatalf("Print() = %v", err)
+	}
+	want := `TIME  DESIRED  READY  PODS
++0s   3        0      3
++6s   3        3      3
+
+Panic windows: 2
+  +0s to +12s (12s)
+  +30s until the end
+
+Duration: 1m0s
+Pod-seconds: 180.0
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Print() (-want, +got) = %v", diff)
+	}
+}
+
+// steadyTrace records a single pod reporting the given concurrency every
+// second for the given duration.
+func steadyTrace(concurrency float64, d time.Duration) []autoscaler.StatMessage {
+	var messages []autoscaler.StatMessage
+	for ts := t0; !ts.After(t0.Add(d)); ts = ts.Add(time.Second) {
+		ts := ts
+		messages = append(messages, autoscaler.StatMessage{
+			Key: autoscaler.NewMetricKey(testNamespace, testRevision),
+			Stat: autoscaler.Stat{
+				Time:                      &ts,
+				PodName:                   "pod",
+				AverageConcurrentRequests: concurrency,
+			},
+		})
+	}
+	return messages
+}
+
+func decider(panicThreshold float64) *autoscaler.Decider {
+	return &autoscaler.Decider{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testRevision,
+		},
+		Spec: autoscaler.DeciderSpec{
+			TickInterval:   2 * time.Second,
+			MaxScaleUpRate: 10,
+			TargetValue:    10,
+			PanicThreshold: panicThreshold,
+			StableWindow:   10 * time.Second,
+		},
+	}
+}
+
+func metric() *autoscaler.Metric {
+	return &autoscaler.Metric{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testRevision,
+		},
+		Spec: autoscaler.MetricSpec{
+			StableWindow: 10 * time.Second,
+			PanicWindow:  time.Second,
+		},
+	}
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/knative/serving/pkg/autoscaler/aggregation"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// snapshotDataKey is the key of the ConfigMap data holding the snapshot.
+	snapshotDataKey = "snapshot"
+
+	// restoredTTL is how long the restored data of metrics that are not
+	// collected yet is kept. It gives the controllers time to create the
+	// metrics after a restart, the data of revisions deleted in the meantime
+	// is dropped after it.
+	restoredTTL = 10 * time.Minute
+)
+
+// Snapshot is the serializable state of a MetricCollector.
+type Snapshot struct {
+	// Collections maps metric keys to the data collected for them.
+	Collections map[string]CollectionSnapshot
+}
+
+// CollectionSnapshot is the serializable state of the data collected for
+// a single metric.
+type CollectionSnapshot struct {
+	Concurrency aggregation.Snapshot
+	RPS         aggregation.Snapshot
+}
+
+// SnapshotStore persists snapshots of the MetricCollector.
+type SnapshotStore interface {
+	// Save persists the snapshot, replacing the previous one.
+	Save(*Snapshot) error
+	// Load returns the last persisted snapshot or nil if there is none.
+	Load() (*Snapshot, error)
+}
+
+// Snapshot returns a copy of the data collected for all metrics. It also
+// drops the restored data that no metric was created for within restoredTTL.
+func (c *MetricCollector) Snapshot() *Snapshot {
+	c.collectionsMutex.Lock()
+	defer c.collectionsMutex.Unlock()
+
+	if len(c.restored) > 0 && c.clock.Since(c.restoredTime) > restoredTTL {
+		c.logger.Infof("Dropping the restored data of %d metrics that were not created", len(c.restored))
+		c.restored = make(map[string]CollectionSnapshot)
+	}
+
+	snapshot := &Snapshot{Collections: make(map[string]CollectionSnapshot, len(c.collections))}
+	for key, collection := range c.collections {
+		snapshot.Collections[key] = collection.snapshot()
+	}
+	return snapshot
+}
+
+// Restore merges the data of the snapshot into the collector. Data of
+// metrics that are not collected yet is kept until the metric is created.
+// Data older than the metric's stable window is dropped.
+func (c *MetricCollector) Restore(snapshot *Snapshot) {
+	if snapshot == nil {
+		return
+	}
+
+	c.collectionsMutex.Lock()
+	defer c.collectionsMutex.Unlock()
+
+	now := c.clock.Now()
+	c.restoredTime = now
+	for key, s := range snapshot.Collections {
+		if collection, exists := c.collections[key]; exists {
+			collection.restore(s, now)
+		} else {
+			c.restored[key] = s
+		}
+	}
+}
+
+// RunSnapshotter saves a snapshot of the collector to the store every
+// interval and once more when the stopCh is closed.
+func RunSnapshotter(stopCh <-chan struct{}, collector *MetricCollector, store SnapshotStore, interval time.Duration, logger *zap.SugaredLogger) {
+	save := func() {
+		if err := store.Save(collector.Snapshot()); err != nil {
+			logger.Errorw("Failed to save metric snapshot", zap.Error(err))
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			save()
+			return
+		case <-ticker.C:
+			save()
+		}
+	}
+}
+
+// fileSnapshotStore persists snapshots as JSON in a local file.
+type fileSnapshotStore struct {
+	path string
+}
+
+// NewFileSnapshotStore creates a SnapshotStore that persists snapshots in the
+// file at the given path.
+func NewFileSnapshotStore(path string) SnapshotStore {
+	return &fileSnapshotStore{path: path}
+}
+
+// Save implements SnapshotStore. The file is replaced atomically, so a crash
+// while saving never leaves a partial snapshot behind.
+func (s *fileSnapshotStore) Save(snapshot *Snapshot) error {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+// Load implements SnapshotStore.
+func (s *fileSnapshotStore) Load() (*Snapshot, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(b, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// configMapSnapshotStore persists snapshots as JSON in a number of ConfigMaps.
+type configMapSnapshotStore struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+	name       string
+	partitions int
+	owns       func(key string) bool
+}
+
+// NewConfigMapSnapshotStore creates a SnapshotStore that persists snapshots in
+// the given number of ConfigMaps in the namespace, named after the given name
+// and their index, creating them if needed. The metrics are spread across the
+// ConfigMaps by the hash of their key, so that the ConfigMaps stay below the
+// size limit of Kubernetes objects as the number of revisions grows.
+//
+// If owns is not nil, the ConfigMaps are shared with other replicas: saving
+// only replaces the stored data of the keys owns returns true for and keeps
+// the data of all other keys.
+func NewConfigMapSnapshotStore(kubeClient kubernetes.Interface, namespace, name string, partitions int, owns func(key string) bool) SnapshotStore {
+	return &configMapSnapshotStore{
+		kubeClient: kubeClient,
+		namespace:  namespace,
+		name:       name,
+		partitions: partitions,
+		owns:       owns,
+	}
+}
+
+// partitionName returns the name of the ConfigMap holding the metric key.
+func (s *configMapSnapshotStore) partitionName(key string) string {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return s.partitionNameAt(int(h.Sum32() % uint32(s.partitions)))
+}
+
+func (s *configMapSnapshotStore) partitionNameAt(i int) string {
+	return fmt.Sprintf("%s-%d", s.name, i)
+}
+
+// Save implements SnapshotStore. ConfigMaps whose data didn't change are not
+// written, the others are updated with the data of the keys not owned merged
+// in, retrying on conflicting writes of other replicas.
+func (s *configMapSnapshotStore) Save(snapshot *Snapshot) error {
+	partitions := make(map[string]*Snapshot, s.partitions)
+	for i := 0; i < s.partitions; i++ {
+		partitions[s.partitionNameAt(i)] = &Snapshot{Collections: make(map[string]CollectionSnapshot)}
+	}
+	for key, collection := range snapshot.Collections {
+		partitions[s.partitionName(key)].Collections[key] = collection
+	}
+	for name, partition := range partitions {
+		if err := s.savePartition(name, partition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *configMapSnapshotStore) savePartition(name string, snapshot *Snapshot) error {
+	configMaps := s.kubeClient.CoreV1().ConfigMaps(s.namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			b, err := json.Marshal(snapshot)
+			if err != nil {
+				return err
+			}
+			_, err = configMaps.Create(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: s.namespace,
+					Name:      name,
+				},
+				Data: map[string]string{snapshotDataKey: string(b)},
+			})
+			if apierrs.IsAlreadyExists(err) {
+				// Another replica created it first, merge into theirs.
+				return apierrs.NewConflict(corev1.Resource("configmaps"), name, err)
+			}
+			return err
+		} else if err != nil {
+			return err
+		}
+
+		merged := snapshot
+		if s.owns != nil {
+			stored, err := snapshotFrom(cm)
+			if err != nil {
+				return err
+			}
+			merged = &Snapshot{Collections: make(map[string]CollectionSnapshot, len(snapshot.Collections))}
+			if stored != nil {
+				for key, collection := range stored.Collections {
+					if !s.owns(key) {
+						merged.Collections[key] = collection
+					}
+				}
+			}
+			for key, collection := range snapshot.Collections {
+				merged.Collections[key] = collection
+			}
+		}
+		b, err := json.Marshal(merged)
+		if err != nil {
+			return err
+		}
+		if cm.Data[snapshotDataKey] == string(b) {
+			return nil
+		}
+
+		// The update carries the resourceVersion read, failing with a
+		// conflict if another replica wrote the ConfigMap since.
+		cm = cm.DeepCopy()
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		cm.Data[snapshotDataKey] = string(b)
+		_, err = configMaps.Update(cm)
+		return err
+	})
+}
+
+// Load implements SnapshotStore.
+func (s *configMapSnapshotStore) Load() (*Snapshot, error) {
+	var snapshot *Snapshot
+	for i := 0; i < s.partitions; i++ {
+		partition, err := s.loadPartition(s.partitionNameAt(i))
+		if err != nil {
+			return nil, err
+		}
+		if partition == nil {
+			continue
+		}
+		if snapshot == nil {
+			snapshot = &Snapshot{Collections: make(map[string]CollectionSnapshot)}
+		}
+		for key, collection := range partition.Collections {
+			snapshot.Collections[key] = collection
+		}
+	}
+	return snapshot, nil
+}
+
+func (s *configMapSnapshotStore) loadPartition(name string) (*Snapshot, error) {
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return snapshotFrom(cm)
+}
+
+// snapshotFrom decodes the snapshot stored in the ConfigMap, if any.
+func snapshotFrom(cm *corev1.ConfigMap) (*Snapshot, error) {
+	data, ok := cm.Data[snapshotDataKey]
+	if !ok {
+		return nil, nil
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal([]byte(data), snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
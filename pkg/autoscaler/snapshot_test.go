@@ -0,0 +1,321 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knative/serving/pkg/autoscaler/aggregation"
+
+	. "github.com/knative/pkg/logging/testing"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakeK8s "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestMetricCollectorSnapshotRestore(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+
+	original := NewMetricCollectorWithClock(scraperFactory(scraper, nil), clock.NewFakeClock(now), logger)
+	original.Create(ctx, defaultMetric)
+	defer original.Delete(ctx, defaultNamespace, defaultName)
+	original.Record(metricKey, Stat{Time: &now, PodName: "testPod", AverageConcurrentRequests: 10, RequestCount: 20})
+	snapshot := original.Snapshot()
+
+	tests := []struct {
+		name string
+		// age is how long after the snapshot the data is restored.
+		age         time.Duration
+		createFirst bool
+		wantErr     error
+	}{{
+		name: "restore before create",
+	}, {
+		name:        "restore after create",
+		createFirst: true,
+	}, {
+		name: "restore within window",
+		age:  defaultMetric.Spec.StableWindow / 2,
+	}, {
+		name:    "drop data older than window",
+		age:     defaultMetric.Spec.StableWindow + bucketSize,
+		wantErr: ErrNoData,
+	}, {
+		name:        "drop data older than window after create",
+		age:         defaultMetric.Spec.StableWindow + bucketSize,
+		createFirst: true,
+		wantErr:     ErrNoData,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			coll := NewMetricCollectorWithClock(scraperFactory(scraper, nil), clock.NewFakeClock(now.Add(test.age)), logger)
+			if test.createFirst {
+				coll.Create(ctx, defaultMetric)
+				coll.Restore(snapshot)
+			} else {
+				coll.Restore(snapshot)
+				coll.Create(ctx, defaultMetric)
+			}
+			defer coll.Delete(ctx, defaultNamespace, defaultName)
+
+			stable, _, err := coll.StableAndPanicConcurrency(metricKey)
+			if err != test.wantErr {
+				t.Fatalf("StableAndPanicConcurrency() = %v, want %v", err, test.wantErr)
+			}
+			if err == nil && stable != 10 {
+				t.Errorf("stable concurrency = %v, want %v", stable, 10)
+			}
+			stable, _, err = coll.StableAndPanicRPS(metricKey)
+			if err != test.wantErr {
+				t.Fatalf("StableAndPanicRPS() = %v, want %v", err, test.wantErr)
+			}
+			if err == nil && stable != 20 {
+				t.Errorf("stable rps = %v, want %v", stable, 20)
+			}
+		})
+	}
+}
+
+func TestSnapshotStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now().Truncate(time.Second)
+	snapshot := func(value float64) *Snapshot {
+		s := &Snapshot{Collections: make(map[string]CollectionSnapshot)}
+		for i := 0; i < 10; i++ {
+			s.Collections[fmt.Sprintf("ns/rev-%d", i)] = CollectionSnapshot{
+				Concurrency: aggregation.Snapshot{Buckets: []aggregation.BucketSnapshot{{
+					Time:  now,
+					Value: value,
+				}}},
+			}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name  string
+		store SnapshotStore
+	}{{
+		name:  "file",
+		store: NewFileSnapshotStore(filepath.Join(dir, "snapshot.json")),
+	}, {
+		name:  "configmap",
+		store: NewConfigMapSnapshotStore(fakeK8s.NewSimpleClientset(), "knative-serving", "snapshot", 3, nil),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, err := test.store.Load(); got != nil || err != nil {
+				t.Errorf("Load() = %v, %v; want nil, nil", got, err)
+			}
+
+			// Save three times to cover creating, replacing and keeping the snapshot.
+			for _, value := range []float64{1, 2, 2} {
+				want := snapshot(value)
+				if err := test.store.Save(want); err != nil {
+					t.Fatalf("Save() = %v", err)
+				}
+				got, err := test.store.Load()
+				if err != nil {
+					t.Fatalf("Load() = %v", err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("Load() differed from saved snapshot (-want, +got): %v", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigMapSnapshotStoreSkipsUnchanged(t *testing.T) {
+	kubeClient := fakeK8s.NewSimpleClientset()
+	store := NewConfigMapSnapshotStore(kubeClient, "knative-serving", "snapshot", 3, nil)
+	snapshot := &Snapshot{Collections: map[string]CollectionSnapshot{
+		"ns/rev": {RPS: aggregation.Snapshot{Buckets: []aggregation.BucketSnapshot{{Value: 1}}}},
+	}}
+
+	if err := store.Save(snapshot); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	kubeClient.ClearActions()
+	if err := store.Save(snapshot); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("Unexpected %s of unchanged snapshot", action.GetVerb())
+		}
+	}
+}
+
+func TestConfigMapSnapshotStoreShared(t *testing.T) {
+	owned := sets.NewString("ns/own-0", "ns/own-1")
+	collection := func(value float64) CollectionSnapshot {
+		return CollectionSnapshot{
+			Concurrency: aggregation.Snapshot{Buckets: []aggregation.BucketSnapshot{{
+				Value: value,
+			}}},
+		}
+	}
+
+	kubeClient := fakeK8s.NewSimpleClientset()
+	if err := NewConfigMapSnapshotStore(kubeClient, "knative-serving", "snapshot", 2, nil).Save(&Snapshot{Collections: map[string]CollectionSnapshot{
+		"ns/own-0":   collection(1),
+		"ns/own-1":   collection(2),
+		"ns/other-0": collection(3),
+	}}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	// Fail the first update as if another replica wrote in between.
+	conflicted := false
+	kubeClient.PrependReactor("update", "configmaps", func(ktesting.Action) (bool, runtime.Object, error) {
+		if conflicted {
+			return false, nil, nil
+		}
+		conflicted = true
+		return true, nil, apierrs.NewConflict(corev1.Resource("configmaps"), "snapshot", errors.New("conflict"))
+	})
+
+	store := NewConfigMapSnapshotStore(kubeClient, "knative-serving", "snapshot", 2, owned.Has)
+	if err := store.Save(&Snapshot{Collections: map[string]CollectionSnapshot{
+		"ns/own-0": collection(4),
+	}}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	if !conflicted {
+		t.Error("Expected the update to conflict")
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	// Our keys are replaced, the keys of the other replica are kept.
+	want := &Snapshot{Collections: map[string]CollectionSnapshot{
+		"ns/own-0":   collection(4),
+		"ns/other-0": collection(3),
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Load() differed (-want, +got): %s", diff)
+	}
+}
+
+func TestMetricCollectorDropsRestoredAfterTTL(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+
+	original := NewMetricCollectorWithClock(scraperFactory(scraper, nil), clock.NewFakeClock(now), logger)
+	original.Create(ctx, defaultMetric)
+	defer original.Delete(ctx, defaultNamespace, defaultName)
+	original.Record(metricKey, Stat{Time: &now, PodName: "testPod", AverageConcurrentRequests: 10, RequestCount: 20})
+	snapshot := original.Snapshot()
+
+	fakeClock := clock.NewFakeClock(now)
+	coll := NewMetricCollectorWithClock(scraperFactory(scraper, nil), fakeClock, logger)
+	coll.Restore(snapshot)
+
+	// Snapshots within the TTL keep the restored data.
+	fakeClock.Step(restoredTTL)
+	coll.Snapshot()
+	if len(coll.restored) != 1 {
+		t.Errorf("len(restored) = %d, want 1", len(coll.restored))
+	}
+
+	// The first snapshot after the TTL drops it.
+	fakeClock.Step(time.Second)
+	coll.Snapshot()
+	if len(coll.restored) != 0 {
+		t.Errorf("len(restored) = %d, want 0", len(coll.restored))
+	}
+}
+
+func TestRunSnapshotter(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	collector := NewMetricCollector(scraperFactory(nil, nil), logger)
+	store := &testSnapshotStore{saved: make(chan *Snapshot, 1)}
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RunSnapshotter(stopCh, collector, store, time.Hour, logger)
+	}()
+
+	// A snapshot is saved when stopping, even before the first interval passed.
+	close(stopCh)
+	select {
+	case <-store.saved:
+	case <-time.After(time.Second):
+		t.Error("Expected a snapshot to be saved on stop")
+	}
+	<-done
+}
+
+type testSnapshotStore struct {
+	saved chan *Snapshot
+}
+
+func (s *testSnapshotStore) Save(snapshot *Snapshot) error {
+	s.saved <- snapshot
+	return nil
+}
+
+func (s *testSnapshotStore) Load() (*Snapshot, error) {
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statproto implements the wire protocol stats are sent to the
+// autoscaler with.
+//
+// Senders request the protocols they speak as websocket subprotocols when
+// connecting. The only protocol so far is JSONv1, which sends text frames
+// carrying a batch of stat messages each, for example
+//
+//	{
+//	  "version": 1,
+//	  "messages": [{
+//	    "key": "default/hello-00001",
+//	    "stat": {
+//	      "podName": "activator-7d9b5c7c8-x2v4k",
+//	      "averageConcurrentRequests": 2.5,
+//	      "requestCount": 31
+//	    }
+//	  }]
+//	}
+//
+// If the autoscaler doesn't select any protocol, senders fall back to
+// sending one gob encoded autoscaler.StatMessage per binary frame, which is
+// what autoscalers without protocol negotiation understand.
+package statproto
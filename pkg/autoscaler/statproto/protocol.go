@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statproto
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+	"github.com/knative/serving/pkg/autoscaler"
+)
+
+const (
+	// JSONv1 is the websocket subprotocol of version 1 of the JSON encoding.
+	JSONv1 = "v1.json.stats.autoscaling.knative.dev"
+
+	// jsonVersion is the version in the frames of the JSONv1 protocol.
+	jsonVersion = 1
+)
+
+// Subprotocols are the protocols understood by Decode, in order of
+// preference.
+var Subprotocols = []string{JSONv1}
+
+// frame is a frame of the JSON protocol.
+type frame struct {
+	Version  int       `json:"version"`
+	Messages []message `json:"messages"`
+}
+
+// message is the JSON encoding of an autoscaler.StatMessage.
+type message struct {
+	Key  string `json:"key"`
+	Stat stat   `json:"stat"`
+}
+
+// stat is the JSON encoding of an autoscaler.Stat. The time of a stat is
+// when the autoscaler receives it, so it's not part of the protocol.
+type stat struct {
+	PodName                          string  `json:"podName"`
+	AverageConcurrentRequests        float64 `json:"averageConcurrentRequests"`
+	AverageProxiedConcurrentRequests float64 `json:"averageProxiedConcurrentRequests,omitempty"`
+	RequestCount                     float64 `json:"requestCount"`
+	ProxiedRequestCount              float64 `json:"proxiedRequestCount,omitempty"`
+	RequestLatencyP50                float64 `json:"requestLatencyP50,omitempty"`
+	RequestLatencyP95                float64 `json:"requestLatencyP95,omitempty"`
+	RequestLatencyP99                float64 `json:"requestLatencyP99,omitempty"`
+	AverageQueueLength               float64 `json:"averageQueueLength,omitempty"`
+	MaxQueueLength                   float64 `json:"maxQueueLength,omitempty"`
+	AverageQueuedTime                float64 `json:"averageQueuedTime,omitempty"`
+}
+
+// Encode encodes the stat messages in the frames of the given protocol and
+// returns the websocket message type of the frames. The empty protocol is
+// the legacy gob encoding, which carries a single message per frame.
+func Encode(protocol string, sms []*autoscaler.StatMessage) (int, [][]byte, error) {
+	switch protocol {
+	case JSONv1:
+		f := frame{
+			Version:  jsonVersion,
+			Messages: make([]message, 0, len(sms)),
+		}
+		for _, sm := range sms {
+			f.Messages = append(f.Messages, message{
+				Key: sm.Key,
+				Stat: stat{
+					PodName:                          sm.Stat.PodName,
+					AverageConcurrentRequests:        sm.Stat.AverageConcurrentRequests,
+					AverageProxiedConcurrentRequests: sm.Stat.AverageProxiedConcurrentRequests,
+					RequestCount:                     sm.Stat.RequestCount,
+					ProxiedRequestCount:              sm.Stat.ProxiedRequestCount,
+					RequestLatencyP50:                sm.Stat.RequestLatencyP50,
+					RequestLatencyP95:                sm.Stat.RequestLatencyP95,
+					RequestLatencyP99:                sm.Stat.RequestLatencyP99,
+					AverageQueueLength:               sm.Stat.AverageQueueLength,
+					MaxQueueLength:                   sm.Stat.MaxQueueLength,
+					AverageQueuedTime:                sm.Stat.AverageQueuedTime,
+				},
+			})
+		}
+		b, err := json.Marshal(f)
+		if err != nil {
+			return 0, nil, err
+		}
+		return websocket.TextMessage, [][]byte{b}, nil
+	case "":
+		frames := make([][]byte, 0, len(sms))
+		for _, sm := range sms {
+			var b bytes.Buffer
+			if err := gob.NewEncoder(&b).Encode(sm); err != nil {
+				return 0, nil, err
+			}
+			frames = append(frames, b.Bytes())
+		}
+		return websocket.BinaryMessage, frames, nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+}
+
+// Decode decodes the stat messages in a frame of any supported protocol.
+// Binary frames are gob encoded, text frames are JSON encoded.
+func Decode(messageType int, data []byte) ([]autoscaler.StatMessage, error) {
+	switch messageType {
+	case websocket.BinaryMessage:
+		var sm autoscaler.StatMessage
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&sm); err != nil {
+			return nil, err
+		}
+		return []autoscaler.StatMessage{sm}, nil
+	case websocket.TextMessage:
+		var f frame
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, err
+		}
+		if f.Version != jsonVersion {
+			return nil, fmt.Errorf("unsupported version %d", f.Version)
+		}
+		sms := make([]autoscaler.StatMessage, 0, len(f.Messages))
+		for _, m := range f.Messages {
+			sms = append(sms, autoscaler.StatMessage{
+				Key: m.Key,
+				Stat: autoscaler.Stat{
+					PodName:                          m.Stat.PodName,
+					AverageConcurrentRequests:        m.Stat.AverageConcurrentRequests,
+					AverageProxiedConcurrentRequests: m.Stat.AverageProxiedConcurrentRequests,
+					RequestCount:                     m.Stat.RequestCount,
+					ProxiedRequestCount:              m.Stat.ProxiedRequestCount,
+					RequestLatencyP50:                m.Stat.RequestLatencyP50,
+					RequestLatencyP95:                m.Stat.RequestLatencyP95,
+					RequestLatencyP99:                m.Stat.RequestLatencyP99,
+					AverageQueueLength:               m.Stat.AverageQueueLength,
+					MaxQueueLength:                   m.Stat.MaxQueueLength,
+					AverageQueuedTime:                m.Stat.AverageQueuedTime,
+				},
+			})
+		}
+		return sms, nil
+	default:
+		return nil, fmt.Errorf("unsupported message type %d", messageType)
+	}
+}
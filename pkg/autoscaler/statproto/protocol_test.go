@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statproto
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+	"github.com/knative/serving/pkg/autoscaler"
+)
+
+var testMessages = []*autoscaler.StatMessage{{
+	Key: "ns/rev1",
+	Stat: autoscaler.Stat{
+		PodName:                          "activator",
+		AverageConcurrentRequests:        2.5,
+		AverageProxiedConcurrentRequests: 1,
+		RequestCount:                     31,
+		ProxiedRequestCount:              12,
+	},
+}, {
+	Key: "ns/rev2",
+	Stat: autoscaler.Stat{
+		PodName:                   "activator",
+		AverageConcurrentRequests: 1,
+		RequestCount:              3,
+		RequestLatencyP50:         0.1,
+		RequestLatencyP95:         0.25,
+		RequestLatencyP99:         0.5,
+		AverageQueueLength:        0.5,
+		MaxQueueLength:            2,
+		AverageQueuedTime:         0.05,
+	},
+}}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name            string
+		protocol        string
+		wantMessageType int
+		wantFrames      int
+	}{{
+		name:            "json",
+		protocol:        JSONv1,
+		wantMessageType: websocket.TextMessage,
+		wantFrames:      1,
+	}, {
+		name:            "gob",
+		wantMessageType: websocket.BinaryMessage,
+		wantFrames:      2,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			messageType, frames, err := Encode(test.protocol, testMessages)
+			if err != nil {
+				t.Fatalf("Encode() = %v", err)
+			}
+			if messageType != test.wantMessageType {
+				t.Errorf("messageType = %d, want %d", messageType, test.wantMessageType)
+			}
+			if len(frames) != test.wantFrames {
+				t.Errorf("len(frames) = %d, want %d", len(frames), test.wantFrames)
+			}
+
+			var got []*autoscaler.StatMessage
+			for _, f := range frames {
+				sms, err := Decode(messageType, f)
+				if err != nil {
+					t.Fatalf("Decode() = %v", err)
+				}
+				for i := range sms {
+					got = append(got, &sms[i])
+				}
+			}
+			if diff := cmp.Diff(testMessages, got); diff != "" {
+				t.Errorf("Decoded messages differ (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	// What a sender written in another language would send.
+	data := `{"version":1,"messages":[{"key":"ns/rev","stat":{"podName":"loadgen","averageConcurrentRequests":3,"requestCount":10}}]}`
+	want := []autoscaler.StatMessage{{
+		Key: "ns/rev",
+		Stat: autoscaler.Stat{
+			PodName:                   "loadgen",
+			AverageConcurrentRequests: 3,
+			RequestCount:              10,
+		},
+	}}
+
+	got, err := Decode(websocket.TextMessage, []byte(data))
+	if err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Decoded messages differ (-want, +got): %s", diff)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		messageType int
+		data        string
+		want        string
+	}{{
+		name:        "unsupported version",
+		messageType: websocket.TextMessage,
+		data:        `{"version":2,"messages":[]}`,
+		want:        "unsupported version 2",
+	}, {
+		name:        "invalid json",
+		messageType: websocket.TextMessage,
+		data:        `{"version":`,
+		want:        "unexpected end of JSON input",
+	}, {
+		name:        "invalid gob",
+		messageType: websocket.BinaryMessage,
+		data:        "nope",
+		want:        "unexpected EOF",
+	}, {
+		name:        "unsupported message type",
+		messageType: websocket.PingMessage,
+		want:        "unsupported message type 9",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Decode(test.messageType, []byte(test.data)); err == nil || err.Error() != test.want {
+				t.Errorf("Decode() = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodeUnsupportedProtocol(t *testing.T) {
+	if _, _, err := Encode("v2.json", testMessages); err == nil {
+		t.Error("Encode() = nil, wanted an error")
+	}
+}
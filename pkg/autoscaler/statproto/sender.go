@@ -0,0 +1,213 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statproto
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/knative/serving/pkg/autoscaler"
+	"go.uber.org/zap"
+)
+
+const (
+	// pongTimeout is the time allowed between two pongs before the
+	// connection is considered broken.
+	pongTimeout = 10 * time.Second
+
+	// Reconnects are retried with an exponential backoff between these.
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 5 * time.Second
+)
+
+// ErrNotConnected is returned when sending before a connection has been
+// established.
+var ErrNotConnected = errors.New("connection has not yet been established")
+
+// Sender sends stat messages to an autoscaler over a websocket connection
+// that is kept alive and reconnected in case of a loss of connectivity.
+type Sender struct {
+	target string
+	logger *zap.SugaredLogger
+	dialer *websocket.Dialer
+
+	// connMux guards the current connection and its negotiated protocol.
+	connMux  sync.RWMutex
+	conn     *websocket.Conn
+	protocol string
+
+	// Gorilla allows only one concurrent writer.
+	writeMux sync.Mutex
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewSender creates a Sender connecting to the given websocket URL.
+func NewSender(target string, logger *zap.SugaredLogger) *Sender {
+	s := &Sender{
+		target: target,
+		logger: logger,
+		dialer: &websocket.Dialer{
+			HandshakeTimeout: 3 * time.Second,
+			Subprotocols:     Subprotocols,
+		},
+		closeCh: make(chan struct{}),
+	}
+
+	s.wg.Add(2)
+	go s.run()
+	go s.ping()
+	return s
+}
+
+// Send sends the stat messages, in a single frame if the autoscaler supports
+// batching.
+func (s *Sender) Send(sms ...*autoscaler.StatMessage) error {
+	s.connMux.RLock()
+	conn, protocol := s.conn, s.protocol
+	s.connMux.RUnlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	messageType, frames, err := Encode(protocol, sms)
+	if err != nil {
+		return err
+	}
+
+	s.writeMux.Lock()
+	defer s.writeMux.Unlock()
+	for _, f := range frames {
+		if err := conn.WriteMessage(messageType, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status returns an error if the Sender is not connected.
+func (s *Sender) Status() error {
+	s.connMux.RLock()
+	defer s.connMux.RUnlock()
+	if s.conn == nil {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// Shutdown closes the connection and stops reconnecting.
+func (s *Sender) Shutdown() error {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
+	err := s.closeConn()
+	s.wg.Wait()
+	return err
+}
+
+// run keeps (re)connecting until the Sender is shut down.
+func (s *Sender) run() {
+	defer s.wg.Done()
+
+	delay := minReconnectDelay
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		default:
+		}
+
+		conn, _, err := s.dialer.Dial(s.target, nil)
+		if err != nil {
+			s.logger.Errorw("Connecting to "+s.target+" failed", zap.Error(err))
+			select {
+			case <-s.closeCh:
+				return
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+			continue
+		}
+		delay = minReconnectDelay
+
+		protocol := conn.Subprotocol()
+		if protocol == "" {
+			s.logger.Infof("Connected to %s, falling back to gob", s.target)
+		} else {
+			s.logger.Infof("Connected to %s with protocol %s", s.target, protocol)
+		}
+		conn.SetReadDeadline(time.Now().Add(pongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongTimeout))
+		})
+
+		s.connMux.Lock()
+		s.conn, s.protocol = conn, protocol
+		s.connMux.Unlock()
+
+		// Reading processes the pongs and returns once the connection breaks.
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				break
+			}
+		}
+		if err := s.closeConn(); err != nil {
+			s.logger.Errorw("Failed to close the connection to "+s.target, zap.Error(err))
+		}
+	}
+}
+
+// ping sends pings three times per pongTimeout to keep the connection alive.
+func (s *Sender) ping() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(pongTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case <-ticker.C:
+			s.connMux.RLock()
+			conn := s.conn
+			s.connMux.RUnlock()
+			if conn == nil {
+				continue
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pongTimeout/3)); err != nil {
+				s.logger.Errorw("Failed to send ping message", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *Sender) closeConn() error {
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.protocol = nil, ""
+	return err
+}
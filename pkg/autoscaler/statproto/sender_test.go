@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statproto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+	"github.com/knative/serving/pkg/autoscaler"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type received struct {
+	MessageType int
+	Messages    []autoscaler.StatMessage
+}
+
+// newTestServer creates a websocket server speaking the given protocols and
+// sending all frames it receives to the returned channel.
+func newTestServer(t *testing.T, subprotocols []string) (*httptest.Server, chan received) {
+	ch := make(chan received, 10)
+	upgrader := websocket.Upgrader{Subprotocols: subprotocols}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade() = %v", err)
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			sms, err := Decode(messageType, msg)
+			if err != nil {
+				t.Errorf("Decode() = %v", err)
+			}
+			ch <- received{MessageType: messageType, Messages: sms}
+		}
+	}))
+	return server, ch
+}
+
+func TestSender(t *testing.T) {
+	tests := []struct {
+		name         string
+		subprotocols []string
+		want         []received
+	}{{
+		name:         "json in one frame",
+		subprotocols: Subprotocols,
+		want: []received{{
+			MessageType: websocket.TextMessage,
+			Messages:    []autoscaler.StatMessage{*testMessages[0], *testMessages[1]},
+		}},
+	}, {
+		name: "gob fallback",
+		want: []received{{
+			MessageType: websocket.BinaryMessage,
+			Messages:    []autoscaler.StatMessage{*testMessages[0]},
+		}, {
+			MessageType: websocket.BinaryMessage,
+			Messages:    []autoscaler.StatMessage{*testMessages[1]},
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, ch := newTestServer(t, test.subprotocols)
+			defer server.Close()
+
+			sender := NewSender("ws"+strings.TrimPrefix(server.URL, "http"), zap.NewNop().Sugar())
+			defer sender.Shutdown()
+
+			if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+				return sender.Status() == nil, nil
+			}); err != nil {
+				t.Fatal("Sender never connected")
+			}
+			if err := sender.Send(testMessages...); err != nil {
+				t.Fatalf("Send() = %v", err)
+			}
+
+			got := make([]received, 0, len(test.want))
+			for range test.want {
+				select {
+				case r := <-ch:
+					got = append(got, r)
+				case <-time.After(5 * time.Second):
+					t.Fatal("Timed out waiting for a frame")
+				}
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Received frames differ (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestSenderNotConnected(t *testing.T) {
+	// Nothing listens on the discard port.
+	sender := NewSender("ws://127.0.0.1:9", zap.NewNop().Sugar())
+	defer sender.Shutdown()
+
+	if err := sender.Status(); err != ErrNotConnected {
+		t.Errorf("Status() = %v, want %v", err, ErrNotConnected)
+	}
+	if err := sender.Send(testMessages...); err != ErrNotConnected {
+		t.Errorf("Send() = %v, want %v", err, ErrNotConnected)
+	}
+}
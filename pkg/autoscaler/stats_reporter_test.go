@@ -67,6 +67,10 @@ func TestReporter_Report(t *testing.T) {
 	expectSuccess(t, "ReportStableRequestConcurrency", func() error { return r.ReportStableRequestConcurrency(2) })
 	expectSuccess(t, "ReportPanicRequestConcurrency", func() error { return r.ReportPanicRequestConcurrency(3) })
 	expectSuccess(t, "ReportTargetRequestConcurrency", func() error { return r.ReportTargetRequestConcurrency(0.9) })
+	expectSuccess(t, "ReportStableRPS", func() error { return r.ReportStableRPS(5) })
+	expectSuccess(t, "ReportPanicRPS", func() error { return r.ReportPanicRPS(6) })
+	expectSuccess(t, "ReportTargetRPS", func() error { return r.ReportTargetRPS(7.5) })
+	expectSuccess(t, "ReportMaxScaleDownRate", func() error { return r.ReportMaxScaleDownRate(2) })
 	assertData(t, "desired_pods", wantTags, 10)
 	assertData(t, "requested_pods", wantTags, 7)
 	assertData(t, "actual_pods", wantTags, 5)
@@ -74,6 +78,10 @@ func TestReporter_Report(t *testing.T) {
 	assertData(t, "stable_request_concurrency", wantTags, 2)
 	assertData(t, "panic_request_concurrency", wantTags, 3)
 	assertData(t, "target_concurrency_per_pod", wantTags, 0.9)
+	assertData(t, "stable_requests_per_second", wantTags, 5)
+	assertData(t, "panic_requests_per_second", wantTags, 6)
+	assertData(t, "target_requests_per_second", wantTags, 7.5)
+	assertData(t, "max_scale_down_rate", wantTags, 2)
 
 	// All the stats are gauges - record multiple entries for one stat - last one should stick
 	expectSuccess(t, "ReportDesiredPodCount", func() error { return r.ReportDesiredPodCount(1) })
@@ -18,6 +18,7 @@ package autoscaler
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"sync"
 	"time"
@@ -176,25 +177,65 @@ func (s *ServiceScraper) Scrape() (*StatMessage, error) {
 	}
 	close(statCh)
 
+	stats := make([]*Stat, 0, len(statCh))
+	for stat := range statCh {
+		stats = append(stats, stat)
+	}
+	return extrapolate(s.metricKey, stats, readyPodsCount), nil
+}
+
+// extrapolate averages the sampled stats and extrapolates the average to
+// all ready pods.
+func extrapolate(metricKey string, stats []*Stat, readyPodsCount int) *StatMessage {
 	var (
 		avgConcurrency        float64
 		avgProxiedConcurrency float64
 		reqCount              float64
 		proxiedReqCount       float64
 		successCount          float64
+
+		// The latencies are weighed by the requests they were measured on.
+		latencyP50, latencyP95, latencyP99 float64
+		latencyReqCount                    float64
+
+		avgQueueLength float64
+		maxQueueLength float64
+		avgQueuedTime  float64
+		queuedReqCount float64
 	)
 
-	for stat := range statCh {
+	for _, stat := range stats {
 		successCount++
 		avgConcurrency += stat.AverageConcurrentRequests
 		avgProxiedConcurrency += stat.AverageProxiedConcurrentRequests
 		reqCount += stat.RequestCount
 		proxiedReqCount += stat.ProxiedRequestCount
+		if stat.RequestLatencyP50 > 0 {
+			latencyP50 += stat.RequestLatencyP50 * stat.RequestCount
+			latencyP95 += stat.RequestLatencyP95 * stat.RequestCount
+			latencyP99 += stat.RequestLatencyP99 * stat.RequestCount
+			latencyReqCount += stat.RequestCount
+		}
+		avgQueueLength += stat.AverageQueueLength
+		maxQueueLength = math.Max(maxQueueLength, stat.MaxQueueLength)
+		if stat.AverageQueuedTime > 0 {
+			avgQueuedTime += stat.AverageQueuedTime * stat.RequestCount
+			queuedReqCount += stat.RequestCount
+		}
+	}
+	if latencyReqCount > 0 {
+		latencyP50 /= latencyReqCount
+		latencyP95 /= latencyReqCount
+		latencyP99 /= latencyReqCount
+	}
+	if queuedReqCount > 0 {
+		avgQueuedTime /= queuedReqCount
 	}
 
 	frpc := float64(readyPodsCount)
 	avgConcurrency = avgConcurrency / successCount
 	avgProxiedConcurrency = avgProxiedConcurrency / successCount
+	avgQueueLength = avgQueueLength / successCount
 	reqCount = reqCount / successCount
 	proxiedReqCount = proxiedReqCount / successCount
 	now := time.Now()
@@ -215,10 +256,18 @@ func (s *ServiceScraper) Scrape() (*StatMessage, error) {
 		AverageProxiedConcurrentRequests: avgProxiedConcurrency * frpc,
 		RequestCount:                     reqCount * frpc,
 		ProxiedRequestCount:              proxiedReqCount * frpc,
+		AverageQueueLength:               avgQueueLength * frpc,
+		// The latency, the peak queue length and the time queued do not
+		// add up over pods.
+		RequestLatencyP50: latencyP50,
+		RequestLatencyP95: latencyP95,
+		RequestLatencyP99: latencyP99,
+		MaxQueueLength:    maxQueueLength,
+		AverageQueuedTime: avgQueuedTime,
 	}
 
 	return &StatMessage{
 		Stat: extrapolatedStat,
-		Key:  s.metricKey,
-	}, nil
+		Key:  metricKey,
+	}
 }
@@ -18,6 +18,7 @@ package autoscaler
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -165,6 +166,73 @@ func TestScrapeReportStatWhenAllCallsSucceed(t *testing.T) {
 	}
 }
 
+func TestExtrapolateLatency(t *testing.T) {
+	stats := []*Stat{{
+		RequestCount:      30,
+		RequestLatencyP50: 0.1,
+		RequestLatencyP95: 0.2,
+		RequestLatencyP99: 0.3,
+	}, {
+		RequestCount:      10,
+		RequestLatencyP50: 0.5,
+		RequestLatencyP95: 0.6,
+		RequestLatencyP99: 0.7,
+	}, {
+		// Pods without requests or older queue-proxies report no latency.
+		RequestCount: 20,
+	}}
+
+	got := extrapolate(testKPAKey, stats, 6).Stat
+	// The latency is weighed by the requests and not extrapolated.
+	for _, l := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"p50", got.RequestLatencyP50, 0.2},
+		{"p95", got.RequestLatencyP95, 0.3},
+		{"p99", got.RequestLatencyP99, 0.4},
+	} {
+		if math.Abs(l.got-l.want) > 1e-9 {
+			t.Errorf("StatMessage.Stat latency %s = %v, want %v", l.name, l.got, l.want)
+		}
+	}
+	// (30 + 10 + 20) / 3 * 6 = 120
+	if got.RequestCount != 120 {
+		t.Errorf("StatMessage.Stat.RequestCount = %v, want 120", got.RequestCount)
+	}
+}
+
+func TestExtrapolateQueue(t *testing.T) {
+	stats := []*Stat{{
+		RequestCount:       30,
+		AverageQueueLength: 2,
+		MaxQueueLength:     4,
+		AverageQueuedTime:  0.1,
+	}, {
+		RequestCount:       10,
+		AverageQueueLength: 1,
+		MaxQueueLength:     2,
+		AverageQueuedTime:  0.5,
+	}, {
+		// Pods without queued requests or older queue-proxies report nothing.
+		RequestCount: 20,
+	}}
+
+	got := extrapolate(testKPAKey, stats, 6).Stat
+	// (2 + 1 + 0) / 3 * 6 = 6
+	if got.AverageQueueLength != 6 {
+		t.Errorf("StatMessage.Stat.AverageQueueLength = %v, want 6", got.AverageQueueLength)
+	}
+	// The peak of any pod, not extrapolated.
+	if got.MaxQueueLength != 4 {
+		t.Errorf("StatMessage.Stat.MaxQueueLength = %v, want 4", got.MaxQueueLength)
+	}
+	// The time queued is weighed by the requests and not extrapolated.
+	if want := 0.2; math.Abs(got.AverageQueuedTime-want) > 1e-9 {
+		t.Errorf("StatMessage.Stat.AverageQueuedTime = %v, want %v", got.AverageQueuedTime, want)
+	}
+}
+
 func TestScrapeReportStatWhenAtLeastOneCallSucceeds(t *testing.T) {
 	errTest := errors.New("test")
 	client := newTestScrapeClient(testStats, []error{nil, errTest, errTest})
@@ -17,9 +17,7 @@ limitations under the License.
 package statserver
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
 	"net"
 	"net/http"
 	"sync"
@@ -27,19 +25,26 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/statproto"
 	"github.com/knative/serving/pkg/network"
 	"go.uber.org/zap"
 )
 
 const closeCodeServiceRestart = 1012 // See https://www.iana.org/assignments/websocket/websocket.xhtml
 
+// ForwardedPath is the path other autoscaler replicas send the stats to
+// that they received but don't own.
+const ForwardedPath = "/forwarded"
+
 // Server receives autoscaler statistics over WebSocket and sends them to a channel.
 type Server struct {
 	addr        string
+	mux         *http.ServeMux
 	wsSrv       http.Server
 	servingCh   chan struct{}
 	stopCh      chan struct{}
 	statsCh     chan<- *autoscaler.StatMessage
+	forwardedCh chan<- *autoscaler.StatMessage
 	openClients sync.WaitGroup
 	logger      *zap.SugaredLogger
 }
@@ -51,20 +56,35 @@ func New(statsServerAddr string, statsCh chan<- *autoscaler.StatMessage, logger
 		servingCh:   make(chan struct{}),
 		stopCh:      make(chan struct{}),
 		statsCh:     statsCh,
+		forwardedCh: statsCh,
 		openClients: sync.WaitGroup{},
 		logger:      logger.Named("stats-websocket-server").With("address", statsServerAddr),
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", svr.Handler)
+	svr.mux = http.NewServeMux()
+	svr.mux.HandleFunc("/", svr.Handler)
+	svr.mux.HandleFunc(ForwardedPath, svr.forwardedHandler)
 	svr.wsSrv = http.Server{
 		Addr:      statsServerAddr,
-		Handler:   mux,
+		Handler:   svr.mux,
 		ConnState: svr.onConnStateChange,
 	}
 	return &svr
 }
 
+// Forwarded makes the Server send the stat messages forwarded by other
+// autoscaler replicas to forwardedCh rather than statsCh. It must be called
+// before ListenAndServe.
+func (s *Server) Forwarded(forwardedCh chan<- *autoscaler.StatMessage) {
+	s.forwardedCh = forwardedCh
+}
+
+// Handle serves the requests to the given path with the handler, e.g. for
+// other autoscaler replicas. It must be called before ListenAndServe.
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.mux.Handle(path, handler)
+}
+
 func (s *Server) onConnStateChange(conn net.Conn, state http.ConnState) {
 	if state == http.StateNew {
 		tcpConn := conn.(*net.TCPConn)
@@ -109,11 +129,25 @@ func handleHealthz(w http.ResponseWriter, r *http.Request) bool {
 // Handler exposes a websocket handler for receiving stats from queue
 // sidecar containers.
 func (s *Server) Handler(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, s.statsCh)
+}
+
+// forwardedHandler exposes a websocket handler for receiving stats from
+// other autoscaler replicas.
+func (s *Server) forwardedHandler(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, s.forwardedCh)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, statsCh chan<- *autoscaler.StatMessage) {
 	s.logger.Debug("Handle entered")
 	if handleHealthz(w, r) {
 		return
 	}
-	var upgrader websocket.Upgrader
+	upgrader := websocket.Upgrader{
+		// Let clients choose the newest protocol we both speak.
+		// Clients not asking for any protocol send gob.
+		Subprotocols: statproto.Subprotocols,
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		s.logger.Errorw("error upgrading websocket", zap.Error(err))
@@ -154,22 +188,19 @@ func (s *Server) Handler(w http.ResponseWriter, r *http.Request) {
 			close(handlerCh)
 			return
 		}
-		if messageType != websocket.BinaryMessage {
-			s.logger.Error("Dropping non-binary message.")
-			continue
-		}
-		dec := gob.NewDecoder(bytes.NewBuffer(msg))
-		var sm autoscaler.StatMessage
-		err = dec.Decode(&sm)
+		sms, err := statproto.Decode(messageType, msg)
 		if err != nil {
-			s.logger.Error(err)
+			s.logger.Errorw("Dropping undecodable message", zap.Error(err))
 			continue
 		}
 		now := time.Now()
-		sm.Stat.Time = &now
+		for i := range sms {
+			sm := &sms[i]
+			sm.Stat.Time = &now
 
-		s.logger.Debugf("Received stat message: %+v", sm)
-		s.statsCh <- &sm
+			s.logger.Debugf("Received stat message: %+v", sm)
+			statsCh <- sm
+		}
 	}
 }
 
@@ -30,6 +30,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/gorilla/websocket"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/statproto"
 	stats "github.com/knative/serving/pkg/autoscaler/statserver"
 	"golang.org/x/sync/errgroup"
 )
@@ -88,6 +89,70 @@ func TestStatsReceived(t *testing.T) {
 	closeSink(statSink, t)
 }
 
+func TestStatsReceivedJSON(t *testing.T) {
+	statsCh := make(chan *autoscaler.StatMessage)
+	server := stats.NewTestServer(statsCh)
+
+	defer server.Shutdown(0)
+	go server.ListenAndServe()
+
+	u, err := url.Parse(server.ListenAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Scheme = "ws"
+	dialer := &websocket.Dialer{
+		HandshakeTimeout: time.Second,
+		Subprotocols:     []string{"unknown", statproto.JSONv1},
+	}
+	statSink, _, err := dialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatal("Dial failed:", err)
+	}
+	defer closeSink(statSink, t)
+	if got, want := statSink.Subprotocol(), statproto.JSONv1; got != want {
+		t.Errorf("Subprotocol() = %q, want %q", got, want)
+	}
+
+	// Both messages are sent in a single frame.
+	want := []*autoscaler.StatMessage{
+		newStatMessage("test-namespace/test-revision", "activator1", 2.1, 51),
+		newStatMessage("test-namespace/test-revision2", "activator1", 2.2, 30),
+	}
+	messageType, frames, err := statproto.Encode(statproto.JSONv1, want)
+	if err != nil {
+		t.Fatal("Encode() =", err)
+	}
+	if err := statSink.WriteMessage(messageType, frames[0]); err != nil {
+		t.Fatal("Failed to write to stat sink:", err)
+	}
+
+	got := []*autoscaler.StatMessage{<-statsCh, <-statsCh}
+	ignoreTimeField := cmpopts.IgnoreFields(autoscaler.StatMessage{}, "Stat.Time")
+	if diff := cmp.Diff(want, got, ignoreTimeField); diff != "" {
+		t.Errorf("StatMessage mismatch: diff (-want, +got) %s", diff)
+	}
+}
+
+func TestStatsForwarded(t *testing.T) {
+	statsCh := make(chan *autoscaler.StatMessage)
+	forwardedCh := make(chan *autoscaler.StatMessage)
+	server := stats.NewTestServer(statsCh)
+	server.Forwarded(forwardedCh)
+
+	defer server.Shutdown(0)
+	go server.ListenAndServe()
+
+	statSink := dialOk(server.ListenAddr(), t)
+	forwardSink := dialOk(server.ListenAddr()+stats.ForwardedPath, t)
+
+	assertReceivedOk(newStatMessage("test-namespace/test-revision", "activator1", 2.1, 51), statSink, statsCh, t)
+	assertReceivedOk(newStatMessage("test-namespace/test-revision2", "activator2", 2.2, 30), forwardSink, forwardedCh, t)
+
+	closeSink(statSink, t)
+	closeSink(forwardSink, t)
+}
+
 func TestServerShutdown(t *testing.T) {
 	statsCh := make(chan *autoscaler.StatMessage)
 	server := stats.NewTestServer(statsCh)
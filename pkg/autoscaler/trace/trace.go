@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trace reads and writes recorded autoscaler statistics as JSON
+// lines, one autoscaler.StatMessage per line.
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/knative/serving/pkg/autoscaler"
+)
+
+// Writer records StatMessages to an underlying io.Writer. It is safe for
+// concurrent use.
+type Writer struct {
+	mux sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriter creates a Writer recording to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+// Write records a single StatMessage as one line.
+func (w *Writer) Write(sm *autoscaler.StatMessage) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return w.enc.Encode(sm)
+}
+
+// Read parses all StatMessages recorded in r. Blank lines are skipped.
+func Read(r io.Reader) ([]autoscaler.StatMessage, error) {
+	var messages []autoscaler.StatMessage
+	scanner := bufio.NewScanner(r)
+	// Allow long lines, stats may carry arbitrarily long pod names.
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var sm autoscaler.StatMessage
+		if err := json.Unmarshal(scanner.Bytes(), &sm); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		messages = append(messages, sm)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
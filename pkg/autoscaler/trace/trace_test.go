@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/knative/pkg/logging/testing"
+	"github.com/knative/serving/pkg/autoscaler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t0 := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+	want := []autoscaler.StatMessage{{
+		Key: "ns/rev",
+		Stat: autoscaler.Stat{
+			Time:                      &t0,
+			PodName:                   "pod-1",
+			AverageConcurrentRequests: 3.5,
+			RequestCount:              7,
+		},
+	}, {
+		Key: "ns/rev",
+		Stat: autoscaler.Stat{
+			Time:                             &t1,
+			PodName:                          "activator",
+			AverageConcurrentRequests:        1,
+			AverageProxiedConcurrentRequests: 1,
+		},
+	}}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for i := range want {
+		if err := w.Write(&want[i]); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+	}
+	if got, want := strings.Count(buf.String(), "\n"), len(want); got != want {
+		t.Errorf("Got %d lines, want %d", got, want)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Read() (-want, +got) = %v", diff)
+	}
+}
+
+func TestReadSkipsBlankLines(t *testing.T) {
+	in := "\n" + `{"Key":"ns/rev","Stat":{"PodName":"pod-1"}}` + "\n\n"
+	got, err := Read(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Read() = %v", err)
+	}
+	if len(got) != 1 || got[0].Stat.PodName != "pod-1" {
+		t.Errorf("Read() = %v, want a single message for pod-1", got)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	in := `{"Key":"ns/rev"}` + "\n" + "not json\n"
+	_, err := Read(strings.NewReader(in))
+	if err == nil {
+		t.Fatal("Read() = nil, wanted an error")
+	}
+	if !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("Read() = %v, want the error to name line 2", err)
+	}
+}
+
+func TestCollectorRecordsScrapedStats(t *testing.T) {
+	now := time.Now()
+	scraped := autoscaler.StatMessage{
+		Key: "ns/rev",
+		Stat: autoscaler.Stat{
+			Time:                      &now,
+			PodName:                   "pod-1",
+			AverageConcurrentRequests: 3,
+		},
+	}
+	factory := func(*autoscaler.Metric) (autoscaler.StatsScraper, error) {
+		return &testScraper{sm: &scraped}, nil
+	}
+
+	var buf syncBuffer
+	collector := autoscaler.NewMetricCollector(factory, TestLogger(t))
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	collector.RecordTo(NewWriter(&buf), stopCh)
+	metric := &autoscaler.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "rev"},
+		Spec: autoscaler.MetricSpec{
+			StableWindow: time.Minute,
+			PanicWindow:  6 * time.Second,
+		},
+	}
+	if _, err := collector.Create(context.Background(), metric); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	defer collector.Delete(context.Background(), "ns", "rev")
+
+	var got []autoscaler.StatMessage
+	wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		var err error
+		got, err = Read(strings.NewReader(buf.String()))
+		return err == nil && len(got) > 0, err
+	})
+	if len(got) == 0 {
+		t.Fatal("The scraped stat was never recorded")
+	}
+	if diff := cmp.Diff(scraped, got[0]); diff != "" {
+		t.Errorf("Recorded stat (-want, +got) = %v", diff)
+	}
+}
+
+type testScraper struct {
+	sm *autoscaler.StatMessage
+}
+
+func (s *testScraper) Scrape() (*autoscaler.StatMessage, error) {
+	return s.sm, nil
+}
+
+func (s *testScraper) UpdateTarget(target, ns string) {}
+
+// syncBuffer is a bytes.Buffer safe to read while it's written.
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -27,6 +29,8 @@ var (
 	ErrUpdateCapacity = errors.New("failed to add all capacity to the breaker")
 	// ErrRelease indicates that release was called more often than acquire.
 	ErrRelease = errors.New("semaphore release error: returned tokens must be <= acquired tokens")
+	// ErrRequestQueueFull indicates that the breaker's queue is full.
+	ErrRequestQueueFull = errors.New("pending request queue full")
 )
 
 // BreakerParams defines the parameters of the breaker.
@@ -43,6 +47,8 @@ type BreakerParams struct {
 type Breaker struct {
 	pendingRequests chan struct{}
 	sem             *semaphore
+	// waiting is the number of requests waiting for capacity.
+	waiting int32
 }
 
 // NewBreaker creates a Breaker with the desired queue depth,
@@ -69,14 +75,32 @@ func NewBreaker(params BreakerParams) *Breaker {
 // already consumed, Maybe returns immediately without calling thunk. If
 // the thunk was executed, Maybe returns true, else false.
 func (b *Breaker) Maybe(thunk func()) bool {
+	return b.MaybeContext(context.Background(), 0, thunk) == nil
+}
+
+// MaybeContext is like Maybe, but gives up waiting for capacity once the
+// context is done, and lets at most maxWaiting requests wait for capacity,
+// 0 leaving the bound to the queue depth. It returns ErrRequestQueueFull if
+// the queue is full and the context's error if it's done before the thunk
+// could be executed.
+func (b *Breaker) MaybeContext(ctx context.Context, maxWaiting int, thunk func()) error {
 	select {
 	default:
 		// Pending request queue is full.  Report failure.
-		return false
+		return ErrRequestQueueFull
 	case b.pendingRequests <- struct{}{}:
 		// Pending request has capacity.
 		// Wait for capacity in the active queue.
-		b.sem.acquire()
+		if !b.addWaiting(maxWaiting) {
+			<-b.pendingRequests
+			return ErrRequestQueueFull
+		}
+		err := b.sem.acquire(ctx)
+		atomic.AddInt32(&b.waiting, -1)
+		if err != nil {
+			<-b.pendingRequests
+			return err
+		}
 		// Defer releasing capacity in the active and pending request queue.
 		defer func() {
 			// It's safe to ignore the error returned by release since we
@@ -88,10 +112,29 @@ func (b *Breaker) Maybe(thunk func()) bool {
 		// Do the thing.
 		thunk()
 		// Report success
-		return true
+		return nil
 	}
 }
 
+// addWaiting counts one more request waiting for capacity, unless there are
+// already maxWaiting of them.
+func (b *Breaker) addWaiting(maxWaiting int) bool {
+	for {
+		waiting := atomic.LoadInt32(&b.waiting)
+		if maxWaiting > 0 && int(waiting) >= maxWaiting {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&b.waiting, waiting, waiting+1) {
+			return true
+		}
+	}
+}
+
+// QueueLength returns the number of requests waiting for capacity.
+func (b *Breaker) QueueLength() int {
+	return int(atomic.LoadInt32(&b.waiting))
+}
+
 // UpdateConcurrency updates the maximum number of in-flight requests.
 func (b *Breaker) UpdateConcurrency(size int) error {
 	return b.sem.updateCapacity(size)
@@ -129,9 +172,15 @@ type semaphore struct {
 	mux      sync.Mutex
 }
 
-// acquire receives the token from the semaphore, potentially blocking.
-func (s *semaphore) acquire() {
-	<-s.queue
+// acquire receives the token from the semaphore, potentially blocking
+// until the context is done.
+func (s *semaphore) acquire(ctx context.Context) error {
+	select {
+	case <-s.queue:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // release potentially puts the token back to the queue.
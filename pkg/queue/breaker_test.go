@@ -17,6 +17,7 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -195,6 +196,80 @@ func TestBreaker_UpdateConcurrency_Overlow(t *testing.T) {
 	}
 }
 
+func TestBreakerMaybeContext(t *testing.T) {
+	b := NewBreaker(BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 0})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- b.MaybeContext(ctx, 0, func() {
+			t.Error("The thunk was called without capacity")
+		})
+	}()
+	if err := wait.PollImmediate(time.Millisecond, semAcquireTimeout, func() (bool, error) {
+		return b.QueueLength() == 1, nil
+	}); err != nil {
+		t.Fatalf("QueueLength() = %d, want: 1", b.QueueLength())
+	}
+
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Errorf("MaybeContext() = %v, want: %v", err, context.Canceled)
+	}
+	if got := b.QueueLength(); got != 0 {
+		t.Errorf("QueueLength() = %d, want: 0", got)
+	}
+	// The request gave up its place in the queue.
+	if got := len(b.pendingRequests); got != 0 {
+		t.Errorf("len(pendingRequests) = %d, want: 0", got)
+	}
+
+	b.UpdateConcurrency(1)
+	called := false
+	if err := b.MaybeContext(context.Background(), 0, func() { called = true }); err != nil || !called {
+		t.Errorf("MaybeContext() = %v, called: %v, want: nil, true", err, called)
+	}
+}
+
+func TestBreakerMaybeContextMaxWaiting(t *testing.T) {
+	const (
+		requests   = 20
+		maxWaiting = 3
+	)
+	b := NewBreaker(BreakerParams{QueueDepth: requests, MaxConcurrency: 1, InitialCapacity: 0})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			errCh <- b.MaybeContext(ctx, maxWaiting, func() {
+				t.Error("The thunk was called without capacity")
+			})
+		}()
+	}
+
+	// All the requests beyond maxWaiting are rejected, however they race.
+	for i := 0; i < requests-maxWaiting; i++ {
+		if err := <-errCh; err != ErrRequestQueueFull {
+			t.Errorf("MaybeContext() = %v, want: %v", err, ErrRequestQueueFull)
+		}
+	}
+	if got := b.QueueLength(); got != maxWaiting {
+		t.Errorf("QueueLength() = %d, want: %d", got, maxWaiting)
+	}
+
+	cancel()
+	for i := 0; i < maxWaiting; i++ {
+		if err := <-errCh; err != context.Canceled {
+			t.Errorf("MaybeContext() = %v, want: %v", err, context.Canceled)
+		}
+	}
+	if got := len(b.pendingRequests); got != 0 {
+		t.Errorf("len(pendingRequests) = %d, want: 0", got)
+	}
+}
+
 // Test empty semaphore, token cannot be acquired
 func TestSemaphore_acquire_HasNoCapacity(t *testing.T) {
 	gotChan := make(chan struct{}, 1)
@@ -238,7 +313,7 @@ func TestSemaphore_acquire_HasCapacity(t *testing.T) {
 
 func TestSemaphore_release(t *testing.T) {
 	sem := newSemaphore(1, 1)
-	sem.acquire()
+	sem.acquire(context.Background())
 	if err := sem.release(); err != nil {
 		t.Errorf("release = %v; want: %v", err, nil)
 	}
@@ -251,8 +326,8 @@ func TestSemaphore_releasesSeveralReducers(t *testing.T) {
 	const wantAfterFirstrelease = 1
 	const wantAfterSecondrelease = 0
 	sem := newSemaphore(2, 2)
-	sem.acquire()
-	sem.acquire()
+	sem.acquire(context.Background())
+	sem.acquire(context.Background())
 	sem.updateCapacity(0)
 	sem.release()
 	if got := sem.Capacity(); got != wantAfterSecondrelease {
@@ -277,7 +352,7 @@ func TestSemaphore_updateCapacity(t *testing.T) {
 	if got, want := sem.Capacity(), 1; got != want {
 		t.Errorf("Capacity = %d, want: %d", got, want)
 	}
-	sem.acquire()
+	sem.acquire(context.Background())
 	sem.updateCapacity(initialCapacity + 2)
 	if got, want := sem.Capacity(), 3; got != want {
 		t.Errorf("Capacity = %d, want: %d", got, want)
@@ -288,8 +363,8 @@ func TestSemaphore_updateCapacity(t *testing.T) {
 func TestSemaphore_updateCapacity_LessThenReducers(t *testing.T) {
 	const initialCapacity = 2
 	sem := newSemaphore(2, initialCapacity)
-	sem.acquire()
-	sem.acquire()
+	sem.acquire(context.Background())
+	sem.acquire(context.Background())
 	sem.updateCapacity(initialCapacity - 2)
 	if got, want := sem.reducers, 2; got != want {
 		t.Errorf("sem.reducers = %d, want: %d", got, want)
@@ -305,8 +380,8 @@ func TestSemaphore_updateCapacity_LessThenReducers(t *testing.T) {
 func TestSemaphore_updateCapacity_ConsumingReducers(t *testing.T) {
 	const initialCapacity = 2
 	sem := newSemaphore(2, initialCapacity)
-	sem.acquire()
-	sem.acquire()
+	sem.acquire(context.Background())
+	sem.acquire(context.Background())
 	sem.updateCapacity(initialCapacity - 2)
 	if got, want := sem.reducers, 2; got != want {
 		t.Errorf("sem.reducers = %d, want: %d", got, want)
@@ -327,7 +402,7 @@ func TestSemaphore_updateCapacity_Overflow(t *testing.T) {
 
 func TestSemaphore_updateCapacity_OutOfBound(t *testing.T) {
 	sem := newSemaphore(1, 1)
-	sem.acquire()
+	sem.acquire(context.Background())
 	if err := sem.updateCapacity(-1); err != ErrUpdateCapacity {
 		t.Errorf("updateCapacity = %v, want: %v", err, ErrUpdateCapacity)
 	}
@@ -426,7 +501,7 @@ func unlockAll(requests []request) {
 func tryAcquire(sem *semaphore, gotChan chan struct{}) {
 	go func() {
 		// blocking until someone puts the token into the semaphore
-		sem.acquire()
+		sem.acquire(context.Background())
 		gotChan <- struct{}{}
 	}()
 }
@@ -58,6 +58,24 @@ var (
 	averageProxiedConcurrentRequestsGV = newGV(
 		"queue_average_proxied_concurrent_requests",
 		"Number of proxied requests currently being handled by this pod")
+	requestLatencyP50GV = newGV(
+		"queue_request_latency_p50_seconds",
+		"The 50th percentile of the latency of the requests handled by this pod")
+	requestLatencyP95GV = newGV(
+		"queue_request_latency_p95_seconds",
+		"The 95th percentile of the latency of the requests handled by this pod")
+	requestLatencyP99GV = newGV(
+		"queue_request_latency_p99_seconds",
+		"The 99th percentile of the latency of the requests handled by this pod")
+	averageQueueLengthGV = newGV(
+		"queue_average_queue_length",
+		"Number of requests waiting for capacity in this pod")
+	maxQueueLengthGV = newGV(
+		"queue_max_queue_length",
+		"Peak number of requests waiting for capacity in this pod")
+	averageQueuedSecondsGV = newGV(
+		"queue_average_queued_seconds",
+		"Average time requests waited for capacity in this pod")
 )
 
 func newGV(n, h string) *prometheus.GaugeVec {
@@ -90,7 +108,8 @@ func NewPrometheusStatsReporter(namespace, config, revision, pod string) (*Prome
 	}
 
 	registry := prometheus.NewRegistry()
-	for _, gv := range []*prometheus.GaugeVec{operationsPerSecondGV, proxiedOperationsPerSecondGV, averageConcurrentRequestsGV, averageProxiedConcurrentRequestsGV} {
+	for _, gv := range []*prometheus.GaugeVec{operationsPerSecondGV, proxiedOperationsPerSecondGV, averageConcurrentRequestsGV, averageProxiedConcurrentRequestsGV,
+		requestLatencyP50GV, requestLatencyP95GV, requestLatencyP99GV, averageQueueLengthGV, maxQueueLengthGV, averageQueuedSecondsGV} {
 		if err := registry.Register(gv); err != nil {
 			return nil, fmt.Errorf("register metric failed: %v", err)
 		}
@@ -118,6 +137,12 @@ func (r *PrometheusStatsReporter) Report(stat *autoscaler.Stat) error {
 	proxiedOperationsPerSecondGV.With(r.labels).Set(stat.ProxiedRequestCount)
 	averageConcurrentRequestsGV.With(r.labels).Set(stat.AverageConcurrentRequests)
 	averageProxiedConcurrentRequestsGV.With(r.labels).Set(stat.AverageProxiedConcurrentRequests)
+	requestLatencyP50GV.With(r.labels).Set(stat.RequestLatencyP50)
+	requestLatencyP95GV.With(r.labels).Set(stat.RequestLatencyP95)
+	requestLatencyP99GV.With(r.labels).Set(stat.RequestLatencyP99)
+	averageQueueLengthGV.With(r.labels).Set(stat.AverageQueueLength)
+	maxQueueLengthGV.With(r.labels).Set(stat.MaxQueueLength)
+	averageQueuedSecondsGV.With(r.labels).Set(stat.AverageQueuedTime)
 
 	return nil
 }
@@ -98,6 +98,32 @@ func TestReporter_Report(t *testing.T) {
 	testReportWithProxiedRequests(t, &autoscaler.Stat{RequestCount: 39, AverageConcurrentRequests: 3, ProxiedRequestCount: 15, AverageProxiedConcurrentRequests: 2}, 39, 3, 15, 2)
 }
 
+func TestReporter_ReportLatency(t *testing.T) {
+	reporter, err := NewPrometheusStatsReporter(namespace, config, revision, pod)
+	if err != nil {
+		t.Fatalf("Something went wrong with creating a reporter, '%v'.", err)
+	}
+	if err := reporter.Report(&autoscaler.Stat{RequestLatencyP50: 0.1, RequestLatencyP95: 0.2, RequestLatencyP99: 0.4}); err != nil {
+		t.Error(err)
+	}
+	checkData(t, requestLatencyP50GV, 0.1)
+	checkData(t, requestLatencyP95GV, 0.2)
+	checkData(t, requestLatencyP99GV, 0.4)
+}
+
+func TestReporter_ReportQueue(t *testing.T) {
+	reporter, err := NewPrometheusStatsReporter(namespace, config, revision, pod)
+	if err != nil {
+		t.Fatalf("Something went wrong with creating a reporter, '%v'.", err)
+	}
+	if err := reporter.Report(&autoscaler.Stat{AverageQueueLength: 1.5, MaxQueueLength: 3, AverageQueuedTime: 0.2}); err != nil {
+		t.Error(err)
+	}
+	checkData(t, averageQueueLengthGV, 1.5)
+	checkData(t, maxQueueLengthGV, 3)
+	checkData(t, averageQueuedSecondsGV, 0.2)
+}
+
 func testReportWithProxiedRequests(t *testing.T, stat *autoscaler.Stat, reqCount, concurrency, proxiedCount, proxiedConcurrency float64) {
 	t.Helper()
 	reporter, err := NewPrometheusStatsReporter(namespace, config, revision, pod)
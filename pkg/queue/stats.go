@@ -17,6 +17,9 @@ limitations under the License.
 package queue
 
 import (
+	"math"
+	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/knative/serving/pkg/autoscaler"
@@ -26,8 +29,16 @@ import (
 type ReqEvent struct {
 	Time      time.Time
 	EventType ReqEventType
+	// Latency is the time it took to finish the request for ReqOut and
+	// ProxiedOut events, and the time the request spent queued for
+	// ReqDequeued events.
+	Latency time.Duration
 }
 
+// maxLatencySamples is the number of request latencies kept per reporting
+// period to compute the percentiles from.
+const maxLatencySamples = 1000
+
 // ReqEventType denotes the type (incoming/closed) of a ReqEvent.
 type ReqEventType int
 
@@ -40,6 +51,12 @@ const (
 	ProxiedIn
 	// ProxiedOut represents a finished proxied request.
 	ProxiedOut
+	// ReqQueued represents a request starting to wait for capacity.
+	ReqQueued
+	// ReqDequeued represents a queued request getting capacity.
+	ReqDequeued
+	// ReqRejected represents a queued request rejected as the queue is full.
+	ReqRejected
 )
 
 // Channels is a structure for holding the channels for driving Stats.
@@ -72,11 +89,18 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 			proxiedCount       float64
 			concurrency        int32
 			proxiedConcurrency int32
+			latencies          = newLatencySample(maxLatencySamples)
+
+			queueLength    int32
+			maxQueueLength int32
+			queuedTime     time.Duration
+			dequeuedCount  float64
 		)
 
 		lastChange := startedAt
 		timeOnConcurrency := make(map[int32]time.Duration)
 		timeOnProxiedConcurrency := make(map[int32]time.Duration)
+		timeOnQueueLength := make(map[int32]time.Duration)
 
 		// Updates the lastChanged/timeOnConcurrency state
 		// Note: Due to nature of the channels used below, the ReportChan
@@ -88,6 +112,7 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 				durationSinceChange := time.Sub(lastChange)
 				timeOnConcurrency[concurrency] += durationSinceChange
 				timeOnProxiedConcurrency[proxiedConcurrency] += durationSinceChange
+				timeOnQueueLength[queueLength] += durationSinceChange
 				lastChange = time
 			}
 		}
@@ -110,10 +135,28 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 					fallthrough
 				case ReqOut:
 					concurrency--
+					if event.Latency > 0 {
+						latencies.add(event.Latency)
+					}
+				case ReqQueued:
+					queueLength++
+					if queueLength > maxQueueLength {
+						maxQueueLength = queueLength
+					}
+				case ReqDequeued:
+					queueLength--
+					queuedTime += event.Latency
+					dequeuedCount++
+				case ReqRejected:
+					queueLength--
 				}
 			case now := <-s.ch.ReportChan:
 				updateState(now)
 
+				var averageQueuedTime float64
+				if dequeuedCount > 0 {
+					averageQueuedTime = queuedTime.Seconds() / dequeuedCount
+				}
 				stat := &autoscaler.Stat{
 					Time:                             &now,
 					PodName:                          s.podName,
@@ -121,6 +164,12 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 					AverageProxiedConcurrentRequests: weightedAverage(timeOnProxiedConcurrency),
 					RequestCount:                     requestCount,
 					ProxiedRequestCount:              proxiedCount,
+					RequestLatencyP50:                latencies.percentile(50),
+					RequestLatencyP95:                latencies.percentile(95),
+					RequestLatencyP99:                latencies.percentile(99),
+					AverageQueueLength:               weightedAverage(timeOnQueueLength),
+					MaxQueueLength:                   float64(maxQueueLength),
+					AverageQueuedTime:                averageQueuedTime,
 				}
 				// Send the stat to another goroutine to transmit
 				// so we can continue bucketing stats.
@@ -131,6 +180,12 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 				timeOnProxiedConcurrency = make(map[int32]time.Duration)
 				requestCount = 0
 				proxiedCount = 0
+				latencies.reset()
+				timeOnQueueLength = make(map[int32]time.Duration)
+				// The requests still queued count towards the next period.
+				maxQueueLength = queueLength
+				queuedTime = 0
+				dequeuedCount = 0
 			}
 		}
 	}()
@@ -153,3 +208,44 @@ func weightedAverage(times map[int32]time.Duration) float64 {
 	}
 	return avg
 }
+
+// latencySample is a uniform random sample of bounded size of the request
+// latencies seen since the last reset.
+type latencySample struct {
+	samples []time.Duration
+	seen    int
+}
+
+func newLatencySample(size int) *latencySample {
+	return &latencySample{samples: make([]time.Duration, 0, size)}
+}
+
+// add adds a latency to the sample, replacing a random one once the
+// sample is full.
+func (l *latencySample) add(latency time.Duration) {
+	l.seen++
+	if len(l.samples) < cap(l.samples) {
+		l.samples = append(l.samples, latency)
+	} else if i := rand.Intn(l.seen); i < len(l.samples) {
+		l.samples[i] = latency
+	}
+}
+
+// percentile returns the latency in seconds at the given percentile by the
+// nearest-rank method, or 0 if the sample is empty.
+func (l *latencySample) percentile(p int) float64 {
+	if len(l.samples) == 0 {
+		return 0
+	}
+	sort.Slice(l.samples, func(i, j int) bool { return l.samples[i] < l.samples[j] })
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(l.samples))))
+	if rank < 1 {
+		rank = 1
+	}
+	return l.samples[rank-1].Seconds()
+}
+
+func (l *latencySample) reset() {
+	l.samples = l.samples[:0]
+	l.seen = 0
+}
@@ -257,6 +257,92 @@ func TestTwoRequestsOneProxied(t *testing.T) {
 	}
 }
 
+func TestRequestLatency(t *testing.T) {
+	now := time.Now()
+	s := newTestStats(now)
+
+	// Ten requests taking 100ms to 1s, one of them proxied.
+	for i := 1; i <= 10; i++ {
+		s.requestStart(now)
+		latency := time.Duration(i) * 100 * time.Millisecond
+		if i == 5 {
+			s.proxiedStart(now)
+			s.ch.ReqChan <- ReqEvent{Time: now.Add(latency), EventType: ProxiedOut, Latency: latency}
+			continue
+		}
+		s.ch.ReqChan <- ReqEvent{Time: now.Add(latency), EventType: ReqOut, Latency: latency}
+	}
+	// A request still in flight has no latency yet.
+	s.requestStart(now)
+
+	now = now.Add(time.Second)
+	got := s.report(now)
+	if got.RequestLatencyP50 != 0.5 || got.RequestLatencyP95 != 1 || got.RequestLatencyP99 != 1 {
+		t.Errorf("Latency p50, p95, p99 = %v, %v, %v, want 0.5, 1, 1",
+			got.RequestLatencyP50, got.RequestLatencyP95, got.RequestLatencyP99)
+	}
+
+	// The latency is reset with every report.
+	now = now.Add(time.Second)
+	got = s.report(now)
+	if got.RequestLatencyP50 != 0 || got.RequestLatencyP95 != 0 || got.RequestLatencyP99 != 0 {
+		t.Errorf("Latency p50, p95, p99 = %v, %v, %v, want 0, 0, 0",
+			got.RequestLatencyP50, got.RequestLatencyP95, got.RequestLatencyP99)
+	}
+}
+
+func TestQueueLength(t *testing.T) {
+	now := time.Now()
+	s := newTestStats(now)
+
+	// Two requests queue up, one of them waits for half of the time and
+	// the other one is rejected after a quarter of it.
+	s.ch.ReqChan <- ReqEvent{Time: now, EventType: ReqQueued}
+	s.ch.ReqChan <- ReqEvent{Time: now, EventType: ReqQueued}
+	s.ch.ReqChan <- ReqEvent{Time: now.Add(250 * time.Millisecond), EventType: ReqRejected}
+	s.ch.ReqChan <- ReqEvent{Time: now.Add(500 * time.Millisecond), EventType: ReqDequeued, Latency: 500 * time.Millisecond}
+	// A third one is still queued when reporting.
+	s.ch.ReqChan <- ReqEvent{Time: now.Add(750 * time.Millisecond), EventType: ReqQueued}
+
+	now = now.Add(time.Second)
+	got := s.report(now)
+	if got.AverageQueueLength != 1 || got.MaxQueueLength != 2 || got.AverageQueuedTime != 0.5 {
+		t.Errorf("Queue average, max, time = %v, %v, %v, want 1, 2, 0.5",
+			got.AverageQueueLength, got.MaxQueueLength, got.AverageQueuedTime)
+	}
+
+	// The request still queued carries over into the next report.
+	now = now.Add(time.Second)
+	got = s.report(now)
+	if got.AverageQueueLength != 1 || got.MaxQueueLength != 1 || got.AverageQueuedTime != 0 {
+		t.Errorf("Queue average, max, time = %v, %v, %v, want 1, 1, 0",
+			got.AverageQueueLength, got.MaxQueueLength, got.AverageQueuedTime)
+	}
+}
+
+func TestLatencySample(t *testing.T) {
+	l := newLatencySample(10)
+	if got := l.percentile(50); got != 0 {
+		t.Errorf("percentile(50) of an empty sample = %v, want 0", got)
+	}
+
+	for i := 1; i <= 1000; i++ {
+		l.add(time.Duration(i) * time.Millisecond)
+	}
+	if got := len(l.samples); got != 10 {
+		t.Errorf("len(samples) = %d, want 10", got)
+	}
+	if min, max := l.percentile(0), l.percentile(100); min < 0.001 || max > 1 || min > max {
+		t.Errorf("sample spans [%v, %v], want within [0.001, 1]", min, max)
+	}
+
+	l.reset()
+	l.add(time.Second)
+	if got := l.percentile(99); got != 1 {
+		t.Errorf("percentile(99) after reset = %v, want 1", got)
+	}
+}
+
 // Test type to hold the bi-directional time channels
 type testStats struct {
 	Stats
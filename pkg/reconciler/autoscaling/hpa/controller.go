@@ -29,6 +29,7 @@ import (
 	"github.com/knative/pkg/controller"
 	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
 	"github.com/knative/serving/pkg/reconciler"
 	areconciler "github.com/knative/serving/pkg/reconciler/autoscaling"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/config"
@@ -63,8 +64,10 @@ func NewController(
 			PSInformerFactory: psInformerFactory,
 		},
 		hpaLister: hpaInformer.Lister(),
+		shards:    sharding.FromContext(ctx),
 	}
 	impl := controller.NewImpl(c, c.Logger, "HPA-Class Autoscaling")
+	c.enqueueAfter = impl.EnqueueAfter
 
 	c.Logger.Info("Setting up hpa-class event handlers")
 	onlyHpaClass := reconciler.AnnotationFilterFunc(autoscaling.ClassAnnotationKey, autoscaling.HPA, false)
@@ -92,6 +95,11 @@ func NewController(
 		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
 	})
 	configStore := config.NewStore(c.Logger.Named("config-store"), resync)
+
+	// Take over or hand off PAs when autoscaler replicas come and go.
+	c.shards.Watch(func() {
+		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
+	})
 	configStore.WatchConfigs(cmw)
 	c.ConfigStore = configStore
 
@@ -19,6 +19,7 @@ package hpa
 import (
 	"context"
 	"fmt"
+	"time"
 
 	perrors "github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -27,6 +28,8 @@ import (
 	"github.com/knative/pkg/logging"
 	"github.com/knative/serving/pkg/apis/autoscaling"
 	pav1alpha1 "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+	nv1alpha1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
 	areconciler "github.com/knative/serving/pkg/reconciler/autoscaling"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/config"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/hpa/resources"
@@ -43,6 +46,10 @@ import (
 type Reconciler struct {
 	*areconciler.Base
 	hpaLister autoscalingv2beta1listers.HorizontalPodAutoscalerLister
+	// shards decides which PAs this autoscaler replica acts on.
+	shards *sharding.Shards
+	// enqueueAfter requeues the PA for when its scale schedule changes.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 var _ controller.Reconciler = (*Reconciler)(nil)
@@ -58,6 +65,12 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 	ctx = c.ConfigStore.ToContext(ctx)
 	logger.Debug("Reconcile hpa-class PodAutoscaler")
 
+	// The metric key of a PA is the same as its reconciler key.
+	if !c.shards.IsOwner(key) {
+		logger.Debugf("PA is owned by autoscaler replica %s", c.shards.Owner(key))
+		return c.Metrics.Delete(ctx, namespace, name)
+	}
+
 	original, err := c.PALister.PodAutoscalers(namespace).Get(name)
 	if errors.IsNotFound(err) {
 		logger.Debug("PA no longer exists")
@@ -115,6 +128,9 @@ func (c *Reconciler) reconcile(ctx context.Context, key string, pa *pav1alpha1.P
 	// HPA-class PAs don't yet support scale-to-zero
 	pa.Status.MarkActive()
 
+	// The HPA bounds follow the scale schedule, so requeue for when it changes.
+	c.reconcileScaleSchedule(pa, time.Now())
+
 	// HPA-class PA delegates autoscaling to the Kubernetes Horizontal Pod Autoscaler.
 	desiredHpa := resources.MakeHPA(pa, config.FromContext(ctx).Autoscaler)
 	hpa, err := c.hpaLister.HorizontalPodAutoscalers(pa.Namespace).Get(desiredHpa.Name)
@@ -153,7 +169,12 @@ func (c *Reconciler) reconcile(ctx context.Context, key string, pa *pav1alpha1.P
 		}
 	}
 
-	sks, err := c.ReconcileSKS(ctx, pa)
+	mode := nv1alpha1.SKSOperationModeServe
+	if pa.Status.IsInactive() {
+		mode = nv1alpha1.SKSOperationModeProxy
+	}
+	// The revision is assigned to all the activators.
+	sks, err := c.ReconcileSKS(ctx, pa, mode, 0)
 	if err != nil {
 		return perrors.Wrap(err, "error reconciling SKS")
 	}
@@ -168,3 +189,11 @@ func (c *Reconciler) reconcile(ctx context.Context, key string, pa *pav1alpha1.P
 	pa.Status.ObservedGeneration = pa.Generation
 	return nil
 }
+
+// reconcileScaleSchedule reports the active entries of the scale schedule in
+// the PA status and requeues the PA for when the scale bounds change next.
+func (c *Reconciler) reconcileScaleSchedule(pa *pav1alpha1.PodAutoscaler, now time.Time) {
+	if after, ok := c.ReconcileScaleSchedule(pa, now); ok {
+		c.enqueueAfter(pa, after)
+	}
+}
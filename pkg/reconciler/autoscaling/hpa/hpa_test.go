@@ -19,6 +19,7 @@ package hpa
 import (
 	"context"
 	"testing"
+	"time"
 
 	// Inject our fake informers
 	fakekubeclient "github.com/knative/pkg/injection/clients/kubeclient/fake"
@@ -38,6 +39,7 @@ import (
 	"github.com/knative/serving/pkg/apis/networking"
 	nv1a1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
 	"github.com/knative/serving/pkg/reconciler"
 	areconciler "github.com/knative/serving/pkg/reconciler/autoscaling"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/config"
@@ -59,8 +61,9 @@ import (
 )
 
 const (
-	testNamespace = "test-namespace"
-	testRevision  = "test-revision"
+	testNamespace   = "test-namespace"
+	testRevision    = "test-revision"
+	alwaysMinScale2 = "minScale=2 on daily 00:00-24:00"
 )
 
 func TestControllerCanReconcile(t *testing.T) {
@@ -92,6 +95,48 @@ func TestControllerCanReconcile(t *testing.T) {
 	}
 }
 
+func TestShardOwnership(t *testing.T) {
+	defer logtesting.ClearAll()
+	ctx, _ := SetupFakeContext(t)
+
+	// Another replica owns all keys.
+	shards := sharding.New("self")
+	shards.Update([]string{"other"})
+	ctx = sharding.WithShards(ctx, shards)
+
+	metrics := newTestMetrics()
+	ctl := NewController(ctx, configmap.NewStaticWatcher(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      autoscaler.ConfigName,
+		},
+		Data: map[string]string{},
+	}), metrics, presources.NewPodScalableInformerFactory(ctx))
+
+	podAutoscaler := pa(testRevision, testNamespace, WithHPAClass)
+	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(podAutoscaler)
+	fakekpainformer.Get(ctx).Informer().GetIndexer().Add(podAutoscaler)
+
+	if err := ctl.Reconciler.Reconcile(context.Background(), testNamespace+"/"+testRevision); err != nil {
+		t.Errorf("Reconcile() = %v", err)
+	}
+	if got := metrics.deleteCallCount.Load(); got != 1 {
+		t.Errorf("Metrics.Delete() called %d times, want 1", got)
+	}
+	if _, err := fakekubeclient.Get(ctx).AutoscalingV2beta1().HorizontalPodAutoscalers(testNamespace).Get(testRevision, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Get() = %v, want the HPA not to be created by a non-owner", err)
+	}
+
+	// Take over once the other replica is gone.
+	shards.Update([]string{"self"})
+	if err := ctl.Reconciler.Reconcile(context.Background(), testNamespace+"/"+testRevision); err != nil {
+		t.Errorf("Reconcile() = %v", err)
+	}
+	if _, err := fakekubeclient.Get(ctx).AutoscalingV2beta1().HorizontalPodAutoscalers(testNamespace).Get(testRevision, metav1.GetOptions{}); err != nil {
+		t.Errorf("Get() = %v, want the HPA created by the owner", err)
+	}
+}
+
 func TestReconcile(t *testing.T) {
 	const deployName = testRevision + "-deployment"
 	usualSelector := map[string]string{"a": "b"}
@@ -347,6 +392,25 @@ func TestReconcile(t *testing.T) {
 		WantUpdates: []ktesting.UpdateActionImpl{{
 			Object: hpa(testRevision, testNamespace, pa(testRevision, testNamespace, WithHPAClass, WithTargetAnnotation("1"), WithMetricAnnotation("cpu"))),
 		}},
+	}, {
+		Name: "update hpa with active scale schedule",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithHPAClass, WithTraffic,
+				WithPAStatusService(testRevision), WithScaleScheduleAnnotation(alwaysMinScale2)),
+			hpa(testRevision, testNamespace, pa(testRevision, testNamespace, WithHPAClass, WithMetricAnnotation("cpu"))),
+			deploy(testNamespace, testRevision),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+		},
+		Key: key(testRevision, testNamespace),
+		WantUpdates: []ktesting.UpdateActionImpl{{
+			Object: hpa(testRevision, testNamespace, pa(testRevision, testNamespace, WithHPAClass,
+				WithScaleScheduleAnnotation(alwaysMinScale2), WithMetricAnnotation("cpu"))),
+		}},
+		WantStatusUpdates: []ktesting.UpdateActionImpl{{
+			Object: pa(testRevision, testNamespace, WithHPAClass, WithTraffic,
+				WithPAStatusService(testRevision), WithScaleScheduleAnnotation(alwaysMinScale2),
+				WithActiveScaleSchedule(alwaysMinScale2)),
+		}},
 	}, {
 		Name: "invalid key",
 		Objects: []runtime.Object{
@@ -391,14 +455,15 @@ func TestReconcile(t *testing.T) {
 				PSInformerFactory: psFactory,
 				Metrics:           fakeMetrics,
 			},
-			hpaLister: listers.GetHorizontalPodAutoscalerLister(),
+			hpaLister:    listers.GetHorizontalPodAutoscalerLister(),
+			enqueueAfter: func(interface{}, time.Duration) {},
 		}
 	}))
 }
 
 func sks(ns, n string, so ...SKSOption) *nv1a1.ServerlessService {
 	hpa := pa(n, ns, WithHPAClass)
-	s := aresources.MakeSKS(hpa, nv1a1.SKSOperationModeServe)
+	s := aresources.MakeSKS(hpa, nv1a1.SKSOperationModeServe, 0)
 	for _, opt := range so {
 		opt(s)
 	}
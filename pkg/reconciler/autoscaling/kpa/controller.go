@@ -24,11 +24,15 @@ import (
 	serviceinformer "github.com/knative/pkg/injection/informers/kubeinformers/corev1/service"
 	kpainformer "github.com/knative/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler"
 	sksinformer "github.com/knative/serving/pkg/client/injection/informers/networking/v1alpha1/serverlessservice"
+	configurationinformer "github.com/knative/serving/pkg/client/injection/informers/serving/v1alpha1/configuration"
+	routeinformer "github.com/knative/serving/pkg/client/injection/informers/serving/v1alpha1/route"
 
 	"github.com/knative/pkg/configmap"
 	"github.com/knative/pkg/controller"
 	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
 	"github.com/knative/serving/pkg/reconciler"
 	areconciler "github.com/knative/serving/pkg/reconciler/autoscaling"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/config"
@@ -53,6 +57,8 @@ func NewController(
 	sksInformer := sksinformer.Get(ctx)
 	serviceInformer := serviceinformer.Get(ctx)
 	endpointsInformer := endpointsinformer.Get(ctx)
+	configurationInformer := configurationinformer.Get(ctx)
+	routeInformer := routeinformer.Get(ctx)
 
 	c := &Reconciler{
 		Base: &areconciler.Base{
@@ -63,11 +69,14 @@ func NewController(
 			Metrics:           metrics,
 			PSInformerFactory: psInformerFactory,
 		},
-		endpointsLister: endpointsInformer.Lister(),
-		deciders:        deciders,
+		endpointsLister:     endpointsInformer.Lister(),
+		configurationLister: configurationInformer.Lister(),
+		routeLister:         routeInformer.Lister(),
+		deciders:            deciders,
+		shards:              sharding.FromContext(ctx),
 	}
 	impl := controller.NewImpl(c, c.Logger, "KPA-Class Autoscaling")
-	c.scaler = newScaler(ctx, psInformerFactory, impl.EnqueueAfter)
+	c.scaler = newScaler(ctx, psInformerFactory, c.Recorder, impl.EnqueueAfter)
 
 	c.Logger.Info("Setting up KPA-Class event handlers")
 	// Handle PodAutoscalers missing the class annotation for backward compatibility.
@@ -91,6 +100,18 @@ func NewController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	// Reevaluate whether a Route refers to the revisions it sends traffic to,
+	// or used to.
+	enqueueTraffic := enqueueTrafficTargets(impl)
+	routeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueueTraffic,
+		UpdateFunc: func(old, new interface{}) {
+			enqueueTraffic(old)
+			enqueueTraffic(new)
+		},
+		DeleteFunc: enqueueTraffic,
+	})
+
 	// Have the Deciders enqueue the PAs whose decisions have changed.
 	deciders.Watch(impl.EnqueueKey)
 
@@ -102,8 +123,33 @@ func NewController(
 		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
 	})
 	configStore := config.NewStore(c.Logger.Named("config-store"), resync)
+
+	// Take over or hand off PAs when autoscaler replicas come and go.
+	c.shards.Watch(func() {
+		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
+	})
 	configStore.WatchConfigs(cmw)
 	c.ConfigStore = configStore
 
 	return impl
 }
+
+// enqueueTrafficTargets returns a handler enqueuing the PAs of the revisions
+// the Route sends traffic to.
+func enqueueTrafficTargets(impl *controller.Impl) func(interface{}) {
+	return func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		route, ok := obj.(*v1alpha1.Route)
+		if !ok {
+			return
+		}
+		for _, tt := range route.Status.Traffic {
+			if tt.RevisionName != "" {
+				// The PA of a revision shares its name.
+				impl.EnqueueKey(route.Namespace + "/" + tt.RevisionName)
+			}
+		}
+	}
+}
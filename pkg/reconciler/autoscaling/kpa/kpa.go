@@ -19,7 +19,9 @@ package kpa
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"math"
+	"sync"
+	"time"
 
 	perrors "github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -28,8 +30,11 @@ import (
 	"github.com/knative/pkg/logging"
 	"github.com/knative/serving/pkg/apis/autoscaling"
 	pav1alpha1 "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+	nv1alpha1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
+	servinglisters "github.com/knative/serving/pkg/client/listers/serving/v1alpha1"
 	areconciler "github.com/knative/serving/pkg/reconciler/autoscaling"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/config"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/kpa/resources"
@@ -48,8 +53,19 @@ import (
 type Reconciler struct {
 	*areconciler.Base
 	endpointsLister corev1listers.EndpointsLister
-	deciders        resources.Deciders
-	scaler          *scaler
+	// The Configuration and Route listers tell whether a Route refers to
+	// the revision of a PA.
+	configurationLister servinglisters.ConfigurationLister
+	routeLister         servinglisters.RouteLister
+	deciders            resources.Deciders
+	scaler              *scaler
+	// shards decides which PAs this autoscaler replica acts on.
+	shards *sharding.Shards
+
+	// panicMux guards panicking, the keys of the PAs whose Deciders were
+	// panicking when last reconciled.
+	panicMux  sync.Mutex
+	panicking map[string]bool
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -67,16 +83,16 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 
 	logger.Debug("Reconcile kpa-class PodAutoscaler")
 
+	// The metric key of a PA is the same as its reconciler key.
+	if !c.shards.IsOwner(key) {
+		logger.Debugf("PA is owned by autoscaler replica %s", c.shards.Owner(key))
+		return c.releasePA(ctx, namespace, name)
+	}
+
 	original, err := c.PALister.PodAutoscalers(namespace).Get(name)
 	if errors.IsNotFound(err) {
 		logger.Debug("PA no longer exists")
-		if err := c.deciders.Delete(ctx, namespace, name); err != nil {
-			return err
-		}
-		if err := c.Metrics.Delete(ctx, namespace, name); err != nil {
-			return err
-		}
-		return nil
+		return c.releasePA(ctx, namespace, name)
 	} else if err != nil {
 		return err
 	}
@@ -109,6 +125,18 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 	return reconcileErr
 }
 
+// releasePA stops deciding on and collecting metrics for the PA.
+func (c *Reconciler) releasePA(ctx context.Context, namespace, name string) error {
+	c.panicMux.Lock()
+	delete(c.panicking, autoscaler.NewMetricKey(namespace, name))
+	c.panicMux.Unlock()
+
+	if err := c.deciders.Delete(ctx, namespace, name); err != nil {
+		return err
+	}
+	return c.Metrics.Delete(ctx, namespace, name)
+}
+
 func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler) error {
 	logger := logging.FromContext(ctx)
 
@@ -130,11 +158,6 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 		return perrors.Wrap(err, "error reconciling metrics service")
 	}
 
-	sks, err := c.ReconcileSKS(ctx, pa)
-	if err != nil {
-		return perrors.Wrap(err, "error reconciling SKS")
-	}
-
 	// Since metricSvc is what is being scraped for metrics
 	// it should be the correct representation of the pods in the deployment
 	// for autoscaling decisions.
@@ -142,17 +165,45 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 	if err != nil {
 		return perrors.Wrap(err, "error reconciling decider")
 	}
+	c.reportPanic(pa, decider)
+
+	activatorCapacity := config.FromContext(ctx).Autoscaler.ActivatorCapacity
+	sks, err := c.ReconcileSKS(ctx, pa, sksMode(pa, decider), numActivators(decider, activatorCapacity))
+	if err != nil {
+		return perrors.Wrap(err, "error reconciling SKS")
+	}
+
+	pa.Status.TunedTarget = 0
+	if decider.Spec.TargetLatency > 0 {
+		pa.Status.TunedTarget = decider.Status.Explanation.TargetValue
+	}
 
 	if err := c.ReconcileMetric(ctx, pa, metricSvc); err != nil {
 		return perrors.Wrap(err, "error reconciling metric")
 	}
 
+	c.reconcileScaleSchedule(pa, time.Now())
+
+	routed, err := c.isRouted(pa)
+	if err != nil {
+		return perrors.Wrap(err, "error checking the routes of the revision")
+	}
+
 	// Get the appropriate current scale from the metric, and right size
 	// the scaleTargetRef based on it.
-	want, err := c.scaler.Scale(ctx, pa, decider.Status.DesiredScale)
+	want, err := c.scaler.Scale(ctx, pa, routed, decider.Status.DesiredScale, decider.Status.LastTrafficTime.Time)
 	if err != nil {
 		return perrors.Wrap(err, "error scaling target")
 	}
+	if pa.Frozen() {
+		message := fmt.Sprintf("The scale is frozen at %d", want)
+		if ds := decider.Status.DesiredScale; ds >= 0 {
+			message += fmt.Sprintf(", the autoscaler recommends %d", ds)
+		}
+		pa.Status.MarkAutoscalingPaused(message)
+	} else {
+		pa.Status.MarkAutoscalingResumed()
+	}
 
 	// Compare the desired and observed resources to determine our situation.
 	// We fetch private endpoints here, since for scaling we're interested in the actual
@@ -169,6 +220,11 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 	}
 	logger.Infof("PA scale got=%v, want=%v", got, want)
 
+	if !pa.Status.IsScaleTargetInitialized() &&
+		int32(got) >= config.FromContext(ctx).Autoscaler.InitialScaleFor(pa.Annotations) {
+		pa.Status.MarkScaleTargetInitialized()
+	}
+
 	err = reportMetrics(pa, want, got)
 	if err != nil {
 		return perrors.Wrap(err, "error reporting metrics")
@@ -176,8 +232,8 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 
 	// computeActiveCondition decides if we need to change the SKS mode,
 	// and returns true if the status has changed.
-	if changed := computeActiveCondition(pa, want, got); changed {
-		_, err := c.ReconcileSKS(ctx, pa)
+	if changed := computeActiveCondition(pa, routed, want, got); changed {
+		_, err := c.ReconcileSKS(ctx, pa, sksMode(pa, decider), numActivators(decider, activatorCapacity))
 		if err != nil {
 			return perrors.Wrap(err, "error re-reconciling SKS")
 		}
@@ -209,6 +265,80 @@ func (c *Reconciler) reconcileDecider(ctx context.Context, pa *pav1alpha1.PodAut
 	return decider, nil
 }
 
+// isRouted returns whether a Route refers to the revision of the PA. The
+// labeler marks the Configuration of every revision a Route refers to with
+// the Route's label, so only that Route's traffic needs checking. PAs not
+// made for a revision of a Configuration count as routed.
+func (c *Reconciler) isRouted(pa *pav1alpha1.PodAutoscaler) (bool, error) {
+	revName, configName := pa.Labels[serving.RevisionLabelKey], pa.Labels[serving.ConfigurationLabelKey]
+	if revName == "" || configName == "" {
+		return true, nil
+	}
+
+	cfg, err := c.configurationLister.Configurations(pa.Namespace).Get(configName)
+	if errors.IsNotFound(err) {
+		// The revision goes away along with its Configuration.
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	routeName, ok := cfg.Labels[serving.RouteLabelKey]
+	if !ok {
+		return false, nil
+	}
+
+	route, err := c.routeLister.Routes(pa.Namespace).Get(routeName)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, tt := range route.Status.Traffic {
+		if tt.RevisionName == revName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcileScaleSchedule reports the active entries of the scale schedule in
+// the PA status and requeues the PA for when the scale bounds change next.
+func (c *Reconciler) reconcileScaleSchedule(pa *pav1alpha1.PodAutoscaler, now time.Time) {
+	if after, ok := c.ReconcileScaleSchedule(pa, now); ok {
+		c.scaler.enqueueCB(pa, after)
+	}
+}
+
+// reportPanic emits an Event when the Decider of the PA starts or stops
+// panicking.
+func (c *Reconciler) reportPanic(pa *pav1alpha1.PodAutoscaler, decider *autoscaler.Decider) {
+	key := autoscaler.NewMetricKey(pa.Namespace, pa.Name)
+	explanation := decider.Status.Explanation
+
+	c.panicMux.Lock()
+	wasPanicking := c.panicking[key]
+	if explanation.Panicking {
+		if c.panicking == nil {
+			c.panicking = make(map[string]bool)
+		}
+		c.panicking[key] = true
+	} else {
+		delete(c.panicking, key)
+	}
+	c.panicMux.Unlock()
+
+	switch {
+	case explanation.Panicking && !wasPanicking:
+		c.Recorder.Eventf(pa, corev1.EventTypeNormal, "PanicStarted",
+			"Entered panic mode: observed %s of %0.3f on %d ready pods, targeting %v per pod",
+			decider.Spec.ScalingMetric, explanation.ObservedPanicValue, explanation.ReadyPods, decider.Spec.TargetValue)
+	case !explanation.Panicking && wasPanicking:
+		c.Recorder.Eventf(pa, corev1.EventTypeNormal, "PanicEnded",
+			"Left panic mode: observed stable %s of %0.3f on %d ready pods, targeting %v per pod",
+			decider.Spec.ScalingMetric, explanation.ObservedStableValue, explanation.ReadyPods, decider.Spec.TargetValue)
+	}
+}
+
 func reportMetrics(pa *pav1alpha1.PodAutoscaler, want int32, got int) error {
 	var serviceLabel string
 	var configLabel string
@@ -231,8 +361,8 @@ func reportMetrics(pa *pav1alpha1.PodAutoscaler, want int32, got int) error {
 
 // computeActiveCondition updates the status of PA, depending on scales desired and present.
 // computeActiveCondition returns true if it thinks SKS needs an update.
-func computeActiveCondition(pa *pav1alpha1.PodAutoscaler, want int32, got int) (ret bool) {
-	minReady := activeThreshold(pa)
+func computeActiveCondition(pa *pav1alpha1.PodAutoscaler, routed bool, want int32, got int) (ret bool) {
+	minReady := activeThreshold(pa, routed)
 
 	switch {
 	case want == 0:
@@ -256,15 +386,47 @@ func computeActiveCondition(pa *pav1alpha1.PodAutoscaler, want int32, got int) (
 	return
 }
 
-// activeThreshold returns the scale required for the kpa to be marked Active
-func activeThreshold(pa *pav1alpha1.PodAutoscaler) int {
-	if min, ok := pa.Annotations[autoscaling.MinScaleAnnotationKey]; ok {
-		if ms, err := strconv.Atoi(min); err == nil {
-			if ms > 1 {
-				return ms
-			}
-		}
+// sksMode returns the mode the SKS of the PA should be in. The activator
+// stays in the request path while the PA is inactive, and to buffer bursts
+// while the spare capacity is below the target burst capacity.
+func sksMode(pa *pav1alpha1.PodAutoscaler, decider *autoscaler.Decider) nv1alpha1.ServerlessServiceOperationMode {
+	if pa.Status.IsInactive() || decider.Status.ExcessBurstCapacity < 0 {
+		return nv1alpha1.SKSOperationModeProxy
 	}
+	return nv1alpha1.SKSOperationModeServe
+}
 
+// minActivators is the least number of activators a revision is assigned to,
+// so that it doesn't depend on a single activator.
+const minActivators = 2
+
+// numActivators returns the number of activators the revision of the PA is
+// assigned to: enough of them to cover the target burst capacity, at least
+// minActivators, and all of them, 0, if the activator is always in the
+// request path.
+func numActivators(decider *autoscaler.Decider, activatorCapacity float64) int32 {
+	tbc := decider.Spec.TargetBurstCapacity
+	if tbc < 0 {
+		return 0
+	}
+	return int32(math.Max(minActivators, math.Ceil(tbc/activatorCapacity)))
+}
+
+// activeThreshold returns the scale required for the kpa to be marked Active
+func activeThreshold(pa *pav1alpha1.PodAutoscaler, routed bool) int {
+	if min, _ := scaleBounds(pa, routed); min > 1 {
+		return int(min)
+	}
 	return 1
 }
+
+// scaleBounds returns the scale bounds of the PA. The minimum scale is
+// released when no Route refers to the revision of the PA, unless the PA
+// asks to keep it.
+func scaleBounds(pa *pav1alpha1.PodAutoscaler, routed bool) (min, max int32) {
+	min, max = pa.ScaleBounds()
+	if !routed && !pa.KeepMinScale() {
+		min = 0
+	}
+	return min, max
+}
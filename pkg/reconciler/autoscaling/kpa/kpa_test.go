@@ -33,7 +33,9 @@ import (
 	fakeservingclient "github.com/knative/serving/pkg/client/injection/client/fake"
 	fakekpainformer "github.com/knative/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler/fake"
 	fakesksinformer "github.com/knative/serving/pkg/client/injection/informers/networking/v1alpha1/serverlessservice/fake"
+	_ "github.com/knative/serving/pkg/client/injection/informers/serving/v1alpha1/configuration/fake"
 	fakerevisioninformer "github.com/knative/serving/pkg/client/injection/informers/serving/v1alpha1/revision/fake"
+	_ "github.com/knative/serving/pkg/client/injection/informers/serving/v1alpha1/route/fake"
 	"github.com/knative/serving/pkg/reconciler"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -54,7 +56,9 @@ import (
 	nv1a1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/apis/serving/v1beta1"
 	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/autoscaler/sharding"
 	rpkg "github.com/knative/serving/pkg/reconciler"
 	areconciler "github.com/knative/serving/pkg/reconciler/autoscaling"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/config"
@@ -131,7 +135,7 @@ func metricsSvc(ns, n string, opts ...K8sServiceOption) *corev1.Service {
 
 func sks(ns, n string, so ...SKSOption) *nv1a1.ServerlessService {
 	kpa := kpa(ns, n)
-	s := aresources.MakeSKS(kpa, nv1a1.SKSOperationModeServe)
+	s := aresources.MakeSKS(kpa, nv1a1.SKSOperationModeServe, minActivators)
 	for _, opt := range so {
 		opt(s)
 	}
@@ -156,6 +160,10 @@ func markActive(pa *asv1a1.PodAutoscaler) {
 	pa.Status.MarkActive()
 }
 
+func markScaleTargetInitialized(pa *asv1a1.PodAutoscaler) {
+	pa.Status.MarkScaleTargetInitialized()
+}
+
 func withMSvcStatus(s string) PodAutoscalerOption {
 	return func(pa *asv1a1.PodAutoscaler) {
 		pa.Status.MetricsServiceName = s
@@ -225,11 +233,14 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 			Name:  deployName,
 			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":0}]`),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "ScaledToZero", "Scaled from 1 to zero, the target is not receiving traffic"),
+		},
 	}, {
 		Name: "from serving to proxy",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, markOld,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, markOld,
 				WithPAStatusService(testRevision),
 				withMSvcStatus("and-into-the-black")),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
@@ -239,7 +250,7 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision,
+			Object: kpa(testNamespace, testRevision, markScaleTargetInitialized,
 				WithNoTraffic("NoTraffic", "The target is not receiving traffic."),
 				WithPAStatusService(testRevision), withMSvcStatus("and-into-the-black")),
 		}},
@@ -251,7 +262,7 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 		Name: "from serving to proxy, sks update fail :-(",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, markOld,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, markOld,
 				WithPAStatusService(testRevision), withMSvcStatus("you-ask-for-this")),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
@@ -268,7 +279,7 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 				"error re-reconciling SKS: error updating SKS test-revision: inducing failure for update serverlessservices"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision,
+			Object: kpa(testNamespace, testRevision, markScaleTargetInitialized,
 				WithNoTraffic("NoTraffic", "The target is not receiving traffic."),
 				WithPAStatusService(testRevision), withMSvcStatus("you-ask-for-this")),
 		}},
@@ -280,7 +291,7 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 		Name: "scaling to 0, but not stable for long enough, so no-op",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision), withMSvcStatus("but-we-give-you-that")),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
@@ -302,7 +313,7 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 
 		fakeMetrics := newTestMetrics()
 		psFactory := presources.NewPodScalableInformerFactory(ctx)
-		scaler := newScaler(ctx, psFactory, func(interface{}, time.Duration) {})
+		scaler := newScaler(ctx, psFactory, controller.GetEventRecorder(ctx), func(interface{}, time.Duration) {})
 		scaler.activatorProbe = func(*asv1a1.PodAutoscaler, http.RoundTripper) (bool, error) { return true, nil }
 		return &Reconciler{
 			Base: &areconciler.Base{
@@ -314,9 +325,11 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 				ConfigStore:       &testConfigStore{config: defaultConfig()},
 				PSInformerFactory: psFactory,
 			},
-			endpointsLister: listers.GetEndpointsLister(),
-			deciders:        fakeDeciders,
-			scaler:          scaler,
+			endpointsLister:     listers.GetEndpointsLister(),
+			deciders:            fakeDeciders,
+			scaler:              scaler,
+			configurationLister: listers.GetConfigurationLister(),
+			routeLister:         listers.GetRouteLister(),
 		}
 	}))
 }
@@ -356,7 +369,7 @@ func TestReconcile(t *testing.T) {
 		Name: "steady state",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a330-200"),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withMSvcStatus("a330-200"),
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
@@ -368,7 +381,7 @@ func TestReconcile(t *testing.T) {
 		Name: "metric-service-mistmatch",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a350-900ULR"),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withMSvcStatus("a350-900ULR"),
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
@@ -389,7 +402,7 @@ func TestReconcile(t *testing.T) {
 			Name: "b777-200LR",
 		}},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive,
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision), withMSvcStatus(testRevision+"-00001")),
 		}},
 		WantCreates: []runtime.Object{
@@ -399,7 +412,7 @@ func TestReconcile(t *testing.T) {
 		Name: "metric-service-exists-not-on-status",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, WithPAStatusService(testRevision)),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("erj-e190")),
@@ -407,14 +420,14 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive,
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision), withMSvcStatus("erj-e190")),
 		}},
 	}, {
 		Name: "delete redundant metrics svc",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a380-800"),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withMSvcStatus("a380-800"),
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
@@ -444,14 +457,14 @@ func TestReconcile(t *testing.T) {
 		Name: "make metrics service",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			expectedDeploy,
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive,
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision), withMSvcStatus(testRevision+"-00001")),
 		}},
 		WantCreates: []runtime.Object{
@@ -465,7 +478,7 @@ func TestReconcile(t *testing.T) {
 		},
 		WantErr: true,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			expectedDeploy,
@@ -482,7 +495,7 @@ func TestReconcile(t *testing.T) {
 		Name: "scale up deployment",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
@@ -496,6 +509,66 @@ func TestReconcile(t *testing.T) {
 			Name:  deployName,
 			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":11}]`),
 		}},
+	}, {
+		Name: "scale up deployment to the initial scale",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, markActivating, withInitialScale(20),
+				WithPAStatusService(testRevision)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
+			deploy(testNamespace, testRevision),
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			// Active, but not initialized until all 20 pods are ready.
+			Object: kpa(testNamespace, testRevision, markActive, withInitialScale(20),
+				WithPAStatusService(testRevision)),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNamespace,
+			},
+			Name:  deployName,
+			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":20}]`),
+		}},
+	}, {
+		Name: "frozen does not scale up deployment",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withFreeze,
+				WithPAStatusService(testRevision)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
+			deploy(testNamespace, testRevision),
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withFreeze,
+				WithPAStatusService(testRevision),
+				withAutoscalingPaused("The scale is frozen at 1, the autoscaler recommends 11")),
+		}},
+	}, {
+		Name: "unfrozen scales up deployment",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, WithPAStatusService(testRevision),
+				withAutoscalingPaused("The scale is frozen at 1, the autoscaler recommends 11")),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
+			deploy(testNamespace, testRevision),
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNamespace,
+			},
+			Name:  deployName,
+			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":11}]`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, WithPAStatusService(testRevision)),
+		}},
 	}, {
 		Name: "scale up deployment failure",
 		Key:  key,
@@ -504,7 +577,7 @@ func TestReconcile(t *testing.T) {
 		},
 		WantErr: true,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
@@ -526,7 +599,7 @@ func TestReconcile(t *testing.T) {
 		Name: "update metrics service",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a321neo"),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withMSvcStatus("a321neo"),
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			expectedDeploy,
@@ -545,7 +618,7 @@ func TestReconcile(t *testing.T) {
 		},
 		WantErr: true,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive, withMSvcStatus("b767-300er"),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withMSvcStatus("b767-300er"),
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			expectedDeploy,
@@ -565,7 +638,7 @@ func TestReconcile(t *testing.T) {
 		Key:     key,
 		WantErr: true,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision), withMSvcStatus("b737max-800")),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector), func(s *corev1.Service) {
@@ -575,7 +648,7 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive, WithPAStatusService(testRevision),
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, WithPAStatusService(testRevision),
 				withMSvcStatus("b737max-800"),
 				// We expect this change in status:
 				markResourceNotOwned("Service", "b737max-800")),
@@ -587,7 +660,7 @@ func TestReconcile(t *testing.T) {
 		Name: "can't read endpoints",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive,
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized,
 				WithPAStatusService(testRevision)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
@@ -643,7 +716,7 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive, WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, WithPAStatusService(testRevision)),
 		}},
 	}, {
 		Name: "kpa does not become ready without minScale endpoints",
@@ -669,20 +742,66 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(2, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive, withMinScale(2), WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized, withMinScale(2), WithPAStatusService(testRevision)),
+		}},
+	}, {
+		Name: "routed kpa does not become ready without minScale endpoints",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, withMinScale(2), withConfigurationLabels),
+			configuration(testNamespace, testConfiguration, testRoute),
+			route(testNamespace, testRoute, testRevision),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
+			expectedDeploy,
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, markActivating, withMinScale(2), withConfigurationLabels,
+				WithPAStatusService(testRevision)),
+		}},
+	}, {
+		Name: "unrouted kpa becomes ready without minScale endpoints",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, withMinScale(2), withConfigurationLabels),
+			configuration(testNamespace, testConfiguration, testRoute),
+			route(testNamespace, testRoute, "another-revision"),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
+			expectedDeploy,
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, markActive, withMinScale(2), withConfigurationLabels,
+				WithPAStatusService(testRevision)),
+		}},
+	}, {
+		Name: "kpa does not become ready without scheduled minScale endpoints",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, WithScaleScheduleAnnotation(alwaysMinScale2)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
+			expectedDeploy,
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, markActivating, markScaleTargetInitialized, WithScaleScheduleAnnotation(alwaysMinScale2),
+				WithActiveScaleSchedule(alwaysMinScale2), WithPAStatusService(testRevision)),
 		}},
 	}, {
 		Name: "sks does not exist",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
 			expectedDeploy,
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			// SKS does not exist, so we're just creating and have no status.
-			Object: kpa(testNamespace, testRevision, markActivating),
+			Object: kpa(testNamespace, testRevision, markActivating, markScaleTargetInitialized),
 		}},
 		WantCreates: []runtime.Object{
 			sks(testNamespace, testRevision, WithDeployRef(deployName)),
@@ -691,7 +810,7 @@ func TestReconcile(t *testing.T) {
 		Name: "sks is out of whack",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized),
 			sks(testNamespace, testRevision, WithDeployRef("bar"),
 				WithPubService),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
@@ -699,7 +818,8 @@ func TestReconcile(t *testing.T) {
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			// SKS just got updated and we don't have up to date status.
-			Object: kpa(testNamespace, testRevision, markActivating, WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActivating, markScaleTargetInitialized,
+				WithPAStatusService(testRevision)),
 		}},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: sks(testNamespace, testRevision, WithPubService,
@@ -709,7 +829,7 @@ func TestReconcile(t *testing.T) {
 		Name: "sks cannot be created",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
 			expectedDeploy,
 		},
@@ -728,7 +848,7 @@ func TestReconcile(t *testing.T) {
 		Name: "sks cannot be updated",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized),
 			sks(testNamespace, testRevision, WithDeployRef("bar")),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
 			expectedDeploy,
@@ -747,7 +867,7 @@ func TestReconcile(t *testing.T) {
 		Name: "sks is disowned",
 		Key:  key,
 		Objects: []runtime.Object{
-			kpa(testNamespace, testRevision, markActive),
+			kpa(testNamespace, testRevision, markActive, markScaleTargetInitialized),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady,
 				WithSKSOwnersRemoved),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
@@ -755,7 +875,8 @@ func TestReconcile(t *testing.T) {
 		},
 		WantErr: true,
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markResourceNotOwned("ServerlessService", testRevision)),
+			Object: kpa(testNamespace, testRevision, markScaleTargetInitialized,
+				markResourceNotOwned("ServerlessService", testRevision)),
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeWarning, "InternalError", "error reconciling SKS: PA: test-revision does not own SKS: test-revision"),
@@ -786,9 +907,11 @@ func TestReconcile(t *testing.T) {
 				ConfigStore:       &testConfigStore{config: defaultConfig()},
 				PSInformerFactory: psFactory,
 			},
-			endpointsLister: listers.GetEndpointsLister(),
-			deciders:        fakeDeciders,
-			scaler:          newScaler(ctx, psFactory, func(interface{}, time.Duration) {}),
+			endpointsLister:     listers.GetEndpointsLister(),
+			deciders:            fakeDeciders,
+			scaler:              newScaler(ctx, psFactory, controller.GetEventRecorder(ctx), func(interface{}, time.Duration) {}),
+			configurationLister: listers.GetConfigurationLister(),
+			routeLister:         listers.GetRouteLister(),
 		}
 	}))
 }
@@ -864,8 +987,8 @@ func TestGlobalResyncOnUpdateAutoscalerConfigMap(t *testing.T) {
 	// Wait for decider to be created.
 	if decider, err := pollDeciders(fakeDeciders, testNamespace, testRevision, nil); err != nil {
 		t.Fatalf("Failed to get decider: %v", err)
-	} else if got, want := decider.Spec.TargetConcurrency, defaultConcurrencyTarget; got != want {
-		t.Fatalf("TargetConcurrency = %v, want %v", got, want)
+	} else if got, want := decider.Spec.TargetValue, defaultConcurrencyTarget; got != want {
+		t.Fatalf("TargetValue = %v, want %v", got, want)
 	}
 
 	concurrencyTargetAfterUpdate := 100.0
@@ -881,12 +1004,12 @@ func TestGlobalResyncOnUpdateAutoscalerConfigMap(t *testing.T) {
 
 	// Wait for decider to be updated with the new values from the configMap.
 	cond := func(d *autoscaler.Decider) bool {
-		return d.Spec.TargetConcurrency == concurrencyTargetAfterUpdate
+		return d.Spec.TargetValue == concurrencyTargetAfterUpdate
 	}
 	if decider, err := pollDeciders(fakeDeciders, testNamespace, testRevision, cond); err != nil {
 		t.Fatalf("Failed to get decider: %v", err)
-	} else if got, want := decider.Spec.TargetConcurrency, concurrencyTargetAfterUpdate; got != want {
-		t.Fatalf("TargetConcurrency = %v, want %v", got, want)
+	} else if got, want := decider.Spec.TargetValue, concurrencyTargetAfterUpdate; got != want {
+		t.Fatalf("TargetValue = %v, want %v", got, want)
 	}
 }
 
@@ -1073,6 +1196,241 @@ func TestNonKPAClass(t *testing.T) {
 	}
 }
 
+func TestShardOwnership(t *testing.T) {
+	defer logtesting.ClearAll()
+	ctx, _ := SetupFakeContext(t)
+
+	// Another replica owns all keys.
+	shards := sharding.New("self")
+	shards.Update([]string{"other"})
+	ctx = sharding.WithShards(ctx, shards)
+
+	fakeDeciders := newTestDeciders()
+	fakeMetrics := newTestMetrics()
+	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders, fakeMetrics, presources.NewPodScalableInformerFactory(ctx))
+
+	rev := newTestRevision(testNamespace, testRevision)
+	fakeservingclient.Get(ctx).ServingV1alpha1().Revisions(testNamespace).Create(rev)
+	fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+	kpa := revisionresources.MakeKPA(rev)
+	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(kpa)
+	fakekpainformer.Get(ctx).Informer().GetIndexer().Add(kpa)
+
+	newDeployment(t, fakedynamicclient.Get(ctx), testRevision+"-deployment", 3)
+
+	if err := ctl.Reconciler.Reconcile(context.Background(), testNamespace+"/"+testRevision); err != nil {
+		t.Errorf("Reconcile() = %v", err)
+	}
+	if got := fakeDeciders.createCallCount.Load(); got != 0 {
+		t.Errorf("Deciders.Create() called %d times, want 0", got)
+	}
+	if got := fakeDeciders.deleteCallCount.Load(); got != 1 {
+		t.Errorf("Deciders.Delete() called %d times, want 1", got)
+	}
+	if got := fakeMetrics.deleteCallCount.Load(); got != 1 {
+		t.Errorf("Metrics.Delete() called %d times, want 1", got)
+	}
+
+	// Take over once the other replica is gone.
+	shards.Update([]string{"self"})
+	if err := ctl.Reconciler.Reconcile(context.Background(), testNamespace+"/"+testRevision); err != nil {
+		t.Errorf("Reconcile() = %v", err)
+	}
+	if got := fakeDeciders.createCallCount.Load(); got != 1 {
+		t.Errorf("Deciders.Create() called %d times, want 1", got)
+	}
+	if got := fakeMetrics.createCallCount.Load(); got != 1 {
+		t.Errorf("Metrics.Create() called %d times, want 1", got)
+	}
+}
+
+func TestReportPanic(t *testing.T) {
+	defer logtesting.ClearAll()
+	ctx, _ := SetupFakeContext(t)
+	c := &Reconciler{
+		Base: &areconciler.Base{
+			Base:    rpkg.NewBase(ctx, controllerAgentName, newConfigWatcher()),
+			Metrics: newTestMetrics(),
+		},
+		deciders: newTestDeciders(),
+	}
+	pa := kpa(testNamespace, testRevision)
+	decider := resources.MakeDecider(ctx, pa, defaultConfig().Autoscaler, "not-important-here")
+	decider.Status.Explanation = autoscaler.Explanation{
+		ReadyPods:           2,
+		ObservedStableValue: 10,
+		ObservedPanicValue:  50,
+	}
+
+	tests := []struct {
+		name      string
+		panicking bool
+		release   bool
+		want      []string
+	}{{
+		name: "not panicking",
+	}, {
+		name:      "starts panicking",
+		panicking: true,
+		want: []string{
+			"Normal PanicStarted Entered panic mode: observed concurrency of 50.000 on 2 ready pods, targeting 10 per pod",
+		},
+	}, {
+		name:      "keeps panicking",
+		panicking: true,
+	}, {
+		name: "stops panicking",
+		want: []string{
+			"Normal PanicEnded Left panic mode: observed stable concurrency of 10.000 on 2 ready pods, targeting 10 per pod",
+		},
+	}, {
+		name:      "panicking again",
+		panicking: true,
+		release:   true,
+		want: []string{
+			"Normal PanicStarted Entered panic mode: observed concurrency of 50.000 on 2 ready pods, targeting 10 per pod",
+		},
+	}, {
+		name:      "panicking after release",
+		panicking: true,
+		want: []string{
+			"Normal PanicStarted Entered panic mode: observed concurrency of 50.000 on 2 ready pods, targeting 10 per pod",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decider.Status.Explanation.Panicking = test.panicking
+			c.reportPanic(pa, decider)
+			if got := recordedEvents(ctx); !cmp.Equal(got, test.want) {
+				t.Errorf("Events = %v, want: %v", got, test.want)
+			}
+			if test.release {
+				// A released PA starts over.
+				if err := c.releasePA(ctx, pa.Namespace, pa.Name); err != nil {
+					t.Errorf("releasePA() = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSKSMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		pa     *asv1a1.PodAutoscaler
+		excess float64
+		want   nv1a1.ServerlessServiceOperationMode
+	}{{
+		name: "active",
+		pa:   kpa(testNamespace, testRevision, markActive),
+		want: nv1a1.SKSOperationModeServe,
+	}, {
+		name: "inactive",
+		pa:   kpa(testNamespace, testRevision, WithNoTraffic("NoTraffic", "The target is not receiving traffic.")),
+		want: nv1a1.SKSOperationModeProxy,
+	}, {
+		name:   "active with spare capacity",
+		pa:     kpa(testNamespace, testRevision, markActive),
+		excess: 5,
+		want:   nv1a1.SKSOperationModeServe,
+	}, {
+		name:   "active without enough spare capacity",
+		pa:     kpa(testNamespace, testRevision, markActive),
+		excess: -5,
+		want:   nv1a1.SKSOperationModeProxy,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decider := &autoscaler.Decider{}
+			decider.Status.ExcessBurstCapacity = test.excess
+			if got := sksMode(test.pa, decider); got != test.want {
+				t.Errorf("sksMode() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNumActivators(t *testing.T) {
+	tests := []struct {
+		name string
+		tbc  float64
+		want int32
+	}{{
+		name: "no target burst capacity",
+		want: minActivators,
+	}, {
+		name: "always in the request path",
+		tbc:  -1,
+		want: 0,
+	}, {
+		name: "target burst capacity covered by the minimum",
+		tbc:  150,
+		want: minActivators,
+	}, {
+		name: "target burst capacity needing more activators",
+		tbc:  250,
+		want: 3,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decider := &autoscaler.Decider{}
+			decider.Spec.TargetBurstCapacity = test.tbc
+			if got := numActivators(decider, 100); got != test.want {
+				t.Errorf("numActivators() = %d, want: %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReconcileScaleSchedule(t *testing.T) {
+	now := time.Date(2019, time.June, 3, 12, 0, 0, 0, time.UTC) // Monday
+	tests := []struct {
+		name        string
+		schedule    string
+		wantActive  []string
+		wantEnqueue time.Duration
+	}{{
+		name: "no schedule",
+	}, {
+		name:        "active entry",
+		schedule:    "minScale=2 on weekdays 08:00-18:00",
+		wantActive:  []string{"minScale=2 on weekdays 08:00-18:00"},
+		wantEnqueue: 6 * time.Hour,
+	}, {
+		name:        "inactive entry",
+		schedule:    "minScale=2 on weekdays 18:00-20:00;maxScale=1 on sat 00:00-24:00",
+		wantEnqueue: 6 * time.Hour,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var enqueued time.Duration
+			c := &Reconciler{
+				scaler: &scaler{
+					enqueueCB: func(_ interface{}, d time.Duration) {
+						enqueued = d
+					},
+				},
+			}
+			pa := kpa(testNamespace, testRevision, WithActiveScaleSchedule("stale"))
+			if test.schedule != "" {
+				WithScaleScheduleAnnotation(test.schedule)(pa)
+			}
+
+			c.reconcileScaleSchedule(pa, now)
+			if got := pa.Status.ActiveScaleSchedule; !cmp.Equal(got, test.wantActive) {
+				t.Errorf("ActiveScaleSchedule = %v, want: %v", got, test.wantActive)
+			}
+			if enqueued != test.wantEnqueue {
+				t.Errorf("Enqueued after %v, want: %v", enqueued, test.wantEnqueue)
+			}
+		})
+	}
+}
+
 func TestNoEndpoints(t *testing.T) {
 	defer logtesting.ClearAll()
 	ctx, _ := SetupFakeContext(t)
@@ -1424,6 +1782,40 @@ func addEndpoint(ep *corev1.Endpoints) *corev1.Endpoints {
 	return ep
 }
 
+func withConfigurationLabels(pa *asv1a1.PodAutoscaler) {
+	pa.Labels = presources.UnionMaps(pa.Labels, map[string]string{
+		serving.RevisionLabelKey:      testRevision,
+		serving.ConfigurationLabelKey: testConfiguration,
+	})
+}
+
+// configuration returns a Configuration labeled with the Route it belongs to.
+func configuration(ns, n, routeName string) *v1alpha1.Configuration {
+	return &v1alpha1.Configuration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      n,
+			Labels:    map[string]string{serving.RouteLabelKey: routeName},
+		},
+	}
+}
+
+// route returns a Route whose status sends traffic to the given revisions.
+func route(ns, n string, revisions ...string) *v1alpha1.Route {
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      n,
+		},
+	}
+	for _, rev := range revisions {
+		r.Status.Traffic = append(r.Status.Traffic, v1alpha1.TrafficTarget{
+			TrafficTarget: v1beta1.TrafficTarget{RevisionName: rev},
+		})
+	}
+	return r
+}
+
 func withMinScale(minScale int) PodAutoscalerOption {
 	return func(pa *asv1a1.PodAutoscaler) {
 		pa.Annotations = presources.UnionMaps(
@@ -1433,6 +1825,30 @@ func withMinScale(minScale int) PodAutoscalerOption {
 	}
 }
 
+const alwaysMinScale2 = "minScale=2 on daily 00:00-24:00"
+
+func withInitialScale(initialScale int) PodAutoscalerOption {
+	return func(pa *asv1a1.PodAutoscaler) {
+		pa.Annotations = presources.UnionMaps(
+			pa.Annotations,
+			map[string]string{autoscaling.InitialScaleAnnotationKey: strconv.Itoa(initialScale)},
+		)
+	}
+}
+
+func withFreeze(pa *asv1a1.PodAutoscaler) {
+	pa.Annotations = presources.UnionMaps(
+		pa.Annotations,
+		map[string]string{autoscaling.FreezeAnnotationKey: "true"},
+	)
+}
+
+func withAutoscalingPaused(message string) PodAutoscalerOption {
+	return func(pa *asv1a1.PodAutoscaler) {
+		pa.Status.MarkAutoscalingPaused(message)
+	}
+}
+
 type testConfigStore struct {
 	config *config.Config
 }
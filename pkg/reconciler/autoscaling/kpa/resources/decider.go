@@ -18,7 +18,9 @@ package resources
 
 import (
 	"context"
+	"math"
 
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
 	"github.com/knative/serving/pkg/reconciler/autoscaling/resources"
@@ -43,8 +45,8 @@ type Deciders interface {
 }
 
 // MakeDecider constructs a Decider resource from a PodAutoscaler taking
-// into account the PA's ContainerConcurrency and the relevant
-// autoscaling annotation.
+// into account the PA's ContainerConcurrency, scaling metric and the
+// relevant autoscaling annotations.
 func MakeDecider(ctx context.Context, pa *v1alpha1.PodAutoscaler, config *autoscaler.Config, svc string) *autoscaler.Decider {
 	panicThresholdPercentage, ok := pa.PanicThresholdPercentage()
 	if !ok {
@@ -56,18 +58,55 @@ func MakeDecider(ctx context.Context, pa *v1alpha1.PodAutoscaler, config *autosc
 		stableWindow = config.StableWindow
 	}
 
-	target := resources.ResolveTargetConcurrency(pa, config)
+	targetBurstCapacity, ok := pa.TargetBurstCapacity()
+	if !ok {
+		targetBurstCapacity = config.TargetBurstCapacity
+	}
+
+	maxScaleDownRate, ok := pa.MaxScaleDownRate()
+	if !ok {
+		maxScaleDownRate = config.MaxScaleDownRate
+	}
+
+	metric := pa.Metric()
+	var target float64
+	switch metric {
+	case autoscaling.RPS:
+		target = resources.ResolveTargetRPS(pa, config)
+	default:
+		target = resources.ResolveTargetConcurrency(pa, config)
+	}
 	panicThreshold := target * panicThresholdPercentage / 100.0
 
+	spec := autoscaler.DeciderSpec{
+		TickInterval:        config.TickInterval,
+		MaxScaleUpRate:      config.MaxScaleUpRate,
+		MaxScaleDownRate:    maxScaleDownRate,
+		Algorithm:           pa.Algorithm(),
+		ScalingMetric:       metric,
+		TargetValue:         target,
+		PanicThreshold:      panicThreshold,
+		PanicQueueLength:    config.PanicQueueLengthThreshold,
+		TargetBurstCapacity: targetBurstCapacity,
+		StableWindow:        stableWindow,
+		ServiceName:         svc,
+	}
+
+	// Only the concurrency target is tuned to a target latency.
+	if latency, percentile, ok := pa.TargetLatency(); ok && metric == autoscaling.Concurrency {
+		spec.TargetLatency = latency
+		spec.LatencyPercentile = percentile
+		spec.MinTargetValue = config.LatencyTunedTargetMin
+		spec.MaxTargetValue = config.LatencyTunedTargetMax
+		// Never tune past what the containers can take.
+		if cc := float64(pa.Spec.ContainerConcurrency); cc > 0 {
+			spec.MaxTargetValue = math.Min(spec.MaxTargetValue, cc)
+			spec.MinTargetValue = math.Min(spec.MinTargetValue, spec.MaxTargetValue)
+		}
+	}
+
 	return &autoscaler.Decider{
 		ObjectMeta: *pa.ObjectMeta.DeepCopy(),
-		Spec: autoscaler.DeciderSpec{
-			TickInterval:      config.TickInterval,
-			MaxScaleUpRate:    config.MaxScaleUpRate,
-			TargetConcurrency: target,
-			PanicThreshold:    panicThreshold,
-			StableWindow:      stableWindow,
-			ServiceName:       svc,
-		},
+		Spec:       spec,
 	}
 }
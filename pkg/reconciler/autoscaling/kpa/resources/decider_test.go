@@ -70,6 +70,56 @@ func TestMakeDecider(t *testing.T) {
 			withService("rock-solid"),
 			withTarget(10.0), withPanicThreshold(40.0),
 			withTargetAnnotation("10"), withPanicThresholdPercentageAnnotation("400")),
+	}, {
+		name: "with max scale down rate annotation",
+		pa:   pa(WithMaxScaleDownRateAnnotation("4")),
+		want: decider(
+			withTarget(100.0), withPanicThreshold(200.0),
+			withMaxScaleDownRate(4.0), withMaxScaleDownRateAnnotation("4")),
+	}, {
+		name: "with rps metric",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS)),
+		want: decider(
+			withMetric(autoscaling.RPS), withTarget(200.0), withPanicThreshold(400.0),
+			withMetricAnnotation(autoscaling.RPS)),
+	}, {
+		name: "with rps metric and target rps annotation",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithTargetRPSAnnotation("50"), WithContainerConcurrency(1)),
+		want: decider(
+			withMetric(autoscaling.RPS), withTarget(50.0), withPanicThreshold(100.0),
+			withMetricAnnotation(autoscaling.RPS), withTargetRPSAnnotation("50")),
+	}, {
+		name: "with algorithm annotation",
+		pa:   pa(WithAlgorithmAnnotation(autoscaling.PIDAlgorithm)),
+		want: decider(
+			withAlgorithm(autoscaling.PIDAlgorithm), withAlgorithmAnnotation(autoscaling.PIDAlgorithm)),
+	}, {
+		name: "with target burst capacity annotation",
+		pa:   pa(WithTargetBurstCapacityAnnotation("150")),
+		want: decider(
+			withTargetBurstCapacity(150), withAnnotation(autoscaling.TargetBurstCapacityAnnotationKey, "150")),
+	}, {
+		name: "with target latency annotation",
+		pa:   pa(WithTargetLatencyAnnotation("300ms")),
+		want: decider(
+			withTargetLatency(300*time.Millisecond, 95, 1, 100),
+			withAnnotation(autoscaling.TargetLatencyAnnotationKey, "300ms")),
+	}, {
+		name: "with target latency annotation and container concurrency",
+		pa: pa(WithTargetLatencyAnnotation("1s"), WithContainerConcurrency(10),
+			withPercentileAnnotation("99")),
+		want: decider(
+			withTarget(10.0), withPanicThreshold(20.0),
+			withTargetLatency(time.Second, 99, 1, 10),
+			withAnnotation(autoscaling.TargetLatencyAnnotationKey, "1s"),
+			withAnnotation(autoscaling.TargetLatencyPercentileAnnotationKey, "99")),
+	}, {
+		name: "with target latency annotation and rps metric (ignored)",
+		pa:   pa(WithTargetLatencyAnnotation("300ms"), WithMetricAnnotation(autoscaling.RPS)),
+		want: decider(
+			withMetric(autoscaling.RPS), withTarget(200.0), withPanicThreshold(400.0),
+			withMetricAnnotation(autoscaling.RPS),
+			withAnnotation(autoscaling.TargetLatencyAnnotationKey, "300ms")),
 	}}
 
 	for _, tc := range cases {
@@ -111,11 +161,15 @@ func decider(options ...DeciderOption) *autoscaler.Decider {
 			},
 		},
 		Spec: autoscaler.DeciderSpec{
-			MaxScaleUpRate:    config.MaxScaleUpRate,
-			TickInterval:      config.TickInterval,
-			TargetConcurrency: float64(100),
-			PanicThreshold:    float64(200),
-			StableWindow:      config.StableWindow,
+			MaxScaleUpRate:   config.MaxScaleUpRate,
+			MaxScaleDownRate: config.MaxScaleDownRate,
+			TickInterval:     config.TickInterval,
+			Algorithm:        autoscaling.StablePanicAlgorithm,
+			ScalingMetric:    autoscaling.Concurrency,
+			TargetValue:      float64(100),
+			PanicThreshold:   float64(200),
+			PanicQueueLength: config.PanicQueueLengthThreshold,
+			StableWindow:     config.StableWindow,
 		},
 	}
 	for _, fn := range options {
@@ -128,7 +182,31 @@ type DeciderOption func(*autoscaler.Decider)
 
 func withTarget(target float64) DeciderOption {
 	return func(decider *autoscaler.Decider) {
-		decider.Spec.TargetConcurrency = target
+		decider.Spec.TargetValue = target
+	}
+}
+
+func withMetric(metric string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.ScalingMetric = metric
+	}
+}
+
+func withAlgorithm(algorithm string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.Algorithm = algorithm
+	}
+}
+
+func withMaxScaleDownRate(rate float64) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.MaxScaleDownRate = rate
+	}
+}
+
+func withTargetBurstCapacity(tbc float64) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.TargetBurstCapacity = tbc
 	}
 }
 
@@ -143,12 +221,57 @@ func withPanicThreshold(threshold float64) DeciderOption {
 		decider.Spec.PanicThreshold = threshold
 	}
 }
+func withTargetLatency(latency time.Duration, percentile int, min, max float64) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.TargetLatency = latency
+		decider.Spec.LatencyPercentile = percentile
+		decider.Spec.MinTargetValue = min
+		decider.Spec.MaxTargetValue = max
+	}
+}
+
+func withPercentileAnnotation(percentile string) PodAutoscalerOption {
+	return func(pa *v1alpha1.PodAutoscaler) {
+		pa.Annotations[autoscaling.TargetLatencyPercentileAnnotationKey] = percentile
+	}
+}
+
+func withAnnotation(key, value string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Annotations[key] = value
+	}
+}
+
 func withTargetAnnotation(target string) DeciderOption {
 	return func(decider *autoscaler.Decider) {
 		decider.Annotations[autoscaling.TargetAnnotationKey] = target
 	}
 }
 
+func withTargetRPSAnnotation(target string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Annotations[autoscaling.TargetRPSAnnotationKey] = target
+	}
+}
+
+func withMetricAnnotation(metric string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Annotations[autoscaling.MetricAnnotationKey] = metric
+	}
+}
+
+func withAlgorithmAnnotation(algorithm string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Annotations[autoscaling.AlgorithmAnnotationKey] = algorithm
+	}
+}
+
+func withMaxScaleDownRateAnnotation(rate string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Annotations[autoscaling.MaxScaleDownRateAnnotationKey] = rate
+	}
+}
+
 func withPanicThresholdPercentageAnnotation(percentage string) DeciderOption {
 	return func(decider *autoscaler.Decider) {
 		decider.Annotations[autoscaling.PanicThresholdPercentageAnnotationKey] = percentage
@@ -159,9 +282,14 @@ var config = &autoscaler.Config{
 	EnableScaleToZero:                  true,
 	ContainerConcurrencyTargetFraction: 1.0,
 	ContainerConcurrencyTargetDefault:  100.0,
+	RPSTargetDefault:                   200.0,
+	LatencyTunedTargetMin:              1.0,
+	LatencyTunedTargetMax:              100.0,
 	MaxScaleUpRate:                     10.0,
+	MaxScaleDownRate:                   2.0,
 	StableWindow:                       60 * time.Second,
 	PanicThresholdPercentage:           200,
+	PanicQueueLengthThreshold:          2,
 	PanicWindow:                        6 * time.Second,
 	PanicWindowPercentage:              10,
 	TickInterval:                       2 * time.Second,
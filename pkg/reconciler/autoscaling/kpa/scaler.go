@@ -34,9 +34,11 @@ import (
 	"github.com/knative/serving/pkg/reconciler/autoscaling/config"
 	"github.com/knative/serving/pkg/resources"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -59,6 +61,7 @@ type scaler struct {
 	psInformerFactory duck.InformerFactory
 	dynamicClient     dynamic.Interface
 	logger            *zap.SugaredLogger
+	recorder          record.EventRecorder
 	transportFactory  prober.TransportFactory
 
 	// For sync probes.
@@ -70,7 +73,8 @@ type scaler struct {
 }
 
 // newScaler creates a scaler.
-func newScaler(ctx context.Context, psInformerFactory duck.InformerFactory, enqueueCB func(interface{}, time.Duration)) *scaler {
+func newScaler(ctx context.Context, psInformerFactory duck.InformerFactory, recorder record.EventRecorder,
+	enqueueCB func(interface{}, time.Duration)) *scaler {
 	logger := logging.FromContext(ctx)
 	ks := &scaler{
 		// Wrap it in a cache, so that we don't stamp out a new
@@ -78,6 +82,7 @@ func newScaler(ctx context.Context, psInformerFactory duck.InformerFactory, enqu
 		psInformerFactory: psInformerFactory,
 		dynamicClient:     dynamicclient.Get(ctx),
 		logger:            logger,
+		recorder:          recorder,
 		transportFactory: func() http.RoundTripper {
 			return network.NewAutoTransport()
 		},
@@ -122,46 +127,64 @@ func applyBounds(min, max, x int32) int32 {
 	return x
 }
 
-func (ks *scaler) handleScaleToZero(pa *pav1alpha1.PodAutoscaler, desiredScale int32, config *autoscaler.Config) (int32, bool) {
+func (ks *scaler) handleScaleToZero(pa *pav1alpha1.PodAutoscaler, desiredScale int32, lastTraffic time.Time, config *autoscaler.Config) (int32, bool) {
 	if desiredScale != 0 {
 		return desiredScale, true
 	}
 
 	// We should only scale to zero when three of the following conditions are true:
 	//   a) enable-scale-to-zero from configmap is true
-	//   b) The PA has been active for at least the stable window, after which it gets marked inactive
+	//   b) The PA has been active without traffic for at least the idle timeout, after which it gets marked inactive
 	//   c) The PA has been inactive for at least the grace period
+	// The PA annotations may override the idle timeout, which defaults to the
+	// stable window, and the grace period.
 
 	if !config.EnableScaleToZero {
 		return 1, true
 	}
 
+	idleTimeout, gracePeriod := config.StableWindow, config.ScaleToZeroGracePeriod
+	if d, ok := pa.ScaleToZeroIdleTimeout(); ok {
+		idleTimeout = d
+	}
+	if d, ok := pa.ScaleToZeroGracePeriod(); ok {
+		gracePeriod = d
+	}
+
 	if pa.Status.IsActivating() { // Active=Unknown
 		return scaleUnknown, false
 	} else if pa.Status.IsReady() { // Active=True
 		// Don't scale-to-zero if the PA is active
 
+		// The PA is idle since the decider last saw traffic, but no earlier
+		// than it became active.
+		idleSince := lastTraffic
+		if active := pa.Status.GetCondition(pav1alpha1.PodAutoscalerConditionActive); active != nil && active.LastTransitionTime.Inner.After(idleSince) {
+			idleSince = active.LastTransitionTime.Inner.Time
+		}
+		idle := time.Since(idleSince)
+
 		// Do not scale to 0, but return desiredScale of 0 to mark PA inactive.
-		if pa.Status.CanMarkInactive(config.StableWindow) {
+		if idle >= idleTimeout {
 			// We do not need to enqueue PA here, since this will
 			// make SKS reconcile and when it's done, PA will be reconciled again.
 			return desiredScale, false
 		}
 		// Otherwise, scale down to 1 until the idle period elapses and re-enqueue
 		// the PA for reconciliation at that time.
-		ks.enqueueCB(pa, config.StableWindow)
+		ks.enqueueCB(pa, idleTimeout-idle)
 		desiredScale = 1
 	} else { // Active=False
 		r, err := ks.activatorProbe(pa, ks.transportFactory())
 		ks.logger.Infof("%s probing activator = %v, err = %v", pa.Name, r, err)
 		if r {
 			// Make sure we've been inactive for enough time.
-			if pa.Status.CanScaleToZero(config.ScaleToZeroGracePeriod) {
+			if pa.Status.CanScaleToZero(gracePeriod) {
 				return desiredScale, true
 			}
 			// Re-enqeue the PA for reconciliation after grace period.
 			// In istio-lean this can be close to 0.
-			ks.enqueueCB(pa, config.ScaleToZeroGracePeriod)
+			ks.enqueueCB(pa, gracePeriod)
 			return desiredScale, false
 		}
 
@@ -207,26 +230,79 @@ func (ks *scaler) applyScale(ctx context.Context, pa *pav1alpha1.PodAutoscaler,
 	return desiredScale, nil
 }
 
+// frozenScale keeps the scale of the PA's target reference while its scaling
+// is frozen, or pins it to the frozen scale if the PA specifies one.
+func (ks *scaler) frozenScale(ctx context.Context, pa *pav1alpha1.PodAutoscaler) (int32, error) {
+	logger := logging.FromContext(ctx)
+
+	ps, err := resources.GetScaleResource(pa.Namespace, pa.Spec.ScaleTargetRef, ks.psInformerFactory)
+	if err != nil {
+		logger.Errorw(fmt.Sprintf("Resource %q not found", pa.Name), zap.Error(err))
+		return scaleUnknown, err
+	}
+
+	currentScale := int32(1)
+	if ps.Spec.Replicas != nil {
+		currentScale = *ps.Spec.Replicas
+	}
+	frozenScale, ok := pa.FrozenScale()
+	if !ok || frozenScale == currentScale {
+		return currentScale, nil
+	}
+
+	logger.Infof("Scaling from %d to the frozen scale %d", currentScale, frozenScale)
+	if _, err := ks.applyScale(ctx, pa, frozenScale, ps); err != nil {
+		return frozenScale, err
+	}
+	ks.recorder.Eventf(pa, corev1.EventTypeNormal, "ScaleFrozen",
+		"Scaled from %d to the frozen scale %d", currentScale, frozenScale)
+	return frozenScale, nil
+}
+
 // Scale attempts to scale the given PA's target reference to the desired scale.
-func (ks *scaler) Scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, desiredScale int32) (int32, error) {
+// The minimum scale of the PA only applies while a Route refers to it.
+// lastTraffic is the last time the decider saw traffic, which the idle
+// timeout before scaling to zero counts from.
+func (ks *scaler) Scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, routed bool, desiredScale int32, lastTraffic time.Time) (int32, error) {
 	logger := logging.FromContext(ctx)
 
+	if pa.Frozen() {
+		return ks.frozenScale(ctx, pa)
+	}
+
+	asConfig := config.FromContext(ctx).Autoscaler
+	// Inactive PAs are past their initial scale, even without the condition.
+	initializing := !pa.Status.IsScaleTargetInitialized() && !pa.Status.IsInactive()
+	initialScale := asConfig.InitialScaleFor(pa.Annotations)
+	if initializing && initialScale == 0 && desiredScale <= 0 {
+		// The target was created without pods and nothing asked for one yet.
+		return 0, nil
+	}
+
 	if desiredScale < 0 {
 		logger.Debug("Metrics are not yet being collected.")
 		return desiredScale, nil
 	}
 
-	min, max := pa.ScaleBounds()
+	min, max := scaleBounds(pa, routed)
+	unboundedScale, clamped := desiredScale, false
 	if newScale := applyBounds(min, max, desiredScale); newScale != desiredScale {
 		logger.Debugf("Adjusting desiredScale to meet the min and max bounds before applying: %d -> %d", desiredScale, newScale)
-		desiredScale = newScale
+		desiredScale, clamped = newScale, true
 	}
 
-	desiredScale, shouldApplyScale := ks.handleScaleToZero(pa, desiredScale, config.FromContext(ctx).Autoscaler)
+	desiredScale, shouldApplyScale := ks.handleScaleToZero(pa, desiredScale, lastTraffic, asConfig)
 	if !shouldApplyScale {
 		return desiredScale, nil
 	}
 
+	// Until the target first reaches its initial scale, don't let the
+	// autoscaler scale it below that.
+	if initializing && desiredScale < initialScale {
+		logger.Debugf("Adjusting desiredScale to the initial scale: %d -> %d", desiredScale, initialScale)
+		desiredScale = initialScale
+	}
+
 	ps, err := resources.GetScaleResource(pa.Namespace, pa.Spec.ScaleTargetRef, ks.psInformerFactory)
 	if err != nil {
 		logger.Errorw(fmt.Sprintf("Resource %q not found", pa.Name), zap.Error(err))
@@ -242,5 +318,18 @@ func (ks *scaler) Scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, desir
 	}
 
 	logger.Infof("Scaling from %d to %d", currentScale, desiredScale)
-	return ks.applyScale(ctx, pa, desiredScale, ps)
+	if _, err := ks.applyScale(ctx, pa, desiredScale, ps); err != nil {
+		return desiredScale, err
+	}
+
+	switch {
+	case desiredScale == 0:
+		ks.recorder.Eventf(pa, corev1.EventTypeNormal, "ScaledToZero",
+			"Scaled from %d to zero, the target is not receiving traffic", currentScale)
+	case clamped:
+		ks.recorder.Eventf(pa, corev1.EventTypeNormal, "ScaleClamped",
+			"Scaled from %d to %d instead of the desired %d to stay within the min scale %d and the max scale %d",
+			currentScale, desiredScale, unboundedScale, min, max)
+	}
+	return desiredScale, nil
 }
@@ -26,12 +26,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
 	// These are the fake informers we want setup.
 	fakedynamicclient "github.com/knative/pkg/injection/clients/dynamicclient/fake"
 	fakeservingclient "github.com/knative/serving/pkg/client/injection/client/fake"
 
 	"github.com/knative/pkg/apis"
 	"github.com/knative/pkg/apis/duck"
+	"github.com/knative/pkg/controller"
 	"github.com/knative/pkg/logging"
 	logtesting "github.com/knative/pkg/logging/testing"
 	_ "github.com/knative/pkg/system/testing"
@@ -54,14 +58,17 @@ import (
 	"k8s.io/client-go/dynamic"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 
 	. "github.com/knative/pkg/reconciler/testing"
 	. "github.com/knative/serving/pkg/testing"
 )
 
 const (
-	testNamespace = "test-namespace"
-	testRevision  = "test-revision"
+	testNamespace     = "test-namespace"
+	testRevision      = "test-revision"
+	testConfiguration = "test-configuration"
+	testRoute         = "test-route"
 )
 
 func TestScaler(t *testing.T) {
@@ -72,12 +79,16 @@ func TestScaler(t *testing.T) {
 		scaleTo             int32
 		minScale            int32
 		maxScale            int32
+		unrouted            bool
+		lastTraffic         time.Time
 		wantReplicas        int32
 		wantScaling         bool
 		kpaMutation         func(*pav1alpha1.PodAutoscaler)
+		configMutation      func(*config.Config)
 		proberfunc          func(*pav1alpha1.PodAutoscaler, http.RoundTripper) (bool, error)
 		wantCBCount         int
 		wantAsyncProbeCount int
+		wantEvents          []string
 	}{{
 		label:         "waits to scale to zero (just before idle period)",
 		startReplicas: 1,
@@ -116,6 +127,9 @@ func TestScaler(t *testing.T) {
 		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
 			kpaMarkInactive(k, time.Now().Add(-gracePeriod))
 		},
+		wantEvents: []string{
+			"Normal ScaledToZero Scaled from 1 to zero, the target is not receiving traffic",
+		},
 	}, {
 		label:         "waits to scale to zero (just before grace period)",
 		startReplicas: 1,
@@ -126,6 +140,62 @@ func TestScaler(t *testing.T) {
 			kpaMarkInactive(k, time.Now().Add(-gracePeriod).Add(1*time.Second))
 		},
 		wantCBCount: 1,
+	}, {
+		label:         "waits for the grace period annotation",
+		startReplicas: 1,
+		scaleTo:       0,
+		wantReplicas:  0,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.ScaleToZeroGracePeriodAnnotationKey] = "10m"
+			kpaMarkInactive(k, time.Now().Add(-gracePeriod))
+		},
+		wantCBCount: 1,
+	}, {
+		label:         "marks inactive after the idle timeout annotation",
+		startReplicas: 1,
+		scaleTo:       0,
+		wantReplicas:  0,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.ScaleToZeroIdleTimeoutAnnotationKey] = "10s"
+			kpaMarkActive(k, time.Now().Add(-10*time.Second))
+		},
+	}, {
+		label:         "stays active until the idle timeout annotation",
+		startReplicas: 1,
+		scaleTo:       0,
+		wantReplicas:  1,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.ScaleToZeroIdleTimeoutAnnotationKey] = "15m"
+			kpaMarkActive(k, time.Now().Add(-stableWindow))
+		},
+		wantCBCount: 1,
+	}, {
+		label:         "stays active until the idle timeout after the last traffic",
+		startReplicas: 1,
+		scaleTo:       0,
+		lastTraffic:   time.Now().Add(-time.Minute),
+		wantReplicas:  1,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.ScaleToZeroIdleTimeoutAnnotationKey] = "5m"
+			// Active for much longer than the idle timeout.
+			kpaMarkActive(k, time.Now().Add(-time.Hour))
+		},
+		wantCBCount: 1,
+	}, {
+		label:         "marks inactive the idle timeout after the last traffic",
+		startReplicas: 1,
+		scaleTo:       0,
+		lastTraffic:   time.Now().Add(-5 * time.Minute),
+		wantReplicas:  0,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.ScaleToZeroIdleTimeoutAnnotationKey] = "5m"
+			kpaMarkActive(k, time.Now().Add(-time.Hour))
+		},
 	}, {
 		label:         "scale to zero after grace period, but fail prober",
 		startReplicas: 1,
@@ -169,6 +239,9 @@ func TestScaler(t *testing.T) {
 		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
 			kpaMarkInactive(k, time.Now().Add(-gracePeriod+time.Second))
 		},
+		wantEvents: []string{
+			"Normal ScaleClamped Scaled from 10 to 2 instead of the desired 0 to stay within the min scale 2 and the max scale 0",
+		},
 	}, {
 		label:         "scale down to minScale after grace period",
 		startReplicas: 10,
@@ -179,6 +252,50 @@ func TestScaler(t *testing.T) {
 		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
 			kpaMarkInactive(k, time.Now().Add(-gracePeriod))
 		},
+		wantEvents: []string{
+			"Normal ScaleClamped Scaled from 10 to 2 instead of the desired 0 to stay within the min scale 2 and the max scale 0",
+		},
+	}, {
+		label:         "unrouted scale down below minScale",
+		startReplicas: 10,
+		scaleTo:       1,
+		minScale:      2,
+		unrouted:      true,
+		wantReplicas:  1,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			kpaMarkActive(k, time.Now())
+			k.Status.MarkScaleTargetInitialized()
+		},
+	}, {
+		label:         "unrouted scale to zero after grace period despite minScale",
+		startReplicas: 2,
+		scaleTo:       0,
+		minScale:      2,
+		unrouted:      true,
+		wantReplicas:  0,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			kpaMarkInactive(k, time.Now().Add(-gracePeriod))
+		},
+		wantEvents: []string{
+			"Normal ScaledToZero Scaled from 2 to zero, the target is not receiving traffic",
+		},
+	}, {
+		label:         "unrouted keeps minScale with keepMinScale",
+		startReplicas: 10,
+		scaleTo:       0,
+		minScale:      2,
+		unrouted:      true,
+		wantReplicas:  2,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.KeepMinScaleAnnotationKey] = "true"
+			kpaMarkInactive(k, time.Now().Add(-gracePeriod))
+		},
+		wantEvents: []string{
+			"Normal ScaleClamped Scaled from 10 to 2 instead of the desired 0 to stay within the min scale 2 and the max scale 0",
+		},
 	}, {
 		label:         "scales up",
 		startReplicas: 1,
@@ -192,6 +309,9 @@ func TestScaler(t *testing.T) {
 		maxScale:      8,
 		wantReplicas:  8,
 		wantScaling:   true,
+		wantEvents: []string{
+			"Normal ScaleClamped Scaled from 1 to 8 instead of the desired 10 to stay within the min scale 0 and the max scale 8",
+		},
 	}, {
 		label:         "scale up inactive revision",
 		startReplicas: 1,
@@ -225,6 +345,104 @@ func TestScaler(t *testing.T) {
 		scaleTo:       -1,
 		wantReplicas:  -1,
 		wantScaling:   false,
+	}, {
+		label:         "frozen keeps the scale",
+		startReplicas: 5,
+		scaleTo:       10,
+		wantReplicas:  5,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.FreezeAnnotationKey] = "true"
+		},
+	}, {
+		label:         "frozen keeps the scale without metrics",
+		startReplicas: 5,
+		scaleTo:       -1,
+		wantReplicas:  5,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.FreezeAnnotationKey] = "true"
+		},
+	}, {
+		label:         "frozen does not scale to zero",
+		startReplicas: 1,
+		scaleTo:       0,
+		wantReplicas:  1,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			kpaMarkInactive(k, time.Now().Add(-gracePeriod))
+			k.Annotations[autoscaling.FreezeAnnotationKey] = "true"
+		},
+	}, {
+		label:         "frozen scale pins the scale",
+		startReplicas: 5,
+		scaleTo:       10,
+		maxScale:      8,
+		wantReplicas:  3,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.FreezeAnnotationKey] = "true"
+			k.Annotations[autoscaling.FrozenScaleAnnotationKey] = "3"
+		},
+		wantEvents: []string{
+			"Normal ScaleFrozen Scaled from 5 to the frozen scale 3",
+		},
+	}, {
+		label:         "frozen scale without the freeze is ignored",
+		startReplicas: 5,
+		scaleTo:       10,
+		wantReplicas:  10,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.FrozenScaleAnnotationKey] = "3"
+		},
+	}, {
+		label:         "scales up to the initial scale",
+		startReplicas: 1,
+		scaleTo:       2,
+		wantReplicas:  5,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.InitialScaleAnnotationKey] = "5"
+			k.Status.MarkActivating("Queued", "")
+		},
+	}, {
+		label:         "initialized target ignores the initial scale",
+		startReplicas: 5,
+		scaleTo:       2,
+		wantReplicas:  2,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.InitialScaleAnnotationKey] = "5"
+			kpaMarkActive(k, time.Now())
+			k.Status.MarkScaleTargetInitialized()
+		},
+	}, {
+		label:         "zero initial scale stays at zero",
+		startReplicas: 0,
+		scaleTo:       -1,
+		wantReplicas:  0,
+		wantScaling:   false,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.InitialScaleAnnotationKey] = "0"
+			k.Status.MarkActivating("Queued", "")
+		},
+		configMutation: func(c *config.Config) {
+			c.Autoscaler.AllowZeroInitialScale = true
+		},
+	}, {
+		label:         "zero initial scale scales up on demand",
+		startReplicas: 0,
+		scaleTo:       2,
+		wantReplicas:  2,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.InitialScaleAnnotationKey] = "0"
+			k.Status.MarkActivating("Queued", "")
+		},
+		configMutation: func(c *config.Config) {
+			c.Autoscaler.AllowZeroInitialScale = true
+		},
 	}}
 
 	for _, test := range tests {
@@ -236,7 +454,7 @@ func TestScaler(t *testing.T) {
 			revision := newRevision(t, fakeservingclient.Get(ctx), test.minScale, test.maxScale)
 			deployment := newDeployment(t, dynamicClient, names.Deployment(revision), test.startReplicas)
 			cbCount := 0
-			revisionScaler := newScaler(ctx, presources.NewPodScalableInformerFactory(ctx), func(interface{}, time.Duration) {
+			revisionScaler := newScaler(ctx, presources.NewPodScalableInformerFactory(ctx), controller.GetEventRecorder(ctx), func(interface{}, time.Duration) {
 				cbCount++
 			})
 			if test.proberfunc != nil {
@@ -265,8 +483,12 @@ func TestScaler(t *testing.T) {
 				test.kpaMutation(pa)
 			}
 
-			ctx = config.ToContext(ctx, defaultConfig())
-			desiredScale, err := revisionScaler.Scale(ctx, pa, test.scaleTo)
+			cfg := defaultConfig()
+			if test.configMutation != nil {
+				test.configMutation(cfg)
+			}
+			ctx = config.ToContext(ctx, cfg)
+			desiredScale, err := revisionScaler.Scale(ctx, pa, !test.unrouted, test.scaleTo, test.lastTraffic)
 			if err != nil {
 				t.Error("Scale got an unexpected error: ", err)
 			}
@@ -285,10 +507,27 @@ func TestScaler(t *testing.T) {
 				}
 				checkReplicas(t, dynamicClient, deployment, test.wantReplicas)
 			}
+			if got, want := recordedEvents(ctx), test.wantEvents; !cmp.Equal(got, want, cmpopts.EquateEmpty()) {
+				t.Errorf("Events = %v, want: %v, diff(-want,+got): %s", got, want, cmp.Diff(want, got, cmpopts.EquateEmpty()))
+			}
 		})
 	}
 }
 
+// recordedEvents drains the events recorded by the fake recorder in the context.
+func recordedEvents(ctx context.Context) []string {
+	events := controller.GetEventRecorder(ctx).(*record.FakeRecorder).Events
+	var got []string
+	for {
+		select {
+		case event := <-events:
+			got = append(got, event)
+		default:
+			return got
+		}
+	}
+}
+
 func TestDisableScaleToZero(t *testing.T) {
 	defer logtesting.ClearAll()
 	tests := []struct {
@@ -344,6 +583,7 @@ func TestDisableScaleToZero(t *testing.T) {
 			revisionScaler := &scaler{
 				dynamicClient:     fakedynamicclient.Get(ctx),
 				logger:            logging.FromContext(ctx),
+				recorder:          controller.GetEventRecorder(ctx),
 				psInformerFactory: presources.NewPodScalableInformerFactory(ctx),
 			}
 			pa := newKPA(t, fakeservingclient.Get(ctx), revision)
@@ -351,7 +591,7 @@ func TestDisableScaleToZero(t *testing.T) {
 			conf := defaultConfig()
 			conf.Autoscaler.EnableScaleToZero = false
 			ctx = config.ToContext(ctx, conf)
-			desiredScale, err := revisionScaler.Scale(ctx, pa, test.scaleTo)
+			desiredScale, err := revisionScaler.Scale(ctx, pa, true, test.scaleTo, time.Time{})
 
 			if err != nil {
 				t.Error("Scale got an unexpected error: ", err)
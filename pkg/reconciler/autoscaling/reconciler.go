@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	perrors "github.com/pkg/errors"
 
@@ -56,19 +57,17 @@ type Base struct {
 	PSInformerFactory duck.InformerFactory
 }
 
-// ReconcileSKS reconciles a ServerlessService based on the given PodAutoscaler.
-func (c *Base) ReconcileSKS(ctx context.Context, pa *pav1alpha1.PodAutoscaler) (*nv1alpha1.ServerlessService, error) {
+// ReconcileSKS reconciles a ServerlessService in the given mode, assigned to the given
+// number of activators, based on the given PodAutoscaler.
+func (c *Base) ReconcileSKS(ctx context.Context, pa *pav1alpha1.PodAutoscaler,
+	mode nv1alpha1.ServerlessServiceOperationMode, numActivators int32) (*nv1alpha1.ServerlessService, error) {
 	logger := logging.FromContext(ctx)
 
-	mode := nv1alpha1.SKSOperationModeServe
-	if pa.Status.IsInactive() {
-		mode = nv1alpha1.SKSOperationModeProxy
-	}
 	sksName := anames.SKS(pa.Name)
 	sks, err := c.SKSLister.ServerlessServices(pa.Namespace).Get(sksName)
 	if errors.IsNotFound(err) {
 		logger.Infof("SKS %s/%s does not exist; creating.", pa.Namespace, sksName)
-		sks = resources.MakeSKS(pa, mode)
+		sks = resources.MakeSKS(pa, mode, numActivators)
 		_, err = c.ServingClientSet.NetworkingV1alpha1().ServerlessServices(sks.Namespace).Create(sks)
 		if err != nil {
 			return nil, perrors.Wrapf(err, "error creating SKS %s", sksName)
@@ -80,7 +79,7 @@ func (c *Base) ReconcileSKS(ctx context.Context, pa *pav1alpha1.PodAutoscaler) (
 		pa.Status.MarkResourceNotOwned("ServerlessService", sksName)
 		return nil, fmt.Errorf("PA: %s does not own SKS: %s", pa.Name, sksName)
 	} else {
-		tmpl := resources.MakeSKS(pa, mode)
+		tmpl := resources.MakeSKS(pa, mode, numActivators)
 		if !equality.Semantic.DeepEqual(tmpl.Spec, sks.Spec) {
 			want := sks.DeepCopy()
 			want.Spec = tmpl.Spec
@@ -123,6 +122,22 @@ func (c *Base) metricService(pa *pav1alpha1.PodAutoscaler) (*corev1.Service, err
 	return ret, nil
 }
 
+// ReconcileScaleSchedule reports the active entries of the scale schedule in
+// the PA status and returns how long until the scale bounds change next, if
+// they ever do, so that the caller can requeue the PA for then.
+func (c *Base) ReconcileScaleSchedule(pa *pav1alpha1.PodAutoscaler, now time.Time) (time.Duration, bool) {
+	schedule := pa.ScaleSchedule()
+	pa.Status.ActiveScaleSchedule = nil
+	for _, entry := range schedule.Active(now) {
+		pa.Status.ActiveScaleSchedule = append(pa.Status.ActiveScaleSchedule, entry.Text)
+	}
+	next, ok := schedule.NextChange(now)
+	if !ok {
+		return 0, false
+	}
+	return next.Sub(now), true
+}
+
 // ReconcileMetricsService reconciles a metrics service for the given PodAutoscaler.
 func (c *Base) ReconcileMetricsService(ctx context.Context, pa *pav1alpha1.PodAutoscaler) (string, error) {
 	logger := logging.FromContext(ctx)
@@ -53,11 +53,16 @@ func MakeMetric(ctx context.Context, pa *v1alpha1.PodAutoscaler, metricSvc strin
 		panicWindowPercentage = config.PanicWindowPercentage
 	}
 	panicWindow := time.Duration(float64(stableWindow) * panicWindowPercentage / 100.0)
+	aggregation, ok := pa.Aggregation()
+	if !ok {
+		aggregation = config.Aggregation
+	}
 	return &autoscaler.Metric{
 		ObjectMeta: pa.ObjectMeta,
 		Spec: autoscaler.MetricSpec{
 			StableWindow: stableWindow,
 			PanicWindow:  panicWindow,
+			Aggregation:  aggregation,
 			ScrapeTarget: metricSvc,
 		},
 	}
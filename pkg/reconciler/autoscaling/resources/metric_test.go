@@ -57,6 +57,14 @@ func TestMakeMetric(t *testing.T) {
 			withScarapeTarget("dansen"),
 			withStableWindow(time.Minute), withPanicWindow(30*time.Second),
 			withPanicWindowPercentageAnnotation("50")),
+	}, {
+		name: "with aggregation annotation",
+		pa:   pa(WithAggregationAnnotation(autoscaling.EWMAAggregation)),
+		msn:  "wiegen",
+		want: metric(
+			withScarapeTarget("wiegen"),
+			withAggregation(autoscaling.EWMAAggregation),
+			withAggregationAnnotation(autoscaling.EWMAAggregation)),
 	}}
 
 	for _, tc := range cases {
@@ -82,6 +90,12 @@ func withPanicWindow(window time.Duration) MetricOption {
 	}
 }
 
+func withAggregation(aggregation string) MetricOption {
+	return func(metric *autoscaler.Metric) {
+		metric.Spec.Aggregation = aggregation
+	}
+}
+
 func withWindowAnnotation(window string) MetricOption {
 	return func(metric *autoscaler.Metric) {
 		metric.Annotations[autoscaling.WindowAnnotationKey] = window
@@ -94,6 +108,12 @@ func withPanicWindowPercentageAnnotation(percentage string) MetricOption {
 	}
 }
 
+func withAggregationAnnotation(aggregation string) MetricOption {
+	return func(metric *autoscaler.Metric) {
+		metric.Annotations[autoscaling.AggregationAnnotationKey] = aggregation
+	}
+}
+
 func withScarapeTarget(s string) MetricOption {
 	return func(metric *autoscaler.Metric) {
 		metric.Spec.ScrapeTarget = s
@@ -112,6 +132,7 @@ func metric(options ...MetricOption) *autoscaler.Metric {
 		Spec: autoscaler.MetricSpec{
 			StableWindow: 60 * time.Second,
 			PanicWindow:  6 * time.Second,
+			Aggregation:  autoscaling.AverageAggregation,
 		},
 	}
 	for _, fn := range options {
@@ -144,11 +165,14 @@ var config = &autoscaler.Config{
 	EnableScaleToZero:                  true,
 	ContainerConcurrencyTargetFraction: 1.0,
 	ContainerConcurrencyTargetDefault:  100.0,
+	RPSTargetDefault:                   200.0,
 	MaxScaleUpRate:                     10.0,
+	MaxScaleDownRate:                   2.0,
 	StableWindow:                       60 * time.Second,
 	PanicThresholdPercentage:           200,
 	PanicWindow:                        6 * time.Second,
 	PanicWindowPercentage:              10,
+	Aggregation:                        autoscaling.AverageAggregation,
 	TickInterval:                       2 * time.Second,
 	ScaleToZeroGracePeriod:             30 * time.Second,
 }
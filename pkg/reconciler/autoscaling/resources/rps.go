@@ -0,0 +1,32 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+	"github.com/knative/serving/pkg/autoscaler"
+)
+
+// ResolveTargetRPS takes the requests per second knobs from multiple locations and
+// resolves them to the final value to be used by the autoscaler.
+func ResolveTargetRPS(pa *v1alpha1.PodAutoscaler, config *autoscaler.Config) float64 {
+	// Use the target provided via annotation, if applicable.
+	if annotationTarget, ok := pa.TargetRPS(); ok {
+		return annotationTarget
+	}
+	return config.RPSTargetDefault
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+
+	. "github.com/knative/serving/pkg/testing"
+)
+
+func TestResolveTargetRPS(t *testing.T) {
+	cases := []struct {
+		name string
+		pa   *v1alpha1.PodAutoscaler
+		want float64
+	}{{
+		name: "defaults",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS)),
+		want: 200,
+	}, {
+		name: "with target rps annotation",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithTargetRPSAnnotation("50")),
+		want: 50,
+	}, {
+		name: "with invalid target rps annotation",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithTargetRPSAnnotation("-1")),
+		want: 200,
+	}, {
+		name: "container concurrency is ignored",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithContainerConcurrency(1)),
+		want: 200,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ResolveTargetRPS(tc.pa, config); got != tc.want {
+				t.Errorf("ResolveTargetRPS(%v, %v) = %v, want %v", tc.pa, config, got, tc.want)
+			}
+		})
+	}
+}
@@ -86,9 +86,10 @@ func TestMakeSKS(t *testing.T) {
 				Kind:       "Deployment",
 				Name:       "blah",
 			},
+			NumActivators: 3,
 		},
 	}
-	if got, want := MakeSKS(pa, mode), want; !cmp.Equal(got, want) {
+	if got, want := MakeSKS(pa, mode, 3), want; !cmp.Equal(got, want) {
 		t.Errorf("MakeSKS = %#v, want: %#v, diff: %s", got, want, cmp.Diff(got, want))
 	}
 }
@@ -172,6 +172,14 @@ func (c *Reconciler) reconcileKPA(ctx context.Context, rev *v1alpha1.Revision) e
 		rev.Status.MarkDeploying("Updating")
 	}
 
+	// The scaling freeze may be toggled on the revision at any time.
+	if want, changed := resources.PropagateFreeze(rev, kpa); changed {
+		logger.Infof("Propagating the scaling freeze to KPA %s", kpa.Name)
+		if kpa, err = c.ServingClientSet.AutoscalingV1alpha1().PodAutoscalers(kpa.Namespace).Update(want); err != nil {
+			return err
+		}
+	}
+
 	// Propagate the service name from the PA.
 	rev.Status.ServiceName = kpa.Status.ServiceName
 
@@ -185,6 +193,13 @@ func (c *Reconciler) reconcileKPA(ctx context.Context, rev *v1alpha1.Revision) e
 		rev.Status.MarkActivating(cond.Reason, cond.Message)
 	case cond.Status == corev1.ConditionFalse:
 		rev.Status.MarkInactive(cond.Reason, cond.Message)
+
+		// A revision with zero initial scale has no pods to wait for.
+		ra := rev.Status.GetCondition(v1alpha1.RevisionConditionResourcesAvailable)
+		if kpa.Status.IsScaleTargetInitialized() && ra != nil && ra.Status == corev1.ConditionUnknown {
+			rev.Status.MarkResourcesAvailable()
+			rev.Status.MarkContainerHealthy()
+		}
 	case cond.Status == corev1.ConditionTrue:
 		rev.Status.MarkActive()
 
@@ -27,6 +27,7 @@ import (
 	"github.com/knative/pkg/ptr"
 	"github.com/knative/pkg/system"
 	_ "github.com/knative/pkg/system/testing"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/apis/networking"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
@@ -804,6 +805,55 @@ func TestMakeDeployment(t *testing.T) {
 			deploy.ObjectMeta.Annotations[IstioOutboundIPRangeAnnotation] = "10.4.0.0/14,10.7.240.0/20"
 			deploy.Spec.Template.ObjectMeta.Annotations[IstioOutboundIPRangeAnnotation] = "10.4.0.0/14,10.7.240.0/20"
 		}),
+	}, {
+		name: "with initial scale configured",
+		rev:  revision(withoutLabels),
+		lc:   &logging.Config{},
+		nc:   &network.Config{},
+		oc:   &metrics.ObservabilityConfig{},
+		ac:   &autoscaler.Config{InitialScale: 3},
+		cc:   &deployment.Config{},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			deploy.Spec.Replicas = ptr.Int32(3)
+		}),
+	}, {
+		name: "with initial scale annotation",
+		rev: revision(withoutLabels, func(revision *v1alpha1.Revision) {
+			revision.ObjectMeta.Annotations = map[string]string{
+				autoscaling.InitialScaleAnnotationKey: "5",
+			}
+		}),
+		lc: &logging.Config{},
+		nc: &network.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{InitialScale: 3},
+		cc: &deployment.Config{},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			deploy.ObjectMeta.Annotations[autoscaling.InitialScaleAnnotationKey] = "5"
+			deploy.Spec.Template.ObjectMeta.Annotations[autoscaling.InitialScaleAnnotationKey] = "5"
+			deploy.Spec.Replicas = ptr.Int32(5)
+		}),
+	}, {
+		name: "with zero initial scale",
+		rev: revision(withoutLabels, func(revision *v1alpha1.Revision) {
+			revision.ObjectMeta.Annotations = map[string]string{
+				autoscaling.InitialScaleAnnotationKey: "0",
+			}
+		}),
+		lc: &logging.Config{},
+		nc: &network.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{
+			EnableScaleToZero:     true,
+			AllowZeroInitialScale: true,
+			InitialScale:          1,
+		},
+		cc: &deployment.Config{},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			deploy.ObjectMeta.Annotations[autoscaling.InitialScaleAnnotationKey] = "0"
+			deploy.Spec.Template.ObjectMeta.Annotations[autoscaling.InitialScaleAnnotationKey] = "0"
+			deploy.Spec.Replicas = ptr.Int32(0)
+		}),
 	}}
 
 	for _, test := range tests {
@@ -17,10 +17,15 @@ limitations under the License.
 package resources
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/knative/pkg/kmeta"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	kpa "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
@@ -30,7 +35,7 @@ import (
 
 // MakeKPA makes a Knative Pod Autoscaler resource from a revision.
 func MakeKPA(rev *v1alpha1.Revision) *kpa.PodAutoscaler {
-	return &kpa.PodAutoscaler{
+	pa := &kpa.PodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.KPA(rev),
 			Namespace: rev.Namespace,
@@ -51,4 +56,48 @@ func MakeKPA(rev *v1alpha1.Revision) *kpa.PodAutoscaler {
 			ProtocolType: rev.GetProtocol(),
 		},
 	}
+	// Record the freeze annotations copied from the revision as propagated.
+	pa, _ = PropagateFreeze(rev, pa)
+	return pa
+}
+
+// freezeAnnotations are the annotations of a revision kept in sync with its
+// Knative Pod Autoscaler after it is created.
+var freezeAnnotations = []string{
+	autoscaling.FreezeAnnotationKey,
+	autoscaling.FrozenScaleAnnotationKey,
+}
+
+// PropagateFreeze returns a copy of the Knative Pod Autoscaler with the
+// scaling freeze annotations of the revision, and whether they changed.
+// Annotations the revision lacks are only removed if they were propagated
+// from it before, so a freeze set on the Knative Pod Autoscaler stays.
+func PropagateFreeze(rev *v1alpha1.Revision, pa *kpa.PodAutoscaler) (*kpa.PodAutoscaler, bool) {
+	propagated := sets.NewString()
+	if v := pa.Annotations[autoscaling.FreezePropagatedAnnotationKey]; v != "" {
+		propagated.Insert(strings.Split(v, ",")...)
+	}
+
+	want := pa.DeepCopy()
+	set := func(k, v string) {
+		if want.Annotations == nil {
+			want.Annotations = make(map[string]string, len(freezeAnnotations)+1)
+		}
+		want.Annotations[k] = v
+	}
+	for _, k := range freezeAnnotations {
+		if v, ok := rev.Annotations[k]; ok {
+			set(k, v)
+			propagated.Insert(k)
+		} else if propagated.Has(k) {
+			delete(want.Annotations, k)
+			propagated.Delete(k)
+		}
+	}
+	if propagated.Len() > 0 {
+		set(autoscaling.FreezePropagatedAnnotationKey, strings.Join(propagated.List(), ","))
+	} else {
+		delete(want.Annotations, autoscaling.FreezePropagatedAnnotationKey)
+	}
+	return want, !equality.Semantic.DeepEqual(pa.Annotations, want.Annotations)
 }
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/knative/pkg/ptr"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	kpa "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"github.com/knative/serving/pkg/apis/networking"
 	"github.com/knative/serving/pkg/apis/serving"
@@ -48,6 +49,7 @@ func TestMakeKPA(t *testing.T) {
 				Annotations: map[string]string{
 					"a":                                     "b",
 					serving.RevisionLastPinnedAnnotationKey: "timeless",
+					autoscaling.FreezeAnnotationKey:         "true",
 				},
 			},
 			Spec: v1alpha1.RevisionSpec{
@@ -66,7 +68,9 @@ func TestMakeKPA(t *testing.T) {
 					AppLabelKey:              "bar",
 				},
 				Annotations: map[string]string{
-					"a": "b",
+					"a":                             "b",
+					autoscaling.FreezeAnnotationKey: "true",
+					autoscaling.FreezePropagatedAnnotationKey: autoscaling.FreezeAnnotationKey,
 				},
 				OwnerReferences: []metav1.OwnerReference{{
 					APIVersion:         v1alpha1.SchemeGroupVersion.String(),
@@ -146,3 +150,82 @@ func TestMakeKPA(t *testing.T) {
 		})
 	}
 }
+
+func TestPropagateFreeze(t *testing.T) {
+	const (
+		freeze      = autoscaling.FreezeAnnotationKey
+		frozenScale = autoscaling.FrozenScaleAnnotationKey
+		propagated  = autoscaling.FreezePropagatedAnnotationKey
+	)
+	tests := []struct {
+		name        string
+		rev         map[string]string
+		pa          map[string]string
+		want        map[string]string
+		wantChanged bool
+	}{{
+		name: "no freeze",
+		rev:  map[string]string{"a": "b"},
+		pa:   map[string]string{"a": "b"},
+		want: map[string]string{"a": "b"},
+	}, {
+		name:        "freeze",
+		rev:         map[string]string{freeze: "true", frozenScale: "2"},
+		want:        map[string]string{freeze: "true", frozenScale: "2", propagated: freeze + "," + frozenScale},
+		wantChanged: true,
+	}, {
+		name:        "change frozen scale",
+		rev:         map[string]string{freeze: "true", frozenScale: "3"},
+		pa:          map[string]string{freeze: "true", frozenScale: "2", propagated: freeze + "," + frozenScale},
+		want:        map[string]string{freeze: "true", frozenScale: "3", propagated: freeze + "," + frozenScale},
+		wantChanged: true,
+	}, {
+		name:        "unfreeze",
+		rev:         map[string]string{"a": "b"},
+		pa:          map[string]string{"a": "b", freeze: "true", propagated: freeze},
+		want:        map[string]string{"a": "b"},
+		wantChanged: true,
+	}, {
+		name:        "drop frozen scale",
+		rev:         map[string]string{freeze: "true"},
+		pa:          map[string]string{freeze: "true", frozenScale: "2", propagated: freeze + "," + frozenScale},
+		want:        map[string]string{freeze: "true", propagated: freeze},
+		wantChanged: true,
+	}, {
+		name: "already frozen",
+		rev:  map[string]string{freeze: "true"},
+		pa:   map[string]string{freeze: "true", propagated: freeze, "c": "d"},
+		want: map[string]string{freeze: "true", propagated: freeze, "c": "d"},
+	}, {
+		name:        "frozen before propagation was recorded",
+		rev:         map[string]string{freeze: "true"},
+		pa:          map[string]string{freeze: "true"},
+		want:        map[string]string{freeze: "true", propagated: freeze},
+		wantChanged: true,
+	}, {
+		name: "freeze set on the pa",
+		rev:  map[string]string{"a": "b"},
+		pa:   map[string]string{"a": "b", freeze: "true", frozenScale: "2"},
+		want: map[string]string{"a": "b", freeze: "true", frozenScale: "2"},
+	}, {
+		name:        "frozen scale set on the pa",
+		rev:         map[string]string{freeze: "true"},
+		pa:          map[string]string{frozenScale: "2"},
+		want:        map[string]string{freeze: "true", frozenScale: "2", propagated: freeze},
+		wantChanged: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Annotations: test.rev}}
+			pa := &kpa.PodAutoscaler{ObjectMeta: metav1.ObjectMeta{Annotations: test.pa}}
+			got, changed := PropagateFreeze(rev, pa)
+			if changed != test.wantChanged {
+				t.Errorf("PropagateFreeze() changed = %v, want %v", changed, test.wantChanged)
+			}
+			if diff := cmp.Diff(test.want, got.Annotations, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("PropagateFreeze() annotations (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/knative/pkg/controller"
 	"github.com/knative/pkg/logging"
 	logtesting "github.com/knative/pkg/logging/testing"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	autoscalingv1alpha1 "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"github.com/knative/serving/pkg/apis/networking"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
@@ -338,6 +339,26 @@ func TestReconcile(t *testing.T) {
 				MarkInactive("NoTraffic", "This thing is inactive.")),
 		}},
 		Key: "foo/kpa-inactive",
+	}, {
+		Name: "kpa inactive with zero initial scale",
+		// Test a revision that starts without pods becoming ready while
+		// its KPA is inactive.
+		Objects: []runtime.Object{
+			rev("foo", "kpa-zero-initial-scale", WithLogURL, AllUnknownConditions),
+			kpa("foo", "kpa-zero-initial-scale", withScaleTargetInitialized,
+				WithNoTraffic("NoTraffic", "This thing is inactive.")),
+			deploy("foo", "kpa-zero-initial-scale"),
+			image("foo", "kpa-zero-initial-scale"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "kpa-zero-initial-scale",
+				WithLogURL, MarkRevisionReady,
+				MarkInactive("NoTraffic", "This thing is inactive.")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon all resources being ready"),
+		},
+		Key: "foo/kpa-zero-initial-scale",
 	}, {
 		Name: "kpa inactive, but has service",
 		// Test propagating the inactivity signal from the KPA to the Revision.
@@ -511,6 +532,45 @@ func TestReconcile(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon all resources being ready"),
 		},
 		Key: "foo/steady-ready",
+	}, {
+		Name: "freeze set on the kpa is kept",
+		// A freeze set directly on the KPA rather than propagated from
+		// the revision must not be removed.
+		Objects: []runtime.Object{
+			rev("foo", "kpa-freeze", withK8sServiceName("kpa-freeze"), WithLogURL),
+			kpa("foo", "kpa-freeze", WithTraffic, WithPAStatusService("kpa-freeze"), withKPAFreeze),
+			deploy("foo", "kpa-freeze"),
+			image("foo", "kpa-freeze"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "kpa-freeze", withK8sServiceName("kpa-freeze"), WithLogURL,
+				MarkRevisionReady),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon all resources being ready"),
+		},
+		Key: "foo/kpa-freeze",
+	}, {
+		Name: "propagated freeze is removed",
+		// The revision was unfrozen, so the freeze it propagated goes.
+		Objects: []runtime.Object{
+			rev("foo", "unfreeze", withK8sServiceName("unfreeze"), WithLogURL),
+			kpa("foo", "unfreeze", WithTraffic, WithPAStatusService("unfreeze"), withKPAFreeze,
+				withKPAFreezePropagated),
+			deploy("foo", "unfreeze"),
+			image("foo", "unfreeze"),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa("foo", "unfreeze", WithTraffic, WithPAStatusService("unfreeze")),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "unfreeze", withK8sServiceName("unfreeze"), WithLogURL,
+				MarkRevisionReady),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon all resources being ready"),
+		},
+		Key: "foo/unfreeze",
 	}, {
 		Name:    "lost kpa owner ref",
 		WantErr: true,
@@ -623,6 +683,18 @@ func withK8sServiceName(sn string) RevisionOption {
 	}
 }
 
+func withKPAFreeze(pa *autoscalingv1alpha1.PodAutoscaler) {
+	pa.Annotations[autoscaling.FreezeAnnotationKey] = "true"
+}
+
+func withKPAFreezePropagated(pa *autoscalingv1alpha1.PodAutoscaler) {
+	pa.Annotations[autoscaling.FreezePropagatedAnnotationKey] = autoscaling.FreezeAnnotationKey
+}
+
+func withScaleTargetInitialized(pa *autoscalingv1alpha1.PodAutoscaler) {
+	pa.Status.MarkScaleTargetInitialized()
+}
+
 // TODO(mattmoor): Come up with a better name for this.
 func AllUnknownConditions(r *v1alpha1.Revision) {
 	WithInitRevConditions(r)
@@ -196,6 +196,9 @@ func (r *reconciler) reconcilePublicEndpoints(ctx context.Context, sks *netv1alp
 		logger.Errorw("Error obtaining activator service endpoints", zap.Error(err))
 		return err
 	}
+	// Only the activators the revision is assigned to are put in its request path.
+	activatorEps = activator.SubsetEndpoints(activator.RevisionID{Namespace: sks.Namespace, Name: sks.Name},
+		activatorEps, int(sks.Spec.NumActivators))
 	logger.Debugf("Activator endpoints: %s", spew.Sprint(activatorEps))
 
 	// The logic below is as follows:
@@ -110,6 +110,29 @@ func TestReconcile(t *testing.T) {
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "Updated", `Successfully updated ServerlessService "steady/to-proxy"`),
 		},
+	}, {
+		Name: "steady switch to proxy mode with a subset of the activators",
+		Key:  "steady/to-proxy",
+		Objects: []runtime.Object{
+			SKS("steady", "to-proxy", markHappy, WithPubService, WithPrivateService("to-proxy-deadbeef"),
+				WithDeployRef("bar"), WithProxyMode, WithNumActivators(2)),
+			deploy("steady", "bar"),
+			svcpub("steady", "to-proxy"),
+			svcpriv("steady", "to-proxy", svcWithName("to-proxy-deadbeef")),
+			endpointspub("steady", "to-proxy", withOtherSubsets),
+			endpointspriv("steady", "to-proxy", epsWithName("to-proxy-deadbeed")),
+			activatorEndpoints(withActivators),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: SKS("steady", "to-proxy", WithDeployRef("bar"), WithNumActivators(2),
+				markNoEndpoints, WithProxyMode, WithPubService, WithPrivateService("to-proxy-deadbeef")),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: endpointspub("steady", "to-proxy", withAssignedActivators("steady", "to-proxy", 2)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", `Successfully updated ServerlessService "steady/to-proxy"`),
+		},
 	}, {
 		Name: "many-private-services",
 		Key:  "many/privates",
@@ -605,6 +628,32 @@ func withOtherSubsets(ep *corev1.Endpoints) {
 	}}
 }
 
+// activatorIPs are the activators behind the endpoints of withActivators.
+var activatorIPs = []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}
+
+func withActivators(ep *corev1.Endpoints) {
+	ep.Subsets = []corev1.EndpointSubset{{}}
+	for _, ip := range activatorIPs {
+		ep.Subsets[0].Addresses = append(ep.Subsets[0].Addresses, corev1.EndpointAddress{IP: ip})
+	}
+}
+
+// withAssignedActivators keeps only the n activators of withActivators
+// the revision is assigned to.
+func withAssignedActivators(namespace, name string, n int) EndpointsOption {
+	return func(ep *corev1.Endpoints) {
+		assigned := activator.AssignedActivators(activator.RevisionID{Namespace: namespace, Name: name}, activatorIPs, n)
+		ep.Subsets = []corev1.EndpointSubset{{}}
+		for _, ip := range activatorIPs {
+			for _, a := range assigned {
+				if ip == a {
+					ep.Subsets[0].Addresses = append(ep.Subsets[0].Addresses, corev1.EndpointAddress{IP: ip})
+				}
+			}
+		}
+	}
+}
+
 func markHappy(sks *nv1a1.ServerlessService) {
 	sks.Status.MarkEndpointsReady()
 }
@@ -130,6 +130,35 @@ func WithTargetAnnotation(target string) PodAutoscalerOption {
 	return withAnnotationValue(autoscaling.TargetAnnotationKey, target)
 }
 
+// WithScaleScheduleAnnotation returns a PodAutoscalerOption which sets
+// the PodAutoscaler autoscaling.knative.dev/scaleSchedule annotation to the
+// provided value.
+func WithScaleScheduleAnnotation(schedule string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.ScaleScheduleAnnotationKey, schedule)
+}
+
+// WithActiveScaleSchedule sets the active scale schedule entries in the
+// PodAutoscaler status.
+func WithActiveScaleSchedule(entries ...string) PodAutoscalerOption {
+	return func(pa *autoscalingv1alpha1.PodAutoscaler) {
+		pa.Status.ActiveScaleSchedule = entries
+	}
+}
+
+// WithTargetRPSAnnotation returns a PodAutoscalerOption which sets
+// the PodAutoscaler autoscaling.knative.dev/targetRPS annotation to the
+// provided value.
+func WithTargetRPSAnnotation(target string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.TargetRPSAnnotationKey, target)
+}
+
+// WithTargetLatencyAnnotation returns a PodAutoscalerOption which sets
+// the PodAutoscaler autoscaling.knative.dev/targetLatency annotation to the
+// provided value.
+func WithTargetLatencyAnnotation(latency string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.TargetLatencyAnnotationKey, latency)
+}
+
 // WithWindowAnnotation returns a PodAutoScalerOption which sets
 // the PodAutoscaler autoscaling.knative.dev/window annotation to the
 // provided value.
@@ -153,6 +182,31 @@ func WithPanicWindowPercentageAnnotation(percentage string) PodAutoscalerOption
 	return withAnnotationValue(autoscaling.PanicWindowPercentageAnnotationKey, percentage)
 }
 
+// WithMaxScaleDownRateAnnotation returns a PodAutoscalerOption which
+// sets the PodAutoscaler autoscaling.knative.dev/maxScaleDownRate
+// annotation to the provided value.
+func WithMaxScaleDownRateAnnotation(rate string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.MaxScaleDownRateAnnotationKey, rate)
+}
+
+// WithAlgorithmAnnotation sets the PodAutoscaler
+// autoscaling.knative.dev/algorithm annotation to the provided value.
+func WithAlgorithmAnnotation(algorithm string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.AlgorithmAnnotationKey, algorithm)
+}
+
+// WithAggregationAnnotation sets the PodAutoscaler
+// autoscaling.knative.dev/aggregation annotation to the provided value.
+func WithAggregationAnnotation(aggregation string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.AggregationAnnotationKey, aggregation)
+}
+
+// WithTargetBurstCapacityAnnotation sets the PodAutoscaler
+// autoscaling.knative.dev/targetBurstCapacity annotation to the provided value.
+func WithTargetBurstCapacityAnnotation(tbc string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.TargetBurstCapacityAnnotationKey, tbc)
+}
+
 // WithMetricAnnotation adds a metric annotation to the PA.
 func WithMetricAnnotation(metric string) PodAutoscalerOption {
 	return withAnnotationValue(autoscaling.MetricAnnotationKey, metric)
@@ -337,6 +391,13 @@ func WithSKSOwnersRemoved(sks *netv1alpha1.ServerlessService) {
 	sks.OwnerReferences = nil
 }
 
+// WithNumActivators assigns the revision of the SKS to n activators.
+func WithNumActivators(n int32) SKSOption {
+	return func(sks *netv1alpha1.ServerlessService) {
+		sks.Spec.NumActivators = n
+	}
+}
+
 // WithProxyMode puts SKS into proxy mode.
 func WithProxyMode(sks *netv1alpha1.ServerlessService) {
 	sks.Spec.Mode = netv1alpha1.SKSOperationModeProxy